@@ -0,0 +1,83 @@
+package command
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/shini4i/argo-compare/internal/app"
+	"github.com/shini4i/argo-compare/internal/helpers"
+)
+
+// Exit statuses reported with --exit-code. They mirror `git diff --exit-code`
+// for the first two and keep gate failures apart from operational errors, so
+// a pipeline can tell "this change is blocked" from "the tool broke".
+const (
+	ExitCodeNoDifferences = 0 // Every comparison came back empty.
+	ExitCodeDifferences   = 1 // At least one Application renders differently.
+	ExitCodeGateFailure   = 2 // Manifest validation, a policy or the risk threshold failed.
+	ExitCodeError         = 3 // The comparison could not be completed.
+)
+
+// ExitError carries the process exit status selected for a run alongside the
+// error that caused it. Execute returns it only when --exit-code is set.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error returns the message of the underlying error.
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap exposes the underlying error to errors.Is and errors.As.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// gateErrors are the sentinel errors that App.Run returns after a complete run
+// that must still fail the pipeline.
+var gateErrors = []error{
+	app.ErrManifestValidationFailed,
+	app.ErrPolicyViolations,
+	app.ErrRiskThresholdExceeded,
+}
+
+// withExitCode maps the outcome of a run onto the --exit-code statuses.
+func withExitCode(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, app.ErrDifferencesFound) {
+		return &ExitError{Code: ExitCodeDifferences, Err: err}
+	}
+	for _, gateErr := range gateErrors {
+		if errors.Is(err, gateErr) {
+			return &ExitError{Code: ExitCodeGateFailure, Err: err}
+		}
+	}
+	return &ExitError{Code: ExitCodeError, Err: err}
+}
+
+// exitCodeRequested reports whether --exit-code is in effect for args: the
+// last --exit-code flag wins, and ARGO_COMPARE_EXIT_CODE applies without one.
+// It reads the raw arguments because it must also answer when cobra could not
+// parse them.
+func exitCodeRequested(args []string) bool {
+	//nolint:errcheck // an invalid value leaves exit-code mode off, as for the flag default
+	requested, _ := strconv.ParseBool(helpers.GetEnv("ARGO_COMPARE_EXIT_CODE", ""))
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--exit-code" {
+			requested = true
+		} else if value, ok := strings.CutPrefix(arg, "--exit-code="); ok {
+			if parsed, err := strconv.ParseBool(value); err == nil {
+				requested = parsed
+			}
+		}
+	}
+	return requested
+}
@@ -3,6 +3,7 @@ package command
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -30,17 +31,28 @@ type Options struct {
 }
 
 // Execute builds and runs the Cobra command tree using the supplied options.
+// With --exit-code, the error of a branch comparison (including
+// app.ErrDifferencesFound) is wrapped in an *ExitError carrying the status
+// the process should exit with; usage errors such as unknown flags or a
+// missing target branch exit with ExitCodeError. Errors are not printed; that
+// is left to the caller.
 func Execute(opts Options, args []string) error {
 	root := newRootCommand(opts)
 
 	if args != nil {
 		root.SetArgs(args)
+	} else {
+		args = os.Args[1:]
 	}
 
 	if err := root.Execute(); err != nil {
 		if errors.Is(err, ErrCachePurged) {
 			return nil
 		}
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) && exitCodeRequested(args) {
+			return &ExitError{Code: ExitCodeError, Err: err}
+		}
 		return err
 	}
 
@@ -55,9 +67,10 @@ func newRootCommand(opts Options) *cobra.Command {
 	)
 
 	root := &cobra.Command{
-		Use:          "argo-compare",
-		Short:        "Compare Argo CD applications between git branches",
-		SilenceUsage: true,
+		Use:           "argo-compare",
+		Short:         "Compare Argo CD applications between git branches",
+		SilenceUsage:  true,
+		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
 		},
@@ -108,25 +121,11 @@ func newBranchCommand(opts Options, dropCache func() bool, debug func() bool) *c
 			params := flags
 			params.applyFullOutput()
 
-			configOptions, err := params.configOptions(opts, debug())
-			if err != nil {
-				return err
-			}
-
-			cfg, err := app.NewConfig(args[0], configOptions...)
-			if err != nil {
-				return err
+			err := runBranch(cmd.Context(), opts, params, args[0], debug())
+			if params.exitCode {
+				return withExitCode(err)
 			}
-
-			if opts.RunApp == nil {
-				return errors.New("no run handler provided")
-			}
-
-			// Create a context that cancels on interrupt/terminate signals.
-			ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
-			defer cancel()
-
-			return opts.RunApp(ctx, cfg)
+			return err
 		},
 	}
 
@@ -136,11 +135,41 @@ func newBranchCommand(opts Options, dropCache func() bool, debug func() bool) *c
 	cmd.Flags().BoolVar(&flags.printAdded, "print-added-manifests", false, "Print added manifests")
 	cmd.Flags().BoolVar(&flags.printRemoved, "print-removed-manifests", false, "Print removed manifests")
 	cmd.Flags().BoolVar(&flags.fullOutput, "full-output", false, "Print all changed, added, and removed manifests")
-	cmd.Flags().StringVar(&flags.commentProvider, "comment-provider", flags.commentProvider, "Post diff comment using provider (gitlab)")
+	cmd.Flags().StringVar(&flags.commentProvider, "comment-provider", flags.commentProvider, "Post diff comment using provider (gitlab, github, gitea, bitbucket, azuredevops)")
+	cmd.Flags().StringVar(&flags.commentMode, "comment-mode", flags.commentMode, "How comments of earlier runs are treated: update edits them in place (gitlab, github, gitea), append posts new ones")
 	cmd.Flags().StringVar(&flags.gitlabURL, "gitlab-url", flags.gitlabURL, "GitLab base URL (e.g., https://gitlab.com)")
 	cmd.Flags().StringVar(&flags.gitlabToken, "gitlab-token", flags.gitlabToken, "GitLab personal access token")
 	cmd.Flags().StringVar(&flags.gitlabProjectID, "gitlab-project-id", flags.gitlabProjectID, "GitLab project ID")
 	cmd.Flags().IntVar(&flags.gitlabMergeIID, "gitlab-merge-request-iid", flags.gitlabMergeIID, "GitLab merge request IID")
+	cmd.Flags().BoolVar(&flags.gitlabStatus, "gitlab-status", flags.gitlabStatus, "Report a GitLab commit status argo-compare/<application> per Application (uses the --gitlab-url, --gitlab-token and --gitlab-project-id settings)")
+	cmd.Flags().StringVar(&flags.gitlabStatusSHA, "gitlab-status-sha", flags.gitlabStatusSHA, "Commit the GitLab statuses are set on (default: the merge request's source commit, or HEAD)")
+	cmd.Flags().StringVar(&flags.gitlabStatusURL, "gitlab-status-url", flags.gitlabStatusURL, "Link shown with the GitLab statuses (default: CI_JOB_URL)")
+	cmd.Flags().BoolVar(&flags.gitlabLabels, "gitlab-labels", flags.gitlabLabels, "Add and remove GitLab merge request labels based on the results (uses the --gitlab-* merge request settings)")
+	cmd.Flags().StringVar(&flags.gitlabLabelsFile, "gitlab-labels-file", flags.gitlabLabelsFile, "YAML file mapping result conditions to GitLab merge request labels; implies --gitlab-labels")
+	cmd.Flags().StringVar(&flags.gitlabDiscussions, "gitlab-discussions", flags.gitlabDiscussions, "Post GitLab results as resolvable discussions: off, general, or diff (attached to the changed file)")
+	cmd.Flags().StringVar(&flags.githubURL, "github-api-url", flags.githubURL, "GitHub REST API URL (e.g., https://github.example.com/api/v3 for GitHub Enterprise; default https://api.github.com)")
+	cmd.Flags().StringVar(&flags.githubToken, "github-token", flags.githubToken, "GitHub token allowed to comment on pull requests")
+	cmd.Flags().StringVar(&flags.githubRepository, "github-repository", flags.githubRepository, "GitHub repository in owner/name form")
+	cmd.Flags().IntVar(&flags.githubPullRequest, "github-pull-request", flags.githubPullRequest, "GitHub pull request number")
+	cmd.Flags().StringVar(&flags.giteaURL, "gitea-url", flags.giteaURL, "Gitea or Forgejo base URL (e.g., https://codeberg.org)")
+	cmd.Flags().StringVar(&flags.giteaToken, "gitea-token", flags.giteaToken, "Gitea or Forgejo access token allowed to comment on pull requests")
+	cmd.Flags().StringVar(&flags.giteaRepository, "gitea-repository", flags.giteaRepository, "Gitea or Forgejo repository in owner/name form")
+	cmd.Flags().IntVar(&flags.giteaPullRequest, "gitea-pull-request", flags.giteaPullRequest, "Gitea or Forgejo pull request number")
+	cmd.Flags().StringVar(&flags.giteaCABundle, "gitea-ca-bundle", flags.giteaCABundle, "PEM file with additional CA certificates trusted for the Gitea or Forgejo server")
+	cmd.Flags().StringVar(&flags.bitbucketURL, "bitbucket-url", flags.bitbucketURL, "Bitbucket Data Center server URL, or Bitbucket Cloud API URL (default https://api.bitbucket.org/2.0)")
+	cmd.Flags().BoolVar(&flags.bitbucketDataCenter, "bitbucket-data-center", flags.bitbucketDataCenter, "Use the Bitbucket Data Center API instead of Bitbucket Cloud")
+	cmd.Flags().StringVar(&flags.bitbucketWorkspace, "bitbucket-workspace", flags.bitbucketWorkspace, "Bitbucket Cloud workspace, or Bitbucket Data Center project key")
+	cmd.Flags().StringVar(&flags.bitbucketRepository, "bitbucket-repository", flags.bitbucketRepository, "Bitbucket repository slug")
+	cmd.Flags().IntVar(&flags.bitbucketPullRequest, "bitbucket-pull-request", flags.bitbucketPullRequest, "Bitbucket pull request ID")
+	cmd.Flags().StringVar(&flags.bitbucketUsername, "bitbucket-username", flags.bitbucketUsername, "Bitbucket username for app password (Cloud) or password (Data Center) authentication; leave empty to send the token as a bearer token")
+	cmd.Flags().StringVar(&flags.bitbucketToken, "bitbucket-token", flags.bitbucketToken, "Bitbucket access token, app password, or HTTP access token")
+	cmd.Flags().StringVar(&flags.azureURL, "azure-devops-url", flags.azureURL, "Azure DevOps organization (collection) URL (e.g., https://dev.azure.com/acme)")
+	cmd.Flags().StringVar(&flags.azureProject, "azure-devops-project", flags.azureProject, "Azure DevOps project")
+	cmd.Flags().StringVar(&flags.azureRepository, "azure-devops-repository", flags.azureRepository, "Azure DevOps repository name or ID")
+	cmd.Flags().IntVar(&flags.azurePullRequest, "azure-devops-pull-request", flags.azurePullRequest, "Azure DevOps pull request ID")
+	cmd.Flags().StringVar(&flags.azureToken, "azure-devops-token", flags.azureToken, "Azure DevOps token (the pipeline's System.AccessToken or a personal access token)")
+	cmd.Flags().BoolVar(&flags.azurePAT, "azure-devops-pat", flags.azurePAT, "Treat --azure-devops-token as a personal access token (Basic auth) rather than an OAuth token")
+	cmd.Flags().BoolVar(&flags.githubActions, "github-actions", flags.githubActions, "Group output per Application, annotate findings and write the job summary for GitHub Actions (default true when GITHUB_ACTIONS=true)")
 	cmd.Flags().BoolVar(&flags.validateManifests, "validate-manifests", flags.validateManifests, "Validate rendered manifests against Kubernetes schemas")
 	cmd.Flags().StringVar(&flags.kubeconformPath, "kubeconform-path", flags.kubeconformPath, "Path to kubeconform binary")
 	cmd.Flags().StringSliceVar(&flags.validateSkipKinds, "skip-validation-kinds", flags.validateSkipKinds, "Resource kinds to skip during validation (comma-separated)")
@@ -148,10 +177,53 @@ func newBranchCommand(opts Options, dropCache func() bool, debug func() bool) *c
 	cmd.Flags().StringVar(&flags.anchorFileName, "anchor-file", flags.anchorFileName, "Name of the file that marks an anchor directory (default .argo-compare.yml; empty disables discovery)")
 	cmd.Flags().StringVar(&flags.gitUsername, "git-username", flags.gitUsername, "Username for HTTP Basic auth when cloning cross-repo anchored Applications (defaults to x-access-token; set to gitlab-ci-token for GitLab CI_JOB_TOKEN or your account name for Bitbucket)")
 	cmd.Flags().StringVar(&flags.gitToken, "git-token", flags.gitToken, "Token (typically a PAT) for HTTP Basic auth when cloning cross-repo anchored Applications")
+	cmd.Flags().StringVar(&flags.maskKeyFile, "mask-key-file", flags.maskKeyFile, "File containing the HMAC key used to mask Secret values (defaults to ARGO_COMPARE_MASK_KEY, or a random per-run key)")
+	cmd.Flags().IntVar(&flags.maskDigestLength, "mask-digest-length", flags.maskDigestLength, "Number of hex digest characters shown in masked Secret placeholders (8-64, default 32)")
+	cmd.Flags().StringVar(&flags.maskingRules, "masking-rules", flags.maskingRules, "YAML file with rules for masking sensitive values outside Secrets (extends the built-in rules)")
+	cmd.Flags().StringVar(&flags.failOnRisk, "fail-on-risk", flags.failOnRisk, "Exit non-zero when a risk finding reaches this severity (low, medium, high, critical)")
+	cmd.Flags().BoolVar(&flags.exitCode, "exit-code", flags.exitCode, "Exit with 1 when differences are found, 2 on validation, policy or risk failures and 3 on errors")
+	cmd.Flags().StringVar(&flags.policyFile, "policy-file", flags.policyFile, "YAML file with CEL policies evaluated against every rendered resource; violations fail the run")
+	cmd.Flags().StringVar(&flags.kubeVersion, "kube-version", flags.kubeVersion, "Kubernetes version (e.g. 1.29) to check rendered API versions against for deprecations and removals; spec.source.helm.kubeVersion takes precedence")
+	cmd.Flags().StringVar(&flags.reportJSON, "report-json", flags.reportJSON, "Write a machine-readable JSON report of every compared Application to this file")
+	cmd.Flags().StringVar(&flags.reportSARIF, "report-sarif", flags.reportSARIF, "Write manifest validation findings as a SARIF 2.1.0 log to this file")
+	cmd.Flags().StringVar(&flags.reportJUnit, "report-junit", flags.reportJUnit, "Write manifest validation results as JUnit XML (one test case per resource) to this file")
+	cmd.Flags().StringVar(&flags.reportCodeQuality, "report-codequality", flags.reportCodeQuality, "Write validation errors, policy violations and risk findings as a GitLab Code Quality report to this file")
+	cmd.Flags().StringVar(&flags.reportHTML, "report-html", flags.reportHTML, "Write a self-contained HTML diff report of every compared Application to this file")
+	cmd.Flags().StringVar(&flags.outputDir, "output-dir", flags.outputDir, "Persist the normalised, masked manifests of both branches per Application and resource to this directory")
+	cmd.Flags().StringVar(&flags.commentTemplate, "comment-template", flags.commentTemplate, "Render merge request comments and the GitHub job summary with this Go text/template file")
+	cmd.Flags().StringSliceVar(&flags.reportTemplates, "report-template", flags.reportTemplates, "Render the run report with a Go text/template file, as TEMPLATE=OUTPUT (can be repeated or comma-separated)")
+	cmd.Flags().StringVar(&flags.patchFile, "patch-file", flags.patchFile, "Write the changed resources of every compared Application as a single git-apply-compatible patch to this file")
+	cmd.Flags().StringVar(&flags.webhookURL, "webhook-url", flags.webhookURL, "POST the JSON report of the run to this URL once every Application has been compared")
+	cmd.Flags().StringArrayVar(&flags.webhookHeaders, "webhook-header", flags.webhookHeaders, "Extra webhook request header, as 'Name: value' (can be repeated)")
+	cmd.Flags().StringVar(&flags.webhookSecretFile, "webhook-secret-file", flags.webhookSecretFile, "File containing the key used to sign webhook requests with HMAC-SHA256 (defaults to ARGO_COMPARE_WEBHOOK_SECRET)")
 
 	return cmd
 }
 
+// runBranch builds the run configuration from the parsed flags and invokes the
+// RunApp handler with a context that cancels on SIGINT/SIGTERM.
+func runBranch(ctx context.Context, opts Options, params branchFlags, targetBranch string, debug bool) error {
+	configOptions, err := params.configOptions(opts, debug)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := app.NewConfig(targetBranch, configOptions...)
+	if err != nil {
+		return err
+	}
+
+	if opts.RunApp == nil {
+		return errors.New("no run handler provided")
+	}
+
+	// Create a context that cancels on interrupt/terminate signals.
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	return opts.RunApp(ctx, cfg)
+}
+
 type branchFlags struct {
 	file                    string
 	ignore                  []string
@@ -160,10 +232,39 @@ type branchFlags struct {
 	printRemoved            bool
 	fullOutput              bool
 	commentProvider         string
+	commentMode             string
 	gitlabURL               string
 	gitlabToken             string
 	gitlabProjectID         string
 	gitlabMergeIID          int
+	gitlabDiscussions       string
+	gitlabStatus            bool
+	gitlabLabels            bool
+	gitlabLabelsFile        string
+	gitlabStatusSHA         string
+	gitlabStatusURL         string
+	githubURL               string
+	githubToken             string
+	githubRepository        string
+	githubPullRequest       int
+	giteaURL                string
+	giteaToken              string
+	giteaRepository         string
+	giteaPullRequest        int
+	giteaCABundle           string
+	bitbucketURL            string
+	bitbucketDataCenter     bool
+	bitbucketWorkspace      string
+	bitbucketRepository     string
+	bitbucketPullRequest    int
+	bitbucketUsername       string
+	bitbucketToken          string
+	azureURL                string
+	azureProject            string
+	azureRepository         string
+	azurePullRequest        int
+	azureToken              string
+	azurePAT                bool
 	validateManifests       bool
 	kubeconformPath         string
 	validateSkipKinds       []string
@@ -171,6 +272,29 @@ type branchFlags struct {
 	anchorFileName          string
 	gitUsername             string
 	gitToken                string
+	maskKey                 string
+	maskKeyFile             string
+	maskDigestLength        int
+	maskingRules            string
+	failOnRisk              string
+	policyFile              string
+	exitCode                bool
+	kubeVersion             string
+	reportJSON              string
+	reportSARIF             string
+	reportJUnit             string
+	reportCodeQuality       string
+	reportHTML              string
+	outputDir               string
+	patchFile               string
+	commentTemplate         string
+	reportTemplates         []string
+	webhookURL              string
+	webhookHeaders          []string
+	webhookSecret           string
+	webhookSecretFile       string
+	githubActions           bool
+	githubStepSummary       string
 }
 
 // loadBranchDefaults gathers branch flag defaults from the environment.
@@ -178,10 +302,26 @@ func loadBranchDefaults() branchFlags {
 	defaults := branchFlags{}
 	loadCommentDefaults(&defaults)
 	loadValidationDefaults(&defaults)
+	loadMaskingDefaults(&defaults)
 
 	defaults.anchorFileName = helpers.GetEnv("ARGO_COMPARE_ANCHOR_FILE", app.DefaultAnchorFileName)
 	defaults.gitUsername = helpers.GetEnv("ARGO_COMPARE_GIT_USERNAME", "")
 	defaults.gitToken = helpers.GetEnv("ARGO_COMPARE_GIT_TOKEN", "")
+	defaults.failOnRisk = helpers.GetEnv("ARGO_COMPARE_FAIL_ON_RISK", "")
+	defaults.policyFile = helpers.GetEnv("ARGO_COMPARE_POLICY_FILE", "")
+	defaults.exitCode = envBool("ARGO_COMPARE_EXIT_CODE")
+	defaults.kubeVersion = helpers.GetEnv("ARGO_COMPARE_KUBE_VERSION", "")
+	defaults.reportJSON = helpers.GetEnv("ARGO_COMPARE_REPORT_JSON", "")
+	defaults.reportSARIF = helpers.GetEnv("ARGO_COMPARE_REPORT_SARIF", "")
+	defaults.reportJUnit = helpers.GetEnv("ARGO_COMPARE_REPORT_JUNIT", "")
+	defaults.reportCodeQuality = helpers.GetEnv("ARGO_COMPARE_REPORT_CODEQUALITY", "")
+	defaults.reportHTML = helpers.GetEnv("ARGO_COMPARE_REPORT_HTML", "")
+	defaults.outputDir = helpers.GetEnv("ARGO_COMPARE_OUTPUT_DIR", "")
+	defaults.patchFile = helpers.GetEnv("ARGO_COMPARE_PATCH_FILE", "")
+	defaults.commentTemplate = helpers.GetEnv("ARGO_COMPARE_COMMENT_TEMPLATE", "")
+	defaults.reportTemplates = splitCSV(helpers.GetEnv("ARGO_COMPARE_REPORT_TEMPLATES", ""))
+	loadGitHubActionsDefaults(&defaults)
+	loadWebhookDefaults(&defaults)
 
 	return defaults
 }
@@ -196,6 +336,21 @@ func envWithFallback(primary, secondary string) string {
 	return helpers.GetEnv(secondary, "")
 }
 
+// envBool parses a boolean environment variable, warning on stderr and
+// returning false when the value is not a valid boolean.
+func envBool(name string) bool {
+	value := helpers.GetEnv(name, "")
+	if value == "" {
+		return false
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s=%q is not a valid boolean; ignoring it (use 1/true/0/false)\n", name, value)
+		return false
+	}
+	return parsed
+}
+
 // splitCSV splits a comma-separated value into trimmed, non-empty items.
 func splitCSV(s string) []string {
 	var out []string
@@ -207,14 +362,15 @@ func splitCSV(s string) []string {
 	return out
 }
 
-// loadCommentDefaults populates the GitLab comment-related defaults from the
-// environment, preferring explicit ARGO_COMPARE_* vars over GitLab CI's.
+// loadCommentDefaults populates the comment-related defaults from the
+// environment, preferring explicit ARGO_COMPARE_* vars over the CI system's.
 func loadCommentDefaults(d *branchFlags) {
 	provider := helpers.GetEnv("ARGO_COMPARE_COMMENT_PROVIDER", "")
 	if provider == "" && helpers.GetEnv("GITLAB_CI", "") != "" && helpers.GetEnv("CI_MERGE_REQUEST_IID", "") != "" {
 		provider = string(app.CommentProviderGitLab)
 	}
 	d.commentProvider = provider
+	d.commentMode = helpers.GetEnv("ARGO_COMPARE_COMMENT_MODE", string(app.CommentModeUpdate))
 
 	d.gitlabURL = envWithFallback("ARGO_COMPARE_GITLAB_URL", "CI_SERVER_URL")
 	d.gitlabToken = envWithFallback("ARGO_COMPARE_GITLAB_TOKEN", "CI_JOB_TOKEN")
@@ -225,6 +381,102 @@ func loadCommentDefaults(d *branchFlags) {
 			d.gitlabMergeIID = parsed
 		}
 	}
+	d.gitlabDiscussions = helpers.GetEnv("ARGO_COMPARE_GITLAB_DISCUSSIONS", string(app.GitLabDiscussionsOff))
+	d.gitlabStatus = envBool("ARGO_COMPARE_GITLAB_STATUS")
+	d.gitlabLabels = envBool("ARGO_COMPARE_GITLAB_LABELS")
+	d.gitlabLabelsFile = helpers.GetEnv("ARGO_COMPARE_GITLAB_LABELS_FILE", "")
+	// Merged results pipelines check out a merge commit; the MR widget shows
+	// the statuses of the source branch's head instead.
+	d.gitlabStatusSHA = envWithFallback("ARGO_COMPARE_GITLAB_STATUS_SHA", "CI_MERGE_REQUEST_SOURCE_BRANCH_SHA")
+	if d.gitlabStatusSHA == "" {
+		d.gitlabStatusSHA = helpers.GetEnv("CI_COMMIT_SHA", "")
+	}
+	d.gitlabStatusURL = envWithFallback("ARGO_COMPARE_GITLAB_STATUS_URL", "CI_JOB_URL")
+
+	d.githubURL = envWithFallback("ARGO_COMPARE_GITHUB_API_URL", "GITHUB_API_URL")
+	d.githubToken = envWithFallback("ARGO_COMPARE_GITHUB_TOKEN", "GITHUB_TOKEN")
+	d.githubRepository = envWithFallback("ARGO_COMPARE_GITHUB_REPOSITORY", "GITHUB_REPOSITORY")
+	d.githubPullRequest = workflowPullRequest("ARGO_COMPARE_GITHUB_PR_NUMBER", "GITHUB")
+
+	// Gitea and Forgejo Actions export the GITHUB_* variables as well, and
+	// GITEA_* ones on newer runners.
+	d.giteaURL = envWithFallback("ARGO_COMPARE_GITEA_URL", "GITEA_SERVER_URL")
+	if d.giteaURL == "" && helpers.GetEnv("GITEA_ACTIONS", "") == "true" {
+		d.giteaURL = helpers.GetEnv("GITHUB_SERVER_URL", "")
+	}
+	d.giteaToken = envWithFallback("ARGO_COMPARE_GITEA_TOKEN", "GITEA_TOKEN")
+	d.giteaRepository = envWithFallback("ARGO_COMPARE_GITEA_REPOSITORY", "GITEA_REPOSITORY")
+	if d.giteaRepository == "" {
+		d.giteaRepository = helpers.GetEnv("GITHUB_REPOSITORY", "")
+	}
+	d.giteaPullRequest = workflowPullRequest("ARGO_COMPARE_GITEA_PR_NUMBER", "GITEA", "GITHUB")
+	d.giteaCABundle = helpers.GetEnv("ARGO_COMPARE_GITEA_CA_BUNDLE", "")
+
+	d.bitbucketURL = helpers.GetEnv("ARGO_COMPARE_BITBUCKET_URL", "")
+	d.bitbucketDataCenter = envBool("ARGO_COMPARE_BITBUCKET_DATA_CENTER")
+	d.bitbucketWorkspace = envWithFallback("ARGO_COMPARE_BITBUCKET_WORKSPACE", "BITBUCKET_WORKSPACE")
+	d.bitbucketRepository = envWithFallback("ARGO_COMPARE_BITBUCKET_REPOSITORY", "BITBUCKET_REPO_SLUG")
+	if pullRequest := envWithFallback("ARGO_COMPARE_BITBUCKET_PR_ID", "BITBUCKET_PR_ID"); pullRequest != "" {
+		if parsed, err := strconv.Atoi(pullRequest); err == nil {
+			d.bitbucketPullRequest = parsed
+		}
+	}
+	d.bitbucketUsername = helpers.GetEnv("ARGO_COMPARE_BITBUCKET_USERNAME", "")
+	d.bitbucketToken = helpers.GetEnv("ARGO_COMPARE_BITBUCKET_TOKEN", "")
+
+	d.azureURL = envWithFallback("ARGO_COMPARE_AZURE_DEVOPS_URL", "SYSTEM_COLLECTIONURI")
+	d.azureProject = envWithFallback("ARGO_COMPARE_AZURE_DEVOPS_PROJECT", "SYSTEM_TEAMPROJECT")
+	d.azureRepository = envWithFallback("ARGO_COMPARE_AZURE_DEVOPS_REPOSITORY", "BUILD_REPOSITORY_ID")
+	if pullRequest := envWithFallback("ARGO_COMPARE_AZURE_DEVOPS_PR_ID", "SYSTEM_PULLREQUEST_PULLREQUESTID"); pullRequest != "" {
+		if parsed, err := strconv.Atoi(pullRequest); err == nil {
+			d.azurePullRequest = parsed
+		}
+	}
+	d.azureToken = envWithFallback("ARGO_COMPARE_AZURE_DEVOPS_TOKEN", "SYSTEM_ACCESSTOKEN")
+	d.azurePAT = envBool("ARGO_COMPARE_AZURE_DEVOPS_PAT")
+}
+
+// workflowPullRequest resolves the pull request number of a GitHub-style
+// workflow run from the override variable, or from the <prefix>_EVENT_PATH
+// payload and the refs/pull/<number>/... <prefix>_REF of each prefix in turn.
+func workflowPullRequest(override string, prefixes ...string) int {
+	if number, err := strconv.Atoi(helpers.GetEnv(override, "")); err == nil {
+		return number
+	}
+
+	for _, prefix := range prefixes {
+		if eventPath := helpers.GetEnv(prefix+"_EVENT_PATH", ""); eventPath != "" {
+			if data, err := os.ReadFile(eventPath); err == nil { // #nosec G304 -- path is provided by the workflow runner
+				var event struct {
+					PullRequest struct {
+						Number int `json:"number"`
+					} `json:"pull_request"`
+				}
+				if json.Unmarshal(data, &event) == nil && event.PullRequest.Number > 0 {
+					return event.PullRequest.Number
+				}
+			}
+		}
+
+		if rest, ok := strings.CutPrefix(helpers.GetEnv(prefix+"_REF", ""), "refs/pull/"); ok {
+			number, _, _ := strings.Cut(rest, "/")
+			if number, err := strconv.Atoi(number); err == nil {
+				return number
+			}
+		}
+	}
+	return 0
+}
+
+// loadGitHubActionsDefaults enables the GitHub Actions output on GitHub's
+// runners unless ARGO_COMPARE_GITHUB_ACTIONS says otherwise.
+func loadGitHubActionsDefaults(d *branchFlags) {
+	if helpers.GetEnv("ARGO_COMPARE_GITHUB_ACTIONS", "") != "" {
+		d.githubActions = envBool("ARGO_COMPARE_GITHUB_ACTIONS")
+	} else {
+		d.githubActions = helpers.GetEnv("GITHUB_ACTIONS", "") == "true"
+	}
+	d.githubStepSummary = helpers.GetEnv("GITHUB_STEP_SUMMARY", "")
 }
 
 // loadValidationDefaults populates the manifest-validation defaults from the
@@ -245,6 +497,94 @@ func loadValidationDefaults(d *branchFlags) {
 	d.validateSchemaLocations = splitCSV(helpers.GetEnv("ARGO_COMPARE_KUBECONFORM_SCHEMA_LOCATIONS", ""))
 }
 
+// loadMaskingDefaults populates the secret-masking defaults from the
+// environment. The key itself is only accepted via environment or file — never
+// as a flag value — so it does not show up in the process argument list.
+func loadMaskingDefaults(d *branchFlags) {
+	d.maskKey = helpers.GetEnv("ARGO_COMPARE_MASK_KEY", "")
+	d.maskKeyFile = helpers.GetEnv("ARGO_COMPARE_MASK_KEY_FILE", "")
+	d.maskingRules = helpers.GetEnv("ARGO_COMPARE_MASKING_RULES", "")
+
+	if lengthStr := helpers.GetEnv("ARGO_COMPARE_MASK_DIGEST_LENGTH", ""); lengthStr != "" {
+		if parsed, err := strconv.Atoi(lengthStr); err == nil {
+			d.maskDigestLength = parsed
+		} else {
+			fmt.Fprintf(os.Stderr,
+				"warning: ARGO_COMPARE_MASK_DIGEST_LENGTH=%q is not a valid integer; using the default digest length\n",
+				lengthStr)
+		}
+	}
+}
+
+// resolveMaskingKey returns the masking key, preferring the key file over the
+// inline environment value. Surrounding whitespace (typically a trailing
+// newline) is trimmed from file contents; an empty key file is an error rather
+// than a silent fallback to a random key.
+func (b branchFlags) resolveMaskingKey() (string, error) {
+	if b.maskKeyFile == "" {
+		return b.maskKey, nil
+	}
+	return readSecretFile(b.maskKeyFile, "mask key file")
+}
+
+// readSecretFile returns the trimmed contents of the file at path, described
+// as what in errors. An empty file is an error.
+func readSecretFile(path, what string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", what, err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("%s %q is empty", what, path)
+	}
+	return secret, nil
+}
+
+// loadWebhookDefaults populates the webhook defaults from the environment.
+// Headers are newline-separated, since values may contain commas. Like the
+// masking key, the signing secret is only accepted via environment or file.
+func loadWebhookDefaults(d *branchFlags) {
+	d.webhookURL = helpers.GetEnv("ARGO_COMPARE_WEBHOOK_URL", "")
+	for _, header := range strings.Split(helpers.GetEnv("ARGO_COMPARE_WEBHOOK_HEADERS", ""), "\n") {
+		if header = strings.TrimSpace(header); header != "" {
+			d.webhookHeaders = append(d.webhookHeaders, header)
+		}
+	}
+	d.webhookSecret = helpers.GetEnv("ARGO_COMPARE_WEBHOOK_SECRET", "")
+	d.webhookSecretFile = helpers.GetEnv("ARGO_COMPARE_WEBHOOK_SECRET_FILE", "")
+}
+
+// webhookOption resolves the webhook configuration, if any.
+func (b branchFlags) webhookOption() (app.ConfigOption, error) {
+	if b.webhookURL == "" {
+		if len(b.webhookHeaders) > 0 || b.webhookSecretFile != "" {
+			return nil, fmt.Errorf("--webhook-header and --webhook-secret-file require --webhook-url")
+		}
+		return nil, nil
+	}
+
+	headers := make(map[string]string, len(b.webhookHeaders))
+	for _, header := range b.webhookHeaders {
+		name, value, ok := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("--webhook-header: expected 'Name: value', got %q", header)
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+
+	secret := b.webhookSecret
+	if b.webhookSecretFile != "" {
+		var err error
+		if secret, err = readSecretFile(b.webhookSecretFile, "webhook secret file"); err != nil {
+			return nil, err
+		}
+	}
+
+	return app.WithWebhook(app.WebhookConfig{URL: b.webhookURL, Headers: headers, Secret: secret}), nil
+}
+
 // applyFullOutput toggles added/removed flags when full output is requested.
 func (b *branchFlags) applyFullOutput() {
 	if b.fullOutput {
@@ -272,6 +612,43 @@ func (b branchFlags) configOptions(opts Options, debugEnabled bool) ([]app.Confi
 		app.WithValidateSchemaLocations(b.validateSchemaLocations),
 		app.WithAnchorFileName(b.anchorFileName),
 		app.WithGitAuth(b.gitUsername, b.gitToken),
+		app.WithMaskDigestLength(b.maskDigestLength),
+		app.WithMaskingRulesFile(b.maskingRules),
+		app.WithPolicyFile(b.policyFile),
+		app.WithDiffExitCode(b.exitCode),
+		app.WithKubeVersion(b.kubeVersion),
+		app.WithReportJSON(b.reportJSON),
+		app.WithReportSARIF(b.reportSARIF),
+		app.WithReportJUnit(b.reportJUnit),
+		app.WithReportCodeQuality(b.reportCodeQuality),
+		app.WithReportHTML(b.reportHTML),
+		app.WithOutputDir(b.outputDir),
+		app.WithPatchFile(b.patchFile),
+		app.WithCommentTemplate(b.commentTemplate),
+	}
+
+	for _, report := range b.reportTemplates {
+		template, output, ok := strings.Cut(report, "=")
+		if !ok || template == "" || output == "" {
+			return nil, fmt.Errorf("--report-template: expected TEMPLATE=OUTPUT, got %q", report)
+		}
+		options = append(options, app.WithReportTemplate(template, output))
+	}
+
+	maskingKey, err := b.resolveMaskingKey()
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, app.WithMaskingKey(maskingKey))
+
+	failOnRisk, err := app.ParseRiskSeverity(b.failOnRisk)
+	if err != nil {
+		return nil, fmt.Errorf("--fail-on-risk: %w", err)
+	}
+	options = append(options, app.WithFailOnRisk(failOnRisk))
+
+	if b.githubActions {
+		options = append(options, app.WithGitHubActions(app.GitHubActionsConfig{StepSummary: b.githubStepSummary}))
 	}
 
 	commentOption, err := b.commentOption()
@@ -282,26 +659,92 @@ func (b branchFlags) configOptions(opts Options, debugEnabled bool) ([]app.Confi
 		options = append(options, commentOption)
 	}
 
+	if b.gitlabStatus {
+		options = append(options, app.WithCommitStatus(app.CommitStatusConfig{
+			BaseURL:   b.gitlabURL,
+			Token:     b.gitlabToken,
+			ProjectID: b.gitlabProjectID,
+			SHA:       strings.TrimSpace(b.gitlabStatusSHA),
+			TargetURL: b.gitlabStatusURL,
+		}))
+	}
+
+	if b.gitlabLabels || b.gitlabLabelsFile != "" {
+		options = append(options, app.WithMergeRequestLabels(app.LabelsConfig{
+			BaseURL:         b.gitlabURL,
+			Token:           b.gitlabToken,
+			ProjectID:       b.gitlabProjectID,
+			MergeRequestIID: b.gitlabMergeIID,
+			RulesFile:       b.gitlabLabelsFile,
+		}))
+	}
+
+	webhookOption, err := b.webhookOption()
+	if err != nil {
+		return nil, err
+	}
+	if webhookOption != nil {
+		options = append(options, webhookOption)
+	}
+
 	return options, nil
 }
 
 // commentOption resolves the comment configuration, if any.
 func (b branchFlags) commentOption() (app.ConfigOption, error) {
 	provider := strings.ToLower(strings.TrimSpace(b.commentProvider))
-	switch provider {
-	case "":
+	commentCfg := app.CommentConfig{
+		Provider: app.CommentProvider(provider),
+		Mode:     app.CommentMode(strings.ToLower(strings.TrimSpace(b.commentMode))),
+	}
+
+	switch commentCfg.Provider {
+	case app.CommentProviderNone:
 		return nil, nil
-	case string(app.CommentProviderGitLab):
-		return app.WithCommentConfig(app.CommentConfig{
-			Provider: app.CommentProviderGitLab,
-			GitLab: app.GitLabCommentConfig{
-				BaseURL:         b.gitlabURL,
-				Token:           b.gitlabToken,
-				ProjectID:       b.gitlabProjectID,
-				MergeRequestIID: b.gitlabMergeIID,
-			},
-		}), nil
+	case app.CommentProviderGitLab:
+		commentCfg.GitLab = app.GitLabCommentConfig{
+			BaseURL:         b.gitlabURL,
+			Token:           b.gitlabToken,
+			ProjectID:       b.gitlabProjectID,
+			MergeRequestIID: b.gitlabMergeIID,
+			Discussions:     app.GitLabDiscussions(strings.ToLower(strings.TrimSpace(b.gitlabDiscussions))),
+		}
+	case app.CommentProviderGitHub:
+		commentCfg.GitHub = app.GitHubCommentConfig{
+			BaseURL:     b.githubURL,
+			Token:       b.githubToken,
+			Repository:  b.githubRepository,
+			PullRequest: b.githubPullRequest,
+		}
+	case app.CommentProviderGitea:
+		commentCfg.Gitea = app.GiteaCommentConfig{
+			BaseURL:     b.giteaURL,
+			Token:       b.giteaToken,
+			Repository:  b.giteaRepository,
+			PullRequest: b.giteaPullRequest,
+			CABundle:    b.giteaCABundle,
+		}
+	case app.CommentProviderBitbucket:
+		commentCfg.Bitbucket = app.BitbucketCommentConfig{
+			BaseURL:     b.bitbucketURL,
+			DataCenter:  b.bitbucketDataCenter,
+			Workspace:   b.bitbucketWorkspace,
+			Repository:  b.bitbucketRepository,
+			PullRequest: b.bitbucketPullRequest,
+			Username:    b.bitbucketUsername,
+			Token:       b.bitbucketToken,
+		}
+	case app.CommentProviderAzureDevOps:
+		commentCfg.AzureDevOps = app.AzureDevOpsCommentConfig{
+			OrganizationURL:     b.azureURL,
+			Project:             b.azureProject,
+			Repository:          b.azureRepository,
+			PullRequest:         b.azurePullRequest,
+			Token:               b.azureToken,
+			PersonalAccessToken: b.azurePAT,
+		}
 	default:
 		return nil, fmt.Errorf("unsupported comment provider %q", b.commentProvider)
 	}
+	return app.WithCommentConfig(commentCfg), nil
 }
@@ -202,6 +202,39 @@ func TestExecuteUsesGitLabCIEnvDefaults(t *testing.T) {
 	assert.Equal(t, "job-token", receivedConfig.Comment.GitLab.Token)
 }
 
+func TestExecuteUsesGitHubActionsEnvDefaults(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("ARGO_COMPARE_GITHUB_ACTIONS", "")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Nil(t, receivedConfig.GitHubActions)
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", "/runner/summary.md")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	require.NotNil(t, receivedConfig.GitHubActions)
+	assert.Equal(t, "/runner/summary.md", receivedConfig.GitHubActions.StepSummary)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--github-actions=false"}))
+	assert.Nil(t, receivedConfig.GitHubActions, "CLI flag should win")
+
+	t.Setenv("ARGO_COMPARE_GITHUB_ACTIONS", "false")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Nil(t, receivedConfig.GitHubActions, "explicit env var should win over detection")
+}
+
 func TestExecuteValidationFlags(t *testing.T) {
 	var receivedConfig app.Config
 
@@ -377,3 +410,802 @@ func TestExecuteValidationCLIFlagsBeatEnvVars(t *testing.T) {
 	assert.Equal(t, []string{"schemas/from-cli/{{.ResourceKind}}.json"}, receivedConfig.ValidateSchemaLocations,
 		"CLI schema locations should win")
 }
+
+func TestExecuteMaskingKeyFromEnv(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("ARGO_COMPARE_MASK_KEY", "env-key")
+	t.Setenv("ARGO_COMPARE_MASK_DIGEST_LENGTH", "12")
+	t.Setenv("ARGO_COMPARE_MASKING_RULES", "masking-rules.yaml")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+
+	assert.Equal(t, "env-key", receivedConfig.MaskingKey)
+	assert.Equal(t, 12, receivedConfig.MaskDigestLength)
+	assert.Equal(t, "masking-rules.yaml", receivedConfig.MaskingRulesFile)
+}
+
+func TestExecuteMaskingKeyFileBeatsEnv(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "mask.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("file-key\n"), 0o600))
+	t.Setenv("ARGO_COMPARE_MASK_KEY", "env-key")
+
+	t.Setenv("ARGO_COMPARE_MASKING_RULES", "env-rules.yaml")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--mask-key-file", keyFile, "--mask-digest-length", "20",
+		"--masking-rules", "cli-rules.yaml"}))
+
+	assert.Equal(t, "file-key", receivedConfig.MaskingKey)
+	assert.Equal(t, 20, receivedConfig.MaskDigestLength)
+	assert.Equal(t, "cli-rules.yaml", receivedConfig.MaskingRulesFile, "CLI rules file should win")
+}
+
+func TestExecuteMaskingKeyFileErrors(t *testing.T) {
+	emptyFile := filepath.Join(t.TempDir(), "empty.key")
+	require.NoError(t, os.WriteFile(emptyFile, []byte("  \n"), 0o600))
+
+	cases := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "missing file", path: filepath.Join(t.TempDir(), "missing.key"), wantErr: "read mask key file"},
+		{name: "empty file", path: emptyFile, wantErr: "is empty"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := Options{
+				Version:     "test-version",
+				CacheDir:    t.TempDir(),
+				TempDirBase: os.TempDir(),
+				InitLogging: func(bool) {},
+				RunApp: func(_ context.Context, _ app.Config) error {
+					t.Fatalf("RunApp should not be called")
+					return nil
+				},
+			}
+
+			err := Execute(opts, []string{"branch", "main", "--mask-key-file", tc.path})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestExecuteFailOnRisk(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("ARGO_COMPARE_FAIL_ON_RISK", "critical")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, app.RiskCritical, receivedConfig.FailOnRisk)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--fail-on-risk", "medium"}))
+	assert.Equal(t, app.RiskMedium, receivedConfig.FailOnRisk, "CLI flag should win")
+
+	err := Execute(opts, []string{"branch", "main", "--fail-on-risk", "severe"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--fail-on-risk")
+}
+
+func TestExecutePolicyFile(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("ARGO_COMPARE_POLICY_FILE", "env-policies.yaml")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, "env-policies.yaml", receivedConfig.PolicyFile)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--policy-file", "cli-policies.yaml"}))
+	assert.Equal(t, "cli-policies.yaml", receivedConfig.PolicyFile, "CLI flag should win")
+}
+
+func TestExecuteKubeVersion(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("ARGO_COMPARE_KUBE_VERSION", "1.28")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, "1.28", receivedConfig.KubeVersion)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--kube-version", "v1.30.2"}))
+	assert.Equal(t, "v1.30.2", receivedConfig.KubeVersion, "CLI flag should win")
+
+	err := Execute(opts, []string{"branch", "main", "--kube-version", "latest"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid Kubernetes version")
+}
+
+func TestExecuteReportJSON(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Empty(t, receivedConfig.ReportJSON)
+
+	t.Setenv("ARGO_COMPARE_REPORT_JSON", "env-report.json")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, "env-report.json", receivedConfig.ReportJSON)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--report-json", "cli-report.json"}))
+	assert.Equal(t, "cli-report.json", receivedConfig.ReportJSON, "CLI flag should win")
+
+	t.Setenv("ARGO_COMPARE_REPORT_SARIF", "env.sarif")
+	t.Setenv("ARGO_COMPARE_REPORT_JUNIT", "env-junit.xml")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, "env.sarif", receivedConfig.ReportSARIF)
+	assert.Equal(t, "env-junit.xml", receivedConfig.ReportJUnit)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--report-sarif", "cli.sarif", "--report-junit", "cli-junit.xml"}))
+	assert.Equal(t, "cli.sarif", receivedConfig.ReportSARIF)
+	assert.Equal(t, "cli-junit.xml", receivedConfig.ReportJUnit)
+
+	t.Setenv("ARGO_COMPARE_REPORT_CODEQUALITY", "env-codequality.json")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, "env-codequality.json", receivedConfig.ReportCodeQuality)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--report-codequality", "cli-codequality.json"}))
+	assert.Equal(t, "cli-codequality.json", receivedConfig.ReportCodeQuality)
+
+	t.Setenv("ARGO_COMPARE_REPORT_HTML", "env-report.html")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, "env-report.html", receivedConfig.ReportHTML)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--report-html", "cli-report.html"}))
+	assert.Equal(t, "cli-report.html", receivedConfig.ReportHTML)
+
+	t.Setenv("ARGO_COMPARE_PATCH_FILE", "env.patch")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, "env.patch", receivedConfig.PatchFile)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--patch-file", "cli.patch"}))
+	assert.Equal(t, "cli.patch", receivedConfig.PatchFile)
+}
+
+func TestExecuteOutputDir(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Empty(t, receivedConfig.OutputDir)
+
+	t.Setenv("ARGO_COMPARE_OUTPUT_DIR", "env-rendered")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, "env-rendered", receivedConfig.OutputDir)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--output-dir", "cli-rendered"}))
+	assert.Equal(t, "cli-rendered", receivedConfig.OutputDir, "CLI flag should win")
+}
+
+func TestExecuteTemplates(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("ARGO_COMPARE_COMMENT_TEMPLATE", "templates/comment.tmpl")
+	t.Setenv("ARGO_COMPARE_REPORT_TEMPLATES", "templates/summary.tmpl=out/summary.md")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, "templates/comment.tmpl", receivedConfig.CommentTemplate)
+	assert.Equal(t, []app.ReportTemplate{{Template: "templates/summary.tmpl", Output: "out/summary.md"}}, receivedConfig.ReportTemplates)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main",
+		"--comment-template", "cli.tmpl",
+		"--report-template", "a.tmpl=a.md",
+		"--report-template", "b.tmpl=out/b.csv",
+	}))
+	assert.Equal(t, "cli.tmpl", receivedConfig.CommentTemplate)
+	assert.Equal(t, []app.ReportTemplate{
+		{Template: "a.tmpl", Output: "a.md"},
+		{Template: "b.tmpl", Output: "out/b.csv"},
+	}, receivedConfig.ReportTemplates)
+
+	err := Execute(opts, []string{"branch", "main", "--report-template", "missing-output.tmpl"})
+	assert.ErrorContains(t, err, "expected TEMPLATE=OUTPUT")
+}
+
+func TestExecuteExitCode(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		runErr   error
+		wantCode int
+	}{
+		{name: "no differences", runErr: nil, wantCode: ExitCodeNoDifferences},
+		{name: "differences found", runErr: app.ErrDifferencesFound, wantCode: ExitCodeDifferences},
+		{name: "validation failure", runErr: app.ErrManifestValidationFailed, wantCode: ExitCodeGateFailure},
+		{name: "policy violations", runErr: app.ErrPolicyViolations, wantCode: ExitCodeGateFailure},
+		{name: "joined gate failures", runErr: errors.Join(app.ErrManifestValidationFailed, app.ErrRiskThresholdExceeded), wantCode: ExitCodeGateFailure},
+		{name: "gate failure with report error", runErr: errors.Join(app.ErrPolicyViolations, errors.New("send webhook: 502 Bad Gateway")), wantCode: ExitCodeGateFailure},
+		{name: "run error", runErr: errors.New("helm template failed"), wantCode: ExitCodeError},
+		{name: "configuration error", args: []string{"--fail-on-risk", "severe"}, wantCode: ExitCodeError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var receivedConfig app.Config
+			opts := Options{
+				Version:     "test-version",
+				CacheDir:    t.TempDir(),
+				TempDirBase: os.TempDir(),
+				InitLogging: func(bool) {},
+				RunApp: func(_ context.Context, cfg app.Config) error {
+					receivedConfig = cfg
+					return tc.runErr
+				},
+			}
+
+			err := Execute(opts, append([]string{"branch", "main", "--exit-code"}, tc.args...))
+			if tc.wantCode == ExitCodeNoDifferences {
+				require.NoError(t, err)
+				assert.True(t, receivedConfig.DiffExitCode)
+				return
+			}
+
+			var exitErr *ExitError
+			require.ErrorAs(t, err, &exitErr)
+			assert.Equal(t, tc.wantCode, exitErr.Code)
+			if tc.runErr != nil {
+				assert.ErrorIs(t, err, tc.runErr)
+			}
+		})
+	}
+}
+
+func TestExecuteExitCodeUsageErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		env  string
+	}{
+		{name: "unknown flag", args: []string{"branch", "main", "--exit-code", "--no-such-flag"}},
+		{name: "unknown flag before --exit-code", args: []string{"branch", "main", "--no-such-flag", "--exit-code"}},
+		{name: "missing target branch", args: []string{"branch", "--exit-code"}},
+		{name: "too many arguments", args: []string{"branch", "main", "develop", "--exit-code=true"}},
+		{name: "invalid int flag", args: []string{"branch", "main", "--exit-code", "--mask-digest-length", "many"}},
+		{name: "unknown command", args: []string{"compare", "--exit-code"}},
+		{name: "enabled from env", args: []string{"branch", "main", "--no-such-flag"}, env: "true"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("ARGO_COMPARE_EXIT_CODE", tc.env)
+			opts := Options{
+				Version:     "test-version",
+				CacheDir:    t.TempDir(),
+				TempDirBase: os.TempDir(),
+				InitLogging: func(bool) {},
+				RunApp: func(context.Context, app.Config) error {
+					t.Fatal("RunApp must not be called on usage errors")
+					return nil
+				},
+			}
+
+			err := Execute(opts, tc.args)
+			var exitErr *ExitError
+			require.ErrorAs(t, err, &exitErr)
+			assert.Equal(t, ExitCodeError, exitErr.Code)
+		})
+	}
+}
+
+func TestExecuteUsageErrorsWithoutExitCode(t *testing.T) {
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+	}
+
+	for _, args := range [][]string{
+		{"branch", "main", "--no-such-flag"},
+		{"branch", "main", "--exit-code=false", "--no-such-flag"},
+	} {
+		err := Execute(opts, args)
+		require.Error(t, err)
+		var exitErr *ExitError
+		assert.False(t, errors.As(err, &exitErr), "%v", args)
+	}
+}
+
+func TestExecuteWithoutExitCodeKeepsPlainErrors(t *testing.T) {
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			assert.False(t, cfg.DiffExitCode)
+			return app.ErrManifestValidationFailed
+		},
+	}
+
+	err := Execute(opts, []string{"branch", "main"})
+	var exitErr *ExitError
+	assert.False(t, errors.As(err, &exitErr))
+	assert.ErrorIs(t, err, app.ErrManifestValidationFailed)
+}
+
+func TestExecuteExitCodeFromEnv(t *testing.T) {
+	var receivedConfig app.Config
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return app.ErrDifferencesFound
+		},
+	}
+
+	t.Setenv("ARGO_COMPARE_EXIT_CODE", "true")
+	err := Execute(opts, []string{"branch", "main"})
+
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, ExitCodeDifferences, exitErr.Code)
+	assert.True(t, receivedConfig.DiffExitCode)
+}
+
+func TestExecuteGitHubCommentDefaults(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	eventPath := filepath.Join(t.TempDir(), "event.json")
+	require.NoError(t, os.WriteFile(eventPath, []byte(`{"action":"synchronize","number":17,"pull_request":{"number":17}}`), 0o600))
+
+	t.Setenv("GITLAB_CI", "")
+	t.Setenv("ARGO_COMPARE_COMMENT_PROVIDER", "github")
+	t.Setenv("GITHUB_API_URL", "https://github.example.com/api/v3")
+	t.Setenv("GITHUB_TOKEN", "ghs_token")
+	t.Setenv("GITHUB_REPOSITORY", "octo/deploy")
+	t.Setenv("GITHUB_EVENT_PATH", eventPath)
+	t.Setenv("GITHUB_REF", "refs/pull/99/merge")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	require.NotNil(t, receivedConfig.Comment)
+	assert.Equal(t, app.CommentProviderGitHub, receivedConfig.Comment.Provider)
+	assert.Equal(t, app.GitHubCommentConfig{
+		BaseURL:     "https://github.example.com/api/v3",
+		Token:       "ghs_token",
+		Repository:  "octo/deploy",
+		PullRequest: 17,
+	}, receivedConfig.Comment.GitHub)
+
+	t.Setenv("GITHUB_EVENT_PATH", "")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, 99, receivedConfig.Comment.GitHub.PullRequest, "falls back to the pull request ref")
+
+	t.Setenv("ARGO_COMPARE_GITHUB_PR_NUMBER", "5")
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--github-repository", "octo/other"}))
+	assert.Equal(t, 5, receivedConfig.Comment.GitHub.PullRequest)
+	assert.Equal(t, "octo/other", receivedConfig.Comment.GitHub.Repository)
+}
+
+func TestExecuteGiteaCommentDefaults(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("GITLAB_CI", "")
+	t.Setenv("ARGO_COMPARE_COMMENT_PROVIDER", "gitea")
+	t.Setenv("GITEA_ACTIONS", "true")
+	t.Setenv("GITHUB_SERVER_URL", "https://forgejo.example.com")
+	t.Setenv("GITHUB_REPOSITORY", "platform/gitops")
+	t.Setenv("GITHUB_REF", "refs/pull/23/head")
+	t.Setenv("GITEA_TOKEN", "forgejo-token")
+	t.Setenv("ARGO_COMPARE_GITEA_CA_BUNDLE", "/etc/ssl/forgejo-ca.pem")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	require.NotNil(t, receivedConfig.Comment)
+	assert.Equal(t, app.CommentProviderGitea, receivedConfig.Comment.Provider)
+	assert.Equal(t, app.GiteaCommentConfig{
+		BaseURL:     "https://forgejo.example.com",
+		Token:       "forgejo-token",
+		Repository:  "platform/gitops",
+		PullRequest: 23,
+		CABundle:    "/etc/ssl/forgejo-ca.pem",
+	}, receivedConfig.Comment.Gitea)
+
+	t.Setenv("GITEA_SERVER_URL", "https://gitea.internal")
+	t.Setenv("GITEA_REF", "refs/pull/24/head")
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--gitea-repository", "other/repo"}))
+	assert.Equal(t, "https://gitea.internal", receivedConfig.Comment.Gitea.BaseURL, "GITEA_* variables win over the GITHUB_* aliases")
+	assert.Equal(t, 24, receivedConfig.Comment.Gitea.PullRequest)
+	assert.Equal(t, "other/repo", receivedConfig.Comment.Gitea.Repository)
+}
+
+func TestExecuteBitbucketCommentDefaults(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("GITLAB_CI", "")
+	t.Setenv("ARGO_COMPARE_COMMENT_PROVIDER", "bitbucket")
+	t.Setenv("BITBUCKET_WORKSPACE", "acme")
+	t.Setenv("BITBUCKET_REPO_SLUG", "gitops")
+	t.Setenv("BITBUCKET_PR_ID", "31")
+	t.Setenv("ARGO_COMPARE_BITBUCKET_TOKEN", "repo-access-token")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	require.NotNil(t, receivedConfig.Comment)
+	assert.Equal(t, app.CommentProviderBitbucket, receivedConfig.Comment.Provider)
+	assert.Equal(t, app.BitbucketCommentConfig{
+		Workspace:   "acme",
+		Repository:  "gitops",
+		PullRequest: 31,
+		Token:       "repo-access-token",
+	}, receivedConfig.Comment.Bitbucket)
+
+	require.NoError(t, Execute(opts, []string{
+		"branch", "main",
+		"--bitbucket-data-center",
+		"--bitbucket-url", "https://bitbucket.example.com",
+		"--bitbucket-workspace", "OPS",
+		"--bitbucket-username", "deploy-bot",
+	}))
+	assert.Equal(t, app.BitbucketCommentConfig{
+		BaseURL:     "https://bitbucket.example.com",
+		DataCenter:  true,
+		Workspace:   "OPS",
+		Repository:  "gitops",
+		PullRequest: 31,
+		Username:    "deploy-bot",
+		Token:       "repo-access-token",
+	}, receivedConfig.Comment.Bitbucket)
+}
+
+func TestExecuteAzureDevOpsCommentDefaults(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("GITLAB_CI", "")
+	t.Setenv("ARGO_COMPARE_COMMENT_PROVIDER", "azuredevops")
+	t.Setenv("SYSTEM_COLLECTIONURI", "https://dev.azure.com/acme/")
+	t.Setenv("SYSTEM_TEAMPROJECT", "Platform Team")
+	t.Setenv("BUILD_REPOSITORY_ID", "0b2c5e1a-7d1f-4c3e-9a7b-2f5d8e6c1a90")
+	t.Setenv("SYSTEM_PULLREQUEST_PULLREQUESTID", "77")
+	t.Setenv("SYSTEM_ACCESSTOKEN", "system-access-token")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	require.NotNil(t, receivedConfig.Comment)
+	assert.Equal(t, app.CommentProviderAzureDevOps, receivedConfig.Comment.Provider)
+	assert.Equal(t, app.AzureDevOpsCommentConfig{
+		OrganizationURL: "https://dev.azure.com/acme/",
+		Project:         "Platform Team",
+		Repository:      "0b2c5e1a-7d1f-4c3e-9a7b-2f5d8e6c1a90",
+		PullRequest:     77,
+		Token:           "system-access-token",
+	}, receivedConfig.Comment.AzureDevOps)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--azure-devops-token", "pat", "--azure-devops-pat"}))
+	assert.Equal(t, "pat", receivedConfig.Comment.AzureDevOps.Token)
+	assert.True(t, receivedConfig.Comment.AzureDevOps.PersonalAccessToken)
+}
+
+func TestExecuteCommentMode(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_MERGE_REQUEST_IID", "42")
+	t.Setenv("CI_PROJECT_ID", "321")
+	t.Setenv("CI_SERVER_URL", "https://gitlab.example.com")
+	t.Setenv("CI_JOB_TOKEN", "job-token")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	require.NotNil(t, receivedConfig.Comment)
+	assert.Equal(t, app.CommentModeUpdate, receivedConfig.Comment.Mode)
+
+	t.Setenv("ARGO_COMPARE_COMMENT_MODE", "append")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, app.CommentModeAppend, receivedConfig.Comment.Mode)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--comment-mode", "Update"}))
+	assert.Equal(t, app.CommentModeUpdate, receivedConfig.Comment.Mode)
+
+	err := Execute(opts, []string{"branch", "main", "--comment-mode", "replace"})
+	require.ErrorContains(t, err, `unsupported comment mode "replace"`)
+}
+
+func TestExecuteGitLabDiscussions(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_MERGE_REQUEST_IID", "42")
+	t.Setenv("CI_PROJECT_ID", "321")
+	t.Setenv("CI_SERVER_URL", "https://gitlab.example.com")
+	t.Setenv("CI_JOB_TOKEN", "job-token")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	require.NotNil(t, receivedConfig.Comment)
+	assert.Equal(t, app.GitLabDiscussionsOff, receivedConfig.Comment.GitLab.Discussions)
+
+	t.Setenv("ARGO_COMPARE_GITLAB_DISCUSSIONS", "general")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, app.GitLabDiscussionsGeneral, receivedConfig.Comment.GitLab.Discussions)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--gitlab-discussions", "Diff"}))
+	assert.Equal(t, app.GitLabDiscussionsDiff, receivedConfig.Comment.GitLab.Discussions)
+
+	err := Execute(opts, []string{"branch", "main", "--gitlab-discussions", "line"})
+	require.ErrorContains(t, err, `unsupported gitlab discussions setting "line"`)
+}
+
+func TestExecuteGitLabStatus(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("CI_SERVER_URL", "https://gitlab.example.com")
+	t.Setenv("CI_PROJECT_ID", "321")
+	t.Setenv("ARGO_COMPARE_GITLAB_TOKEN", "api-token")
+	t.Setenv("CI_COMMIT_SHA", "merge-sha")
+	t.Setenv("CI_JOB_URL", "https://gitlab.example.com/jobs/7")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Nil(t, receivedConfig.CommitStatus, "statuses are opt-in")
+
+	t.Setenv("ARGO_COMPARE_GITLAB_STATUS", "true")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	require.NotNil(t, receivedConfig.CommitStatus)
+	assert.Nil(t, receivedConfig.Comment, "statuses do not need comments")
+	assert.Equal(t, app.CommitStatusConfig{
+		BaseURL:   "https://gitlab.example.com",
+		Token:     "api-token",
+		ProjectID: "321",
+		SHA:       "merge-sha",
+		TargetURL: "https://gitlab.example.com/jobs/7",
+	}, *receivedConfig.CommitStatus)
+
+	t.Setenv("CI_MERGE_REQUEST_SOURCE_BRANCH_SHA", "source-sha")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Equal(t, "source-sha", receivedConfig.CommitStatus.SHA)
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--gitlab-status-sha", "explicit-sha"}))
+	assert.Equal(t, "explicit-sha", receivedConfig.CommitStatus.SHA)
+
+	t.Setenv("ARGO_COMPARE_GITLAB_TOKEN", "")
+	t.Setenv("CI_JOB_TOKEN", "")
+	err := Execute(opts, []string{"branch", "main"})
+	require.ErrorContains(t, err, "gitlab commit status configuration requires")
+}
+
+func TestExecuteGitLabLabels(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	t.Setenv("CI_SERVER_URL", "https://gitlab.example.com")
+	t.Setenv("CI_PROJECT_ID", "321")
+	t.Setenv("CI_MERGE_REQUEST_IID", "42")
+	t.Setenv("ARGO_COMPARE_GITLAB_TOKEN", "api-token")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Nil(t, receivedConfig.Labels, "labels are opt-in")
+
+	require.NoError(t, Execute(opts, []string{"branch", "main", "--gitlab-labels"}))
+	require.NotNil(t, receivedConfig.Labels)
+	assert.Equal(t, app.LabelsConfig{
+		BaseURL:         "https://gitlab.example.com",
+		Token:           "api-token",
+		ProjectID:       "321",
+		MergeRequestIID: 42,
+	}, *receivedConfig.Labels)
+
+	t.Setenv("ARGO_COMPARE_GITLAB_LABELS_FILE", ".argo-compare/labels.yaml")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	require.NotNil(t, receivedConfig.Labels)
+	assert.Equal(t, ".argo-compare/labels.yaml", receivedConfig.Labels.RulesFile)
+}
+
+func TestExecuteWebhook(t *testing.T) {
+	var receivedConfig app.Config
+
+	opts := Options{
+		Version:     "test-version",
+		CacheDir:    t.TempDir(),
+		TempDirBase: os.TempDir(),
+		InitLogging: func(bool) {},
+		RunApp: func(_ context.Context, cfg app.Config) error {
+			receivedConfig = cfg
+			return nil
+		},
+	}
+
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	assert.Nil(t, receivedConfig.Webhook, "the webhook is opt-in")
+
+	t.Setenv("ARGO_COMPARE_WEBHOOK_URL", "https://hooks.example.com/argo-compare")
+	t.Setenv("ARGO_COMPARE_WEBHOOK_HEADERS", "Authorization: Bearer a,b\nX-Team: platform\n")
+	t.Setenv("ARGO_COMPARE_WEBHOOK_SECRET", "env-secret")
+	require.NoError(t, Execute(opts, []string{"branch", "main"}))
+	require.NotNil(t, receivedConfig.Webhook)
+	assert.Equal(t, app.WebhookConfig{
+		URL:     "https://hooks.example.com/argo-compare",
+		Headers: map[string]string{"Authorization": "Bearer a,b", "X-Team": "platform"},
+		Secret:  "env-secret",
+	}, *receivedConfig.Webhook)
+
+	secretFile := filepath.Join(t.TempDir(), "webhook-secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("file-secret\n"), 0o600))
+	require.NoError(t, Execute(opts, []string{"branch", "main",
+		"--webhook-url", "https://bot.internal/hook",
+		"--webhook-header", "X-Source: argo-compare",
+		"--webhook-secret-file", secretFile,
+	}))
+	require.NotNil(t, receivedConfig.Webhook)
+	assert.Equal(t, app.WebhookConfig{
+		URL:     "https://bot.internal/hook",
+		Headers: map[string]string{"X-Source": "argo-compare"},
+		Secret:  "file-secret",
+	}, *receivedConfig.Webhook)
+
+	err := Execute(opts, []string{"branch", "main", "--webhook-header", "X-Source"})
+	require.ErrorContains(t, err, "--webhook-header: expected 'Name: value'")
+
+	t.Setenv("ARGO_COMPARE_WEBHOOK_URL", "")
+	err = Execute(opts, []string{"branch", "main"})
+	require.ErrorContains(t, err, "require --webhook-url")
+}
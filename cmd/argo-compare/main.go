@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
@@ -12,7 +13,6 @@ import (
 	"github.com/shini4i/argo-compare/cmd/argo-compare/utils/logger"
 	"github.com/shini4i/argo-compare/internal/app"
 	"github.com/shini4i/argo-compare/internal/helpers"
-	"github.com/shini4i/argo-compare/internal/sanitizer"
 	"github.com/spf13/afero"
 )
 
@@ -30,15 +30,16 @@ func loggingInit(debug bool) {
 }
 
 // setupDependencies wires runtime collaborators used by the application.
+// The sensitive-data masker is left to app.New, which keys it from the
+// masking settings in the run configuration.
 func setupDependencies(log *logger.Logger) app.Dependencies {
 	return app.Dependencies{
-		FS:                  afero.NewOsFs(),
-		CmdRunner:           &utils.RealCmdRunner{},
-		FileReader:          utils.OsFileReader{},
-		HelmProcessor:       utils.RealHelmChartProcessor{Log: log},
-		Globber:             utils.CustomGlobber{},
-		Logger:              log,
-		SensitiveDataMasker: sanitizer.NewKubernetesSecretMasker(),
+		FS:            afero.NewOsFs(),
+		CmdRunner:     &utils.RealCmdRunner{},
+		FileReader:    utils.OsFileReader{},
+		HelmProcessor: utils.RealHelmChartProcessor{Log: log},
+		Globber:       utils.CustomGlobber{},
+		Logger:        log,
 	}
 }
 
@@ -47,10 +48,25 @@ func main() {
 	opts := buildOptions()
 
 	if err := command.Execute(opts, nil); err != nil {
-		log.Fatal(err)
+		os.Exit(exitStatus(err))
 	}
 }
 
+// exitStatus logs err and returns the process exit status for it: the code
+// carried by a command.ExitError (--exit-code), or 1 otherwise. The
+// "differences found" outcome of --exit-code is not an error worth logging.
+func exitStatus(err error) int {
+	var exitErr *command.ExitError
+	if !errors.As(err, &exitErr) {
+		log.Error(err)
+		return 1
+	}
+	if !errors.Is(err, app.ErrDifferencesFound) {
+		log.Error(err)
+	}
+	return exitErr.Code
+}
+
 // buildOptions assembles the command execution options from environment defaults.
 func buildOptions() command.Options {
 	return command.Options{
@@ -42,62 +42,61 @@ var ErrAnchorNotPathBased = errors.New("anchored Application is not path-based")
 var ErrValueFileMissingFromSource = errors.New("anchored Application references a values file absent from the current branch")
 
 // compareAnchorGroups runs the path-based rendering pipeline for every anchor
-// group discovered in the diff. It returns true if any rendering produced a
-// non-Valid validation result; the error return is reserved for terminal
-// failures that prevent rendering altogether.
-func (a *App) compareAnchorGroups(ctx context.Context, repo *GitRepo, groups []AnchorGroup) (bool, error) {
+// group discovered in the diff. It returns the accumulated gate outcome; the
+// error return is reserved for terminal failures that prevent rendering
+// altogether.
+func (a *App) compareAnchorGroups(ctx context.Context, repo *GitRepo, groups []AnchorGroup) (comparisonOutcome, error) {
 	if len(groups) == 0 {
-		return false, nil
+		return comparisonOutcome{}, nil
 	}
 
 	repoRoot, err := GetGitRepoRoot()
 	if err != nil {
-		return false, fmt.Errorf("resolve repo root for anchor flow: %w", err)
+		return comparisonOutcome{}, fmt.Errorf("resolve repo root for anchor flow: %w", err)
 	}
 	originURL, err := repo.OriginURL()
 	if err != nil {
-		return false, err
+		return comparisonOutcome{}, err
 	}
 
 	fetcher := a.applicationFetcher()
 
-	anyFailed := false
+	var outcome comparisonOutcome
 	for _, group := range groups {
-		failed, err := a.processAnchorGroup(ctx, repo, group, fetcher, repoRoot, originURL)
+		groupOutcome, err := a.processAnchorGroup(ctx, repo, group, fetcher, repoRoot, originURL)
 		if err != nil {
-			return anyFailed, err
-		}
-		if failed {
-			anyFailed = true
+			return outcome, err
 		}
+		outcome.merge(groupOutcome)
 	}
-	return anyFailed, nil
+	return outcome, nil
 }
 
 // processAnchorGroup renders, diffs, and validates the Application that the
 // anchor points to. tmpDir is created fresh per group and cleaned up at end.
-func (a *App) processAnchorGroup(ctx context.Context, repo *GitRepo, group AnchorGroup, fetcher ports.ApplicationFetcher, repoRoot, originURL string) (validationFailed bool, err error) {
+func (a *App) processAnchorGroup(ctx context.Context, repo *GitRepo, group AnchorGroup, fetcher ports.ApplicationFetcher, repoRoot, originURL string) (outcome comparisonOutcome, err error) {
 	a.logger.Infof("===> Processing anchored chart in [%s]", ui.Cyan(group.Dir))
+	started := now()
 
 	app, err := fetcher.Fetch(ctx, group.Anchor.Application, repoRoot)
 	if err != nil {
-		return false, err
+		return comparisonOutcome{}, err
 	}
 
 	classifyTarget := Target{App: app}
 	if classifyErr := classifyTarget.ClassifySources(); classifyErr != nil {
-		return false, classifyErr
+		return comparisonOutcome{}, classifyErr
 	}
 	if !classifyTarget.PathBased() {
-		return false, fmt.Errorf("%w: %s", ErrAnchorNotPathBased, anchorRefDisplay(group.Anchor.Application))
+		return comparisonOutcome{}, fmt.Errorf("%w: %s", ErrAnchorNotPathBased, anchorRefDisplay(group.Anchor.Application))
 	}
 	if mismatchErr := assertSameRepo(app.Spec.Source, app.Spec.Sources, originURL); mismatchErr != nil {
-		return false, fmt.Errorf("%w: %w", ErrAnchorRepoMismatch, mismatchErr)
+		return comparisonOutcome{}, fmt.Errorf("%w: %w", ErrAnchorRepoMismatch, mismatchErr)
 	}
 
 	tmpDir, err := afero.TempDir(a.fs, a.cfg.TempDirBase, "argo-compare-anchor-")
 	if err != nil {
-		return false, err
+		return comparisonOutcome{}, err
 	}
 	defer func() {
 		if removeErr := (afero.Afero{Fs: a.fs}).RemoveAll(tmpDir); err == nil && removeErr != nil {
@@ -118,23 +117,27 @@ func (a *App) processAnchorGroup(ctx context.Context, repo *GitRepo, group Ancho
 
 	proceed, err := a.renderAnchorLegs(ctx, lc, group)
 	if err != nil {
-		return false, err
+		return comparisonOutcome{}, err
 	}
 	if !proceed {
-		return false, nil
-	}
-
-	if err = a.runComparison(ctx, tmpDir, group.Anchor.Application.Path, validationResults); err != nil {
-		return false, err
+		return comparisonOutcome{}, nil
 	}
 
-	for _, r := range validationResults {
-		if !r.Valid {
-			validationFailed = true
-			break
-		}
-	}
-	return validationFailed, nil
+	result, err := a.runComparison(ctx, tmpDir, group.Anchor.Application.Path, anchorDiffFile(group), app, validationResults)
+	if err != nil {
+		return comparisonOutcome{}, err
+	}
+	a.recordReport(ApplicationReport{
+		File: group.Anchor.Application.Path,
+		Anchor: &AnchorReport{
+			Dir:          group.Dir,
+			Repo:         group.Anchor.Application.Repo,
+			Path:         group.Anchor.Application.Path,
+			Branch:       group.Anchor.Application.Branch,
+			ChangedFiles: group.ChangedFiles,
+		},
+	}, result, tmpDir, app, app, started)
+	return a.outcomeOf(result), nil
 }
 
 // anchorLegContext carries the per-group state shared by both render legs
@@ -310,6 +313,16 @@ func (a *App) applicationFetcher() ports.ApplicationFetcher {
 	}
 }
 
+// anchorDiffFile returns the changed file review comments on the group's
+// result are attached to: the first file that seeded the group, since the
+// anchored Application itself is usually not part of the change.
+func anchorDiffFile(group AnchorGroup) string {
+	if len(group.ChangedFiles) == 0 {
+		return ""
+	}
+	return group.ChangedFiles[0]
+}
+
 // anchorRefDisplay produces a human-readable identifier for log/error messages.
 func anchorRefDisplay(ref anchor.ApplicationRef) string {
 	if ref.Repo == "" {
@@ -10,15 +10,24 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/shini4i/argo-compare/cmd/argo-compare/utils"
 	"github.com/shini4i/argo-compare/cmd/argo-compare/utils/logger"
 	"github.com/shini4i/argo-compare/internal/comment"
+	"github.com/shini4i/argo-compare/internal/comment/azuredevops"
+	"github.com/shini4i/argo-compare/internal/comment/bitbucket"
+	"github.com/shini4i/argo-compare/internal/comment/gitea"
+	"github.com/shini4i/argo-compare/internal/comment/github"
 	"github.com/shini4i/argo-compare/internal/comment/gitlab"
+	"github.com/shini4i/argo-compare/internal/label"
 	"github.com/shini4i/argo-compare/internal/models"
+	"github.com/shini4i/argo-compare/internal/policy"
 	"github.com/shini4i/argo-compare/internal/ports"
 	"github.com/shini4i/argo-compare/internal/sanitizer"
+	"github.com/shini4i/argo-compare/internal/status"
 	"github.com/shini4i/argo-compare/internal/ui"
+	"github.com/shini4i/argo-compare/internal/webhook"
 	"github.com/spf13/afero"
 )
 
@@ -34,6 +43,11 @@ const defaultKubeconformBinary = "kubeconform"
 // callers/CI can fail the job after the diff and any comments have been emitted.
 var ErrManifestValidationFailed = errors.New("manifest validation failed")
 
+// ErrDifferencesFound is returned by Run when WithDiffExitCode is enabled and at
+// least one comparison produced a diff, mirroring `git diff --exit-code`. Gate
+// failures (validation, policies, risk threshold) take precedence over it.
+var ErrDifferencesFound = errors.New("differences found")
+
 // Dependencies aggregates runtime collaborators required by App.
 type Dependencies struct {
 	FS                   afero.Fs
@@ -43,6 +57,9 @@ type Dependencies struct {
 	Globber              ports.Globber
 	Logger               *logger.Logger
 	CommentPosterFactory CommentPosterFactory
+	CommitStatusReporter status.Reporter            // Reports cfg.CommitStatus. Optional; defaults to a GitLab reporter.
+	MergeRequestLabeler  label.Labeler              // Applies cfg.Labels. Optional; defaults to a GitLab labeler.
+	WebhookSender        WebhookSender              // Delivers cfg.Webhook. Optional; defaults to an HTTP sender.
 	SensitiveDataMasker  ports.SensitiveDataMasker  // Responsible for redacting sensitive manifest fields.
 	CredentialProviders  []ports.CredentialProvider // Dynamic credential providers (e.g. ECR). Optional; defaults include ECR.
 	ManifestValidator    ports.ManifestValidator    // Validator for rendered manifests. Optional; defaults to KubeconformValidator if validation is enabled.
@@ -65,6 +82,14 @@ type App struct {
 	sensitiveDataMasker ports.SensitiveDataMasker // Applied to manifest content prior to diff generation.
 	validator           ports.ManifestValidator   // Optional validator for rendered manifests.
 	fetcher             ports.ApplicationFetcher  // Resolves anchored Applications. Optional; defaults to a real impl.
+	policies            *policy.Set               // Policies from cfg.PolicyFile; nil when none are configured.
+	runPresenters       []RunPresenter            // Machine-readable reports written once the run completes.
+	reports             []ApplicationReport       // Collected while comparing when runPresenters are configured.
+	exported            applicationDirs           // Export directories claimed during the run.
+	compared            []string                  // Application files compared during the run.
+	commentTemplate     *template.Template        // From cfg.CommentTemplate; nil selects the built-in comment layout.
+	statusReporter      status.Reporter           // Set when cfg.CommitStatus is configured.
+	revision            string                    // HEAD commit of the run; empty when it cannot be resolved.
 }
 
 // CommentPosterFactory builds a comment poster based on the active configuration.
@@ -74,8 +99,8 @@ type CommentPosterFactory func(cfg Config) (comment.Poster, error)
 // The provided Config must include a non-empty CacheDir and Dependencies must
 // include a Logger. Any nil dependency fields are replaced with sensible
 // defaults (OS filesystem, real command runner, OS file reader, real Helm
-// processor, globber, default comment poster factory, and a Kubernetes secret
-// sensitive-data masker). It returns the constructed *App or an error if
+// processor, globber, default comment poster factory, and a sensitive-data
+// masker chaining Secret and rule-based masking). It returns the constructed *App or an error if
 // validation fails.
 func New(cfg Config, deps Dependencies) (*App, error) {
 	if cfg.CacheDir == "" {
@@ -109,7 +134,11 @@ func New(cfg Config, deps Dependencies) (*App, error) {
 		deps.CommentPosterFactory = defaultCommentPosterFactory
 	}
 	if deps.SensitiveDataMasker == nil {
-		deps.SensitiveDataMasker = sanitizer.NewKubernetesSecretMasker()
+		masker, err := newSensitiveDataMasker(cfg, deps.FileReader, deps.Logger)
+		if err != nil {
+			return nil, err
+		}
+		deps.SensitiveDataMasker = masker
 	}
 	if deps.CredentialProviders == nil {
 		deps.CredentialProviders = []ports.CredentialProvider{
@@ -117,6 +146,48 @@ func New(cfg Config, deps Dependencies) (*App, error) {
 		}
 	}
 
+	if cfg.CommitStatus != nil && deps.CommitStatusReporter == nil {
+		reporter, err := gitlab.NewStatusReporter(gitlab.StatusConfig{
+			BaseURL:   cfg.CommitStatus.BaseURL,
+			Token:     cfg.CommitStatus.Token,
+			ProjectID: cfg.CommitStatus.ProjectID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		deps.CommitStatusReporter = reporter
+	}
+
+	policies, err := loadPolicies(cfg, deps.FileReader)
+	if err != nil {
+		return nil, err
+	}
+
+	commentTemplate, err := loadCommentTemplate(cfg, deps.FileReader)
+	if err != nil {
+		return nil, err
+	}
+	templateReports, err := loadReportTemplates(cfg, deps.FS, deps.FileReader)
+	if err != nil {
+		return nil, err
+	}
+
+	runPresenters := append(selectRunPresenters(cfg, deps.FS), templateReports...)
+	if cfg.Labels != nil {
+		labeler, err := newMergeRequestLabeler(cfg, deps.FileReader, deps.MergeRequestLabeler)
+		if err != nil {
+			return nil, err
+		}
+		runPresenters = append(runPresenters, labeler)
+	}
+	if cfg.Webhook != nil {
+		notifier, err := newWebhookNotifier(cfg, deps.WebhookSender)
+		if err != nil {
+			return nil, err
+		}
+		runPresenters = append(runPresenters, notifier)
+	}
+
 	var validator ports.ManifestValidator
 	if deps.ManifestValidator != nil {
 		validator = deps.ManifestValidator
@@ -146,12 +217,95 @@ func New(cfg Config, deps Dependencies) (*App, error) {
 		sensitiveDataMasker: deps.SensitiveDataMasker,
 		validator:           validator,
 		fetcher:             deps.ApplicationFetcher,
+		policies:            policies,
+		runPresenters:       runPresenters,
+		commentTemplate:     commentTemplate,
+		statusReporter:      statusReporterFor(cfg, deps.CommitStatusReporter),
 	}, nil
 }
 
+// newMergeRequestLabeler returns the run presenter applying cfg.Labels,
+// using labeler or else a GitLab one.
+func newMergeRequestLabeler(cfg Config, reader ports.FileReader, labeler label.Labeler) (MergeRequestLabeler, error) {
+	rules, err := loadLabelRules(cfg, reader)
+	if err != nil {
+		return MergeRequestLabeler{}, err
+	}
+	if labeler == nil {
+		gitlabLabeler, err := gitlab.NewLabeler(gitlab.LabelerConfig{
+			BaseURL:         cfg.Labels.BaseURL,
+			Token:           cfg.Labels.Token,
+			ProjectID:       cfg.Labels.ProjectID,
+			MergeRequestIID: cfg.Labels.MergeRequestIID,
+		})
+		if err != nil {
+			return MergeRequestLabeler{}, err
+		}
+		labeler = gitlabLabeler
+	}
+	// A --file run compares one Application at most, so the labels of the
+	// others must survive it.
+	return MergeRequestLabeler{Labeler: labeler, Rules: rules, AddOnly: cfg.FileToCompare != ""}, nil
+}
+
+// newWebhookNotifier returns the run presenter delivering cfg.Webhook, using
+// sender or else an HTTP one.
+func newWebhookNotifier(cfg Config, sender WebhookSender) (WebhookNotifier, error) {
+	if sender == nil {
+		httpSender, err := webhook.NewSender(webhook.Config{
+			URL:     cfg.Webhook.URL,
+			Headers: cfg.Webhook.Headers,
+			Secret:  cfg.Webhook.Secret,
+		})
+		if err != nil {
+			return WebhookNotifier{}, err
+		}
+		sender = httpSender
+	}
+	return WebhookNotifier{Sender: sender}, nil
+}
+
+// statusReporterFor returns reporter when commit statuses are configured.
+func statusReporterFor(cfg Config, reporter status.Reporter) status.Reporter {
+	if cfg.CommitStatus == nil {
+		return nil
+	}
+	return reporter
+}
+
+// selectRunPresenters picks the machine-readable report writers requested by
+// the configuration.
+func selectRunPresenters(cfg Config, fs afero.Fs) []RunPresenter {
+	var presenters []RunPresenter
+	if cfg.ReportJSON != "" {
+		presenters = append(presenters, JSONReportWriter{Fs: fs, Path: cfg.ReportJSON})
+	}
+	if cfg.ReportSARIF != "" {
+		presenters = append(presenters, SARIFReportWriter{Fs: fs, Path: cfg.ReportSARIF})
+	}
+	if cfg.ReportJUnit != "" {
+		presenters = append(presenters, JUnitReportWriter{Fs: fs, Path: cfg.ReportJUnit})
+	}
+	if cfg.ReportCodeQuality != "" {
+		presenters = append(presenters, CodeQualityReportWriter{Fs: fs, Path: cfg.ReportCodeQuality})
+	}
+	if cfg.ReportHTML != "" {
+		presenters = append(presenters, HTMLReportWriter{Fs: fs, Path: cfg.ReportHTML})
+	}
+	if cfg.PatchFile != "" {
+		presenters = append(presenters, PatchWriter{Fs: fs, Path: cfg.PatchFile})
+	}
+	return presenters
+}
+
 // Run executes the comparison workflow and returns any terminal error.
 // The context can be used for cancellation and timeout control.
 func (a *App) Run(ctx context.Context) error {
+	started := now()
+	a.reports = nil
+	a.exported = nil
+	a.compared = nil
+
 	if err := a.collectRepoCredentials(); err != nil {
 		return err
 	}
@@ -167,6 +321,9 @@ func (a *App) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if a.revision, err = repo.HeadCommit(); err != nil {
+		a.logger.Debugf("Unable to resolve the current commit: %v", err)
+	}
 
 	a.logger.Infof("===> Running Argo Compare version [%s]", ui.Cyan(a.cfg.Version))
 
@@ -174,31 +331,103 @@ func (a *App) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	// Runs without anything to compare still present an empty report, so CI
+	// artifacts, labels and webhooks reflect the latest state of the branch.
 	if inputs.exitEarly {
-		return nil
+		return a.presentRun(ctx, started, nil)
 	}
 
 	if len(inputs.changed) == 0 && len(inputs.groups) == 0 {
 		a.logger.Info("No changed Application files found. Exiting...")
-		return nil
+		return errors.Join(a.markStaleComments(ctx), a.presentRun(ctx, started, inputs.invalid))
 	}
 
-	validationFailed, err := a.runComparisons(ctx, repo, inputs.changed, inputs.groups)
+	outcome, err := a.runComparisons(ctx, repo, inputs.changed, inputs.groups)
 	if err != nil {
 		return err
 	}
 
+	// Gate failures come ahead of report errors, so that --exit-code still
+	// reports a blocked change when a report could not be delivered.
+	reportErr := errors.Join(a.markStaleComments(ctx), a.presentRun(ctx, started, inputs.invalid))
+
 	if err := a.reportInvalidFiles(inputs.invalid); err != nil {
-		return err
+		return errors.Join(err, reportErr)
 	}
 
-	if validationFailed {
-		return ErrManifestValidationFailed
+	if err := outcome.err(); err != nil {
+		return errors.Join(err, reportErr)
 	}
 
+	if reportErr != nil {
+		return reportErr
+	}
+	if a.cfg.DiffExitCode && outcome.differencesFound {
+		return ErrDifferencesFound
+	}
 	return nil
 }
 
+// comparisonOutcome accumulates the gate-relevant facts of the comparisons in
+// a run. They are independent of the error return so the run can complete
+// (print diffs, post comments) before deciding to exit non-zero.
+type comparisonOutcome struct {
+	validationFailed bool // Some rendered manifest failed validation, or the validator could not run.
+	policyViolated   bool // Some rendered resource violated a configured policy.
+	riskExceeded     bool // Some risk finding met the --fail-on-risk threshold.
+	differencesFound bool // Some comparison produced a diff.
+}
+
+// merge folds other into o.
+func (o *comparisonOutcome) merge(other comparisonOutcome) {
+	o.validationFailed = o.validationFailed || other.validationFailed
+	o.policyViolated = o.policyViolated || other.policyViolated
+	o.riskExceeded = o.riskExceeded || other.riskExceeded
+	o.differencesFound = o.differencesFound || other.differencesFound
+}
+
+// err converts the gate failures of the outcome into the sentinel errors
+// returned by Run. Differences alone are not an error; Run decides whether to
+// report them based on the configuration.
+func (o comparisonOutcome) err() error {
+	var errs []error
+	if o.validationFailed {
+		errs = append(errs, ErrManifestValidationFailed)
+	}
+	if o.policyViolated {
+		errs = append(errs, ErrPolicyViolations)
+	}
+	if o.riskExceeded {
+		errs = append(errs, ErrRiskThresholdExceeded)
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// outcomeOf derives the gate outcome of a single comparison result.
+func (a *App) outcomeOf(result ComparisonResult) comparisonOutcome {
+	return resultOutcome(result, a.cfg.FailOnRisk)
+}
+
+// resultOutcome derives the gate outcome of a single comparison result, with
+// failOnRisk as the --fail-on-risk threshold (zero disables it).
+func resultOutcome(result ComparisonResult, failOnRisk RiskSeverity) comparisonOutcome {
+	outcome := comparisonOutcome{differencesFound: !result.IsEmpty()}
+	for _, r := range result.ValidationResults {
+		if !r.Valid {
+			outcome.validationFailed = true
+			break
+		}
+	}
+	outcome.policyViolated = len(result.PolicyViolations) > 0
+	if failOnRisk > 0 && highestRisk(result.Findings) >= failOnRisk {
+		outcome.riskExceeded = true
+	}
+	return outcome
+}
+
 // comparisonInputs bundles the inputs needed by the comparison loop. exitEarly
 // is set when the only configured target was filtered out, allowing the caller
 // to short-circuit without surfacing an error.
@@ -235,29 +464,29 @@ func (a *App) collectComparisonInputs(repo *GitRepo) (comparisonInputs, error) {
 }
 
 // runComparisons fans out the comparison work across changed Application files
-// and anchor groups, returning whether any validation step produced a non-Valid
-// result. Errors short-circuit; the validation flag is accumulated across both
-// branches so a single failure surfaces ErrManifestValidationFailed.
-func (a *App) runComparisons(ctx context.Context, repo *GitRepo, changedFiles []string, anchorGroups []AnchorGroup) (bool, error) {
-	validationFailed := false
+// and anchor groups, returning the accumulated outcome. Errors short-circuit;
+// the outcome is accumulated across both branches so a single validation
+// failure, policy violation or risk finding surfaces the matching sentinel error.
+func (a *App) runComparisons(ctx context.Context, repo *GitRepo, changedFiles []string, anchorGroups []AnchorGroup) (comparisonOutcome, error) {
+	var outcome comparisonOutcome
 
 	if len(changedFiles) > 0 {
-		failed, err := a.compareFiles(ctx, repo, changedFiles)
+		fileOutcome, err := a.compareFiles(ctx, repo, changedFiles)
 		if err != nil {
-			return false, err
+			return comparisonOutcome{}, err
 		}
-		validationFailed = validationFailed || failed
+		outcome.merge(fileOutcome)
 	}
 
 	if len(anchorGroups) > 0 {
-		failed, err := a.compareAnchorGroups(ctx, repo, anchorGroups)
+		groupOutcome, err := a.compareAnchorGroups(ctx, repo, anchorGroups)
 		if err != nil {
-			return false, err
+			return comparisonOutcome{}, err
 		}
-		validationFailed = validationFailed || failed
+		outcome.merge(groupOutcome)
 	}
 
-	return validationFailed, nil
+	return outcome, nil
 }
 
 // dedupAnchorGroups drops anchor groups whose target Application file already
@@ -289,21 +518,19 @@ func dedupAnchorGroups(groups []AnchorGroup, changedApps []string) []AnchorGroup
 }
 
 // compareFiles renders and evaluates each changed Application manifest against the target branch.
-// Returns true if any application produced a non-Valid validation result (schema failure or
-// validator invocation error). The bool is independent of err so the caller can complete the
-// run (post comments, etc.) before deciding to exit non-zero.
-func (a *App) compareFiles(ctx context.Context, repo *GitRepo, changedFiles []string) (bool, error) {
-	anyFailed := false
+// The returned outcome records whether any application produced a non-Valid validation result
+// (schema failure or validator invocation error), a policy violation, or a risk finding at the
+// configured threshold.
+func (a *App) compareFiles(ctx context.Context, repo *GitRepo, changedFiles []string) (comparisonOutcome, error) {
+	var outcome comparisonOutcome
 	for _, file := range changedFiles {
-		failed, err := a.processChangedFile(ctx, repo, file)
+		fileOutcome, err := a.processChangedFile(ctx, repo, file)
 		if err != nil {
-			return anyFailed, err
-		}
-		if failed {
-			anyFailed = true
+			return outcome, err
 		}
+		outcome.merge(fileOutcome)
 	}
-	return anyFailed, nil
+	return outcome, nil
 }
 
 type destinationAction int
@@ -315,13 +542,14 @@ const (
 )
 
 // processChangedFile orchestrates comparison for a single manifest, optionally skipping targets.
-// Returns a flag indicating whether any validation result for this application was non-Valid.
-func (a *App) processChangedFile(ctx context.Context, repo *GitRepo, file string) (validationFailed bool, err error) {
+// Returns the gate outcome of the application's comparison.
+func (a *App) processChangedFile(ctx context.Context, repo *GitRepo, file string) (outcome comparisonOutcome, err error) {
 	a.logger.Infof("===> Processing changed application: [%s]", ui.Cyan(file))
+	started := now()
 
 	tmpDir, err := afero.TempDir(a.fs, a.cfg.TempDirBase, "argo-compare-")
 	if err != nil {
-		return false, err
+		return comparisonOutcome{}, err
 	}
 
 	defer func() {
@@ -333,36 +561,32 @@ func (a *App) processChangedFile(ctx context.Context, repo *GitRepo, file string
 	// Scoped per-comparison: keeps state local and avoids cross-app leakage.
 	validationResults := make(map[string]ports.ValidationResult)
 
-	if err = a.processFile(ctx, repo, file, TargetTypeSource, models.Application{}, tmpDir, validationResults); err != nil {
-		return false, err
+	sourceApp, err := a.processFile(ctx, repo, file, TargetTypeSource, models.Application{}, tmpDir, validationResults)
+	if err != nil {
+		return comparisonOutcome{}, err
 	}
 
 	targetApp, action, err := a.resolveTargetApplication(repo, file)
 	if err != nil {
-		return false, err
+		return comparisonOutcome{}, err
 	}
 
 	if action == destinationSkip {
-		return false, nil
+		return comparisonOutcome{}, nil
 	}
 
 	if action == destinationProcess {
-		if destErr := a.processFile(ctx, repo, file, TargetTypeDestination, targetApp, tmpDir, validationResults); destErr != nil && !a.cfg.PrintAddedManifests {
-			return false, destErr
+		if _, destErr := a.processFile(ctx, repo, file, TargetTypeDestination, targetApp, tmpDir, validationResults); destErr != nil && !a.cfg.PrintAddedManifests {
+			return comparisonOutcome{}, destErr
 		}
 	}
 
-	if err := a.runComparison(ctx, tmpDir, file, validationResults); err != nil {
-		return false, err
-	}
-
-	for _, r := range validationResults {
-		if !r.Valid {
-			validationFailed = true
-			break
-		}
+	result, err := a.runComparison(ctx, tmpDir, file, file, sourceApp, validationResults)
+	if err != nil {
+		return comparisonOutcome{}, err
 	}
-	return validationFailed, nil
+	a.recordReport(ApplicationReport{File: file}, result, tmpDir, sourceApp, targetApp, started)
+	return a.outcomeOf(result), nil
 }
 
 // resolveTargetApplication retrieves the target branch manifest and determines follow-up actions.
@@ -429,7 +653,8 @@ func decideDestinationAction(err error, printAdded bool) (destinationAction, err
 	}
 }
 
-// processFile prepares Helm inputs for a single manifest and renders its templates.
+// processFile prepares Helm inputs for a single manifest and renders its templates,
+// returning the Application it rendered (parsed from fileName for the source leg).
 // validationResults is populated when a validator is configured; entries are keyed by fileType.
 //
 // For registry-based sources (spec.source.chart set) the chart is fetched via
@@ -437,7 +662,7 @@ func decideDestinationAction(err error, printAdded bool) (destinationAction, err
 // (spec.source.path set) the chart directory is materialized into the same
 // on-disk layout from the local working tree (src leg) or the merge-base tree
 // (dst leg), and the registry plumbing is skipped.
-func (a *App) processFile(ctx context.Context, repo *GitRepo, fileName, fileType string, application models.Application, tmpDir string, validationResults map[string]ports.ValidationResult) error {
+func (a *App) processFile(ctx context.Context, repo *GitRepo, fileName, fileType string, application models.Application, tmpDir string, validationResults map[string]ports.ValidationResult) (models.Application, error) {
 	target := Target{
 		CmdRunner:           a.cmdRunner,
 		FileReader:          a.fileReader,
@@ -454,28 +679,28 @@ func (a *App) processFile(ctx context.Context, repo *GitRepo, fileName, fileType
 
 	if fileType == TargetTypeSource {
 		if err := target.parse(); err != nil {
-			return err
+			return models.Application{}, err
 		}
 	}
 
 	if err := target.ClassifySources(); err != nil {
-		return err
+		return models.Application{}, err
 	}
 
 	if err := target.generateValuesFiles(); err != nil {
-		return err
+		return models.Application{}, err
 	}
 
 	if err := a.prepareChart(ctx, repo, &target, fileType); err != nil {
-		return err
+		return models.Application{}, err
 	}
 
 	if err := target.renderAppSources(ctx); err != nil {
-		return err
+		return models.Application{}, err
 	}
 
 	a.runManifestValidation(ctx, fileType, tmpDir, validationResults)
-	return nil
+	return target.App, nil
 }
 
 // prepareChart materializes the chart inputs for a target. Path-based sources
@@ -519,8 +744,11 @@ func (a *App) runManifestValidation(ctx context.Context, fileType, tmpDir string
 	}
 }
 
-// runComparison executes the diff strategy for the prepared temporary workspace.
-func (a *App) runComparison(ctx context.Context, tmpDir, applicationFile string, validationResults map[string]ports.ValidationResult) error {
+// runComparison executes the diff strategy for the prepared temporary workspace
+// and returns the presented result. application is the source-leg Application,
+// which may pin the Kubernetes version used for the deprecation check.
+// diffFile is the changed file the result is attached to in review comments.
+func (a *App) runComparison(ctx context.Context, tmpDir, applicationFile, diffFile string, application models.Application, validationResults map[string]ports.ValidationResult) (ComparisonResult, error) {
 	comparer := Compare{
 		Fs:                 a.fs,
 		Globber:            a.globber,
@@ -531,46 +759,95 @@ func (a *App) runComparison(ctx context.Context, tmpDir, applicationFile string,
 
 	result, err := comparer.Execute()
 	if err != nil {
-		return err
+		return ComparisonResult{}, err
 	}
 
 	if len(validationResults) > 0 {
 		result.ValidationResults = validationResults
 	}
+	result.PolicyViolations = evaluatePolicies(a.policies, result.Resources)
+	result.Findings = assessRisks(result.Resources)
+	result.Deprecations = checkDeprecations(a.targetKubeVersion(application), result.Resources)
+
+	if err := a.exportManifests(applicationFile, application, result.Resources); err != nil {
+		return ComparisonResult{}, err
+	}
+	a.compared = append(a.compared, applicationFile)
 
-	strategies, err := a.selectDiffStrategies(applicationFile)
+	strategies, err := a.selectDiffStrategies(applicationFile, diffFile)
 	if err != nil {
-		return err
+		return ComparisonResult{}, err
 	}
 
 	for _, strategy := range strategies {
 		if err := strategy.Present(ctx, result); err != nil {
-			return err
+			return ComparisonResult{}, err
 		}
 	}
 
-	return nil
+	return result, nil
+}
+
+// markStaleComments marks stale the diff comments of Applications that an
+// earlier run commented on but this run did not compare. Runs limited to one
+// file with --file leave the other Applications' comments alone.
+func (a *App) markStaleComments(ctx context.Context) error {
+	if a.cfg.FileToCompare != "" || a.cfg.Comment == nil || a.cfg.Comment.Provider == CommentProviderNone ||
+		a.cfg.Comment.Mode == CommentModeAppend {
+		return nil
+	}
+
+	poster, err := a.commentFactory(a.cfg)
+	if err != nil {
+		return err
+	}
+	upserter, ok := poster.(comment.Upserter)
+	if !ok {
+		return nil
+	}
+
+	marked, err := markUncomparedStale(ctx, poster, upserter, a.compared, a.revision)
+	for _, applicationPath := range marked {
+		a.logger.Infof("Marked diff comments of %s stale: the Application file is no longer changed", applicationPath)
+	}
+	return err
 }
 
 // selectDiffStrategies picks the appropriate diff presentation implementations based on configuration.
-func (a *App) selectDiffStrategies(applicationFile string) ([]DiffPresenter, error) {
+func (a *App) selectDiffStrategies(applicationFile, diffFile string) ([]DiffPresenter, error) {
 	var strategies []DiffPresenter
 
+	var console DiffPresenter
 	if a.cfg.ExternalDiffTool != "" {
-		strategies = append(strategies, ExternalDiffStrategy{
+		console = ExternalDiffStrategy{
 			Log:         a.logger,
 			Tool:        a.cfg.ExternalDiffTool,
 			ShowAdded:   a.cfg.PrintAddedManifests,
 			ShowRemoved: a.cfg.PrintRemovedManifests,
-		})
+		}
 	} else {
-		strategies = append(strategies, StdoutStrategy{
+		console = StdoutStrategy{
 			Log:         a.logger,
 			ShowAdded:   a.cfg.PrintAddedManifests,
 			ShowRemoved: a.cfg.PrintRemovedManifests,
-		})
+		}
 	}
 
+	if a.cfg.GitHubActions != nil {
+		console = GitHubActionsStrategy{
+			Inner:           console,
+			Out:             os.Stdout,
+			Fs:              a.fs,
+			StepSummary:     a.cfg.GitHubActions.StepSummary,
+			Template:        a.commentTemplate,
+			FailOnRisk:      a.cfg.FailOnRisk,
+			ShowAdded:       a.cfg.PrintAddedManifests,
+			ShowRemoved:     a.cfg.PrintRemovedManifests,
+			ApplicationPath: applicationFile,
+		}
+	}
+	strategies = append(strategies, console)
+
 	if a.cfg.Comment != nil && a.cfg.Comment.Provider != CommentProviderNone {
 		poster, err := a.commentFactory(a.cfg)
 		if err != nil {
@@ -583,9 +860,30 @@ func (a *App) selectDiffStrategies(applicationFile string) ([]DiffPresenter, err
 		strategies = append(strategies, CommentStrategy{
 			Log:             a.logger,
 			Poster:          poster,
+			Template:        a.commentTemplate,
+			Mode:            a.cfg.Comment.Mode,
+			Revision:        a.revision,
+			FailOnRisk:      a.cfg.FailOnRisk,
+			RandomMaskKey:   a.cfg.MaskingKey == "",
 			ShowAdded:       a.cfg.PrintAddedManifests,
 			ShowRemoved:     a.cfg.PrintRemovedManifests,
 			ApplicationPath: applicationFile,
+			DiffFile:        diffFile,
+		})
+	}
+
+	if a.statusReporter != nil {
+		sha := a.cfg.CommitStatus.SHA
+		if sha == "" {
+			sha = a.revision
+		}
+		strategies = append(strategies, CommitStatusStrategy{
+			Log:             a.logger,
+			Reporter:        a.statusReporter,
+			SHA:             sha,
+			TargetURL:       a.cfg.CommitStatus.TargetURL,
+			FailOnRisk:      a.cfg.FailOnRisk,
+			ApplicationPath: applicationFile,
 		})
 	}
 
@@ -629,6 +927,36 @@ func (a *App) reportInvalidFiles(invalid []string) error {
 	return errors.New("invalid files found")
 }
 
+// newSensitiveDataMasker builds the default masking pipeline from the masking
+// settings in cfg: the Secret masker followed by the rule masker, loaded from
+// cfg.MaskingRulesFile or the built-in rules. Without a configured key the
+// maskers share a random one, which keeps placeholders deterministic for this
+// run only; that is safe but makes masked values incomparable across
+// pipelines, so the fallback is surfaced as a warning.
+func newSensitiveDataMasker(cfg Config, reader ports.FileReader, log *logger.Logger) (ports.SensitiveDataMasker, error) {
+	rules := sanitizer.DefaultMaskingRules()
+	if cfg.MaskingRulesFile != "" {
+		data, err := reader.ReadFile(cfg.MaskingRulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("read masking rules %s: %w", cfg.MaskingRulesFile, err)
+		}
+		if data == nil {
+			return nil, fmt.Errorf("masking rules file %s not found", cfg.MaskingRulesFile)
+		}
+		if rules, err = sanitizer.ParseMaskingRules(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.MaskingRulesFile, err)
+		}
+	}
+
+	if cfg.MaskingKey == "" {
+		log.Warning(ui.Yellow("No masking key configured (ARGO_COMPARE_MASK_KEY or --mask-key-file); using a random per-run key for masked values"))
+	}
+	return sanitizer.NewMasker(rules,
+		sanitizer.WithKey([]byte(cfg.MaskingKey)),
+		sanitizer.WithDigestLength(cfg.MaskDigestLength),
+	)
+}
+
 // defaultCommentPosterFactory returns a poster instance for the configured comment provider.
 // It expects cfg.Comment to be non-nil and already validated by the caller.
 func defaultCommentPosterFactory(cfg Config) (comment.Poster, error) {
@@ -643,6 +971,42 @@ func defaultCommentPosterFactory(cfg Config) (comment.Poster, error) {
 			Token:           cfg.Comment.GitLab.Token,
 			ProjectID:       cfg.Comment.GitLab.ProjectID,
 			MergeRequestIID: cfg.Comment.GitLab.MergeRequestIID,
+			Discussions:     cfg.Comment.GitLab.Discussions == GitLabDiscussionsGeneral || cfg.Comment.GitLab.Discussions == GitLabDiscussionsDiff,
+			PositionOnDiff:  cfg.Comment.GitLab.Discussions == GitLabDiscussionsDiff,
+		})
+	case CommentProviderGitHub:
+		return github.NewPoster(github.Config{
+			BaseURL:     cfg.Comment.GitHub.BaseURL,
+			Token:       cfg.Comment.GitHub.Token,
+			Repository:  cfg.Comment.GitHub.Repository,
+			PullRequest: cfg.Comment.GitHub.PullRequest,
+		})
+	case CommentProviderGitea:
+		return gitea.NewPoster(gitea.Config{
+			BaseURL:     cfg.Comment.Gitea.BaseURL,
+			Token:       cfg.Comment.Gitea.Token,
+			Repository:  cfg.Comment.Gitea.Repository,
+			PullRequest: cfg.Comment.Gitea.PullRequest,
+			CABundle:    cfg.Comment.Gitea.CABundle,
+		})
+	case CommentProviderBitbucket:
+		return bitbucket.NewPoster(bitbucket.Config{
+			BaseURL:     cfg.Comment.Bitbucket.BaseURL,
+			DataCenter:  cfg.Comment.Bitbucket.DataCenter,
+			Workspace:   cfg.Comment.Bitbucket.Workspace,
+			Repository:  cfg.Comment.Bitbucket.Repository,
+			PullRequest: cfg.Comment.Bitbucket.PullRequest,
+			Username:    cfg.Comment.Bitbucket.Username,
+			Token:       cfg.Comment.Bitbucket.Token,
+		})
+	case CommentProviderAzureDevOps:
+		return azuredevops.NewPoster(azuredevops.Config{
+			OrganizationURL:     cfg.Comment.AzureDevOps.OrganizationURL,
+			Project:             cfg.Comment.AzureDevOps.Project,
+			Repository:          cfg.Comment.AzureDevOps.Repository,
+			PullRequest:         cfg.Comment.AzureDevOps.PullRequest,
+			Token:               cfg.Comment.AzureDevOps.Token,
+			PersonalAccessToken: cfg.Comment.AzureDevOps.PersonalAccessToken,
 		})
 	case CommentProviderNone:
 		return nil, fmt.Errorf("comment factory requested with comment provider %q", CommentProviderNone)
@@ -3,6 +3,7 @@ package app
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -18,6 +19,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/shini4i/argo-compare/cmd/argo-compare/utils"
+	"github.com/shini4i/argo-compare/internal/comment"
 	"github.com/shini4i/argo-compare/internal/ports"
 	"github.com/shini4i/argo-compare/internal/ports/portstest"
 	"github.com/spf13/afero"
@@ -138,6 +140,38 @@ func TestAppRunIntegration(t *testing.T) {
 	}
 
 	require.Contains(t, logBuffer.String(), "would be changed")
+
+	// With the diff exit code enabled, the same run reports the differences,
+	// and the JSON report records them.
+	cfg.DiffExitCode = true
+	cfg.ReportJSON = filepath.Join(tempDir, "reports", "run.json")
+	appInstance, err = New(cfg, Dependencies{
+		FS:            afero.NewOsFs(),
+		CmdRunner:     portstest.NoopCmdRunner{},
+		FileReader:    utils.OsFileReader{},
+		HelmProcessor: helmStub,
+		Globber:       utils.CustomGlobber{},
+		Logger:        appLogger,
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, appInstance.Run(context.Background()), ErrDifferencesFound)
+
+	data, err := os.ReadFile(cfg.ReportJSON)
+	require.NoError(t, err)
+	var report RunReport
+	require.NoError(t, json.Unmarshal(data, &report))
+	require.Len(t, report.Applications, 1)
+	application := report.Applications[0]
+	require.Equal(t, "apps/demo.yaml", application.File)
+	require.Equal(t, "demo", application.Name)
+	require.True(t, application.Differences)
+	require.Equal(t, []ChartReport{
+		{Leg: TargetTypeSource, Name: "demo-chart", RepoURL: "fake.repo/charts", TargetRevision: "1.1.0", Version: "1.1.0"},
+		{Leg: TargetTypeDestination, Name: "demo-chart", RepoURL: "fake.repo/charts", TargetRevision: "1.0.0", Version: "1.0.0"},
+	}, application.Charts)
+	require.Len(t, application.Changed, 1)
+	require.Contains(t, application.Changed[0].Diff, "+  version: 1.1.0")
+	require.Equal(t, []ResourceReport{{APIVersion: "v1", Kind: "ConfigMap", Namespace: "demo", Name: "demo"}}, application.Changed[0].Resources)
 }
 
 func writeApplication(t *testing.T, repoDir, version string, replicas int) {
@@ -230,7 +264,11 @@ func (s *stubHelmProcessor) ExtractHelmChart(_ context.Context, _ ports.HelmDeps
 		return err
 	}
 	content := fmt.Sprintf("chartVersion: %s\n", req.ChartVersion)
-	return os.WriteFile(filepath.Join(dir, "values.yaml"), []byte(content), 0o644)
+	if err := os.WriteFile(filepath.Join(dir, "values.yaml"), []byte(content), 0o644); err != nil {
+		return err
+	}
+	chart := fmt.Sprintf("apiVersion: v2\nname: %s\nversion: %s\n", req.ChartName, req.ChartVersion)
+	return os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte(chart), 0o644)
 }
 
 func (s *stubHelmProcessor) RenderAppSource(_ context.Context, _ ports.CmdRunner, req ports.ChartRenderRequest) error {
@@ -358,10 +396,16 @@ func TestAppRunReturnsValidationErrorWhenValidatorFails(t *testing.T) {
 		ManifestValidator: validator,
 	})
 	require.NoError(t, err)
+	// A failing report must not hide the gate failure.
+	reportErr := errors.New("send webhook: 502 Bad Gateway")
+	appInstance.runPresenters = append(appInstance.runPresenters, runPresenterFunc(func(context.Context, RunReport) error {
+		return reportErr
+	}))
 
 	err = appInstance.Run(context.Background())
 	require.Error(t, err, "Run must return an error when validation fails")
 	require.True(t, errors.Is(err, ErrManifestValidationFailed), "error must wrap ErrManifestValidationFailed, got: %v", err)
+	require.ErrorIs(t, err, reportErr)
 
 	// Validator was actually invoked (sanity check the test exercises the path).
 	assert.Equal(t, 1, validator.calls, "validator must be called exactly once per application (src only)")
@@ -447,3 +491,164 @@ func TestAppRunSucceedsWhenValidatorReportsValid(t *testing.T) {
 	require.NoError(t, err, "Run must not return an error when validation passes")
 	assert.Equal(t, 1, validator.calls, "validator must be called exactly once per application (src only)")
 }
+
+func TestAppRunWritesReportsWhenNothingChanged(t *testing.T) {
+	buildGitRepo(t, true)
+	reportDir := t.TempDir()
+
+	cfg := Config{
+		TargetBranch: "main",
+		CacheDir:     t.TempDir(),
+		TempDirBase:  t.TempDir(),
+		Version:      "test",
+		ReportJSON:   filepath.Join(reportDir, "run.json"),
+		// The target branch pipeline writes the Code Quality baseline this way.
+		ReportCodeQuality: filepath.Join(reportDir, "gl-code-quality.json"),
+	}
+	newApp := func(cfg Config) *App {
+		appInstance, err := New(cfg, Dependencies{
+			FS:            afero.NewOsFs(),
+			CmdRunner:     portstest.NoopCmdRunner{},
+			FileReader:    utils.OsFileReader{},
+			HelmProcessor: newStubHelmProcessor(t),
+			Globber:       utils.CustomGlobber{},
+			Logger:        logger.New("app-test-no-changes"),
+		})
+		require.NoError(t, err)
+		return appInstance
+	}
+
+	readReport := func() RunReport {
+		data, err := os.ReadFile(cfg.ReportJSON)
+		require.NoError(t, err)
+		var report RunReport
+		require.NoError(t, json.Unmarshal(data, &report))
+		require.NoError(t, os.Remove(cfg.ReportJSON))
+		return report
+	}
+
+	require.NoError(t, newApp(cfg).Run(context.Background()))
+	report := readReport()
+	assert.Equal(t, "main", report.TargetBranch)
+	assert.NotNil(t, report.Applications)
+	assert.Empty(t, report.Applications)
+
+	codeQuality, err := os.ReadFile(cfg.ReportCodeQuality)
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", string(codeQuality))
+
+	// A --file filtered out by --ignore also ends the run early.
+	cfg.FileToCompare = "apps/demo.yaml"
+	cfg.FilesToIgnore = []string{"apps/demo.yaml"}
+	require.NoError(t, newApp(cfg).Run(context.Background()))
+	assert.Empty(t, readReport().Applications)
+}
+
+func TestAppRunMarksCommentsOfRevertedApplicationsStale(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip integration test in short mode")
+	}
+
+	tempDir := t.TempDir()
+	workDir := filepath.Join(tempDir, "work")
+	repo, err := git.PlainInit(workDir, false)
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main"))))
+
+	commit := func(version string, replicas int, message string) plumbing.Hash {
+		writeApplication(t, workDir, version, replicas)
+		worktree, err := repo.Worktree()
+		require.NoError(t, err)
+		_, err = worktree.Add("apps/demo.yaml")
+		require.NoError(t, err)
+		hash, err := worktree.Commit(message, &git.CommitOptions{Author: defaultSignature()})
+		require.NoError(t, err)
+		return hash
+	}
+
+	initialHash := commit(`1.0.0`, 1, "initial commit")
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName("refs/remotes/origin/main"), initialHash)))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName("feature/revert"),
+		Create: true,
+	}))
+	commit(`1.1.0`, 2, "update chart version")
+
+	oldWD, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(workDir))
+	t.Cleanup(func() { require.NoError(t, os.Chdir(oldWD)) })
+
+	poster := newNotesPoster()
+	run := func() {
+		appInstance, err := New(Config{
+			TargetBranch: "main",
+			CacheDir:     filepath.Join(tempDir, "cache"),
+			TempDirBase:  t.TempDir(),
+			Version:      "test",
+			Comment:      &CommentConfig{Provider: CommentProviderGitLab},
+		}, Dependencies{
+			FS:                   afero.NewOsFs(),
+			CmdRunner:            portstest.NoopCmdRunner{},
+			FileReader:           utils.OsFileReader{},
+			HelmProcessor:        newStubHelmProcessor(t),
+			Globber:              utils.CustomGlobber{},
+			Logger:               logger.New("app-test-reverted"),
+			CommentPosterFactory: func(Config) (comment.Poster, error) { return poster, nil },
+		})
+		require.NoError(t, err)
+		require.NoError(t, appInstance.Run(context.Background()))
+	}
+
+	run()
+	notes, err := findComments(context.Background(), poster, "apps/demo.yaml")
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Contains(t, notes[0].Body, "+  version: 1.1.0")
+
+	// Reverting the file takes the Application out of the comparison.
+	revertHash := commit(`1.0.0`, 1, "revert chart version")
+	run()
+	notes, err = findComments(context.Background(), poster, "apps/demo.yaml")
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.NotContains(t, notes[0].Body, "version: 1.1.0")
+	assert.Contains(t, notes[0].Body, "no longer differs from the target branch as of commit `"+revertHash.String()[:8]+"`")
+}
+
+func TestAppRunWithFileKeepsMergeRequestLabels(t *testing.T) {
+	buildGitRepo(t, true)
+
+	for name, ignore := range map[string][]string{
+		"compared": nil,
+		"ignored":  {"apps/demo.yaml"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			labeler := &fakeLabeler{labels: []string{"app::other", "manifests-changed", "crd-change"}}
+			appInstance, err := New(Config{
+				TargetBranch:  "main",
+				CacheDir:      t.TempDir(),
+				TempDirBase:   t.TempDir(),
+				Version:       "test",
+				FileToCompare: "apps/demo.yaml",
+				FilesToIgnore: ignore,
+				Labels:        &LabelsConfig{},
+			}, Dependencies{
+				FS:                  afero.NewOsFs(),
+				CmdRunner:           portstest.NoopCmdRunner{},
+				FileReader:          utils.OsFileReader{},
+				HelmProcessor:       newStubHelmProcessor(t),
+				Globber:             utils.CustomGlobber{},
+				Logger:              logger.New("app-test-file-labels"),
+				MergeRequestLabeler: labeler,
+			})
+			require.NoError(t, err)
+			require.NoError(t, appInstance.Run(context.Background()))
+
+			assert.Equal(t, 1, labeler.calls)
+			assert.Empty(t, labeler.removed, "a --file run sees one Application and removes nothing")
+		})
+	}
+}
@@ -81,7 +81,7 @@ func TestSelectDiffStrategiesIncludesCommentStrategy(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	strategies, err := appInstance.selectDiffStrategies("apps/foo.yaml")
+	strategies, err := appInstance.selectDiffStrategies("apps/foo.yaml", "apps/foo.yaml")
 	require.NoError(t, err)
 	require.Len(t, strategies, 2)
 
@@ -115,7 +115,7 @@ func TestSelectDiffStrategiesErrorFromFactory(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	_, err = appInstance.selectDiffStrategies("apps/foo.yaml")
+	_, err = appInstance.selectDiffStrategies("apps/foo.yaml", "apps/foo.yaml")
 	require.Error(t, err)
 }
 
@@ -143,7 +143,7 @@ func TestSelectDiffStrategiesNilPosterFromFactory(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	_, err = appInstance.selectDiffStrategies("apps/foo.yaml")
+	_, err = appInstance.selectDiffStrategies("apps/foo.yaml", "apps/foo.yaml")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "comment poster factory returned nil")
 }
@@ -163,7 +163,7 @@ func TestSelectDiffStrategiesWithExternalTool(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	strategies, err := appInstance.selectDiffStrategies("apps/foo.yaml")
+	strategies, err := appInstance.selectDiffStrategies("apps/foo.yaml", "apps/foo.yaml")
 	require.NoError(t, err)
 	require.Len(t, strategies, 1)
 
@@ -171,6 +171,33 @@ func TestSelectDiffStrategiesWithExternalTool(t *testing.T) {
 	assert.True(t, isExternal)
 }
 
+func TestSelectDiffStrategiesWrapsConsoleForGitHubActions(t *testing.T) {
+	cfg, err := NewConfig("main",
+		WithCacheDir("/tmp/cache"),
+		WithFailOnRisk(RiskHigh),
+		WithGitHubActions(GitHubActionsConfig{StepSummary: "/runner/summary.md"}),
+	)
+	require.NoError(t, err)
+
+	appInstance, err := New(cfg, Dependencies{
+		FS:     afero.NewMemMapFs(),
+		Logger: setupTestLogger(t, "app-select-github"),
+	})
+	require.NoError(t, err)
+
+	strategies, err := appInstance.selectDiffStrategies("apps/foo.yaml", "apps/foo.yaml")
+	require.NoError(t, err)
+	require.Len(t, strategies, 1)
+
+	actions, ok := strategies[0].(GitHubActionsStrategy)
+	require.True(t, ok)
+	_, isStdout := actions.Inner.(StdoutStrategy)
+	assert.True(t, isStdout)
+	assert.Equal(t, "/runner/summary.md", actions.StepSummary)
+	assert.Equal(t, RiskHigh, actions.FailOnRisk)
+	assert.Equal(t, "apps/foo.yaml", actions.ApplicationPath)
+}
+
 func TestReportInvalidFilesEmpty(t *testing.T) {
 	cfg, err := NewConfig("main", WithCacheDir("/tmp/cache"))
 	require.NoError(t, err)
@@ -353,6 +380,107 @@ func TestDefaultCommentPosterFactoryGitLab(t *testing.T) {
 	require.NotNil(t, poster)
 }
 
+func TestDefaultCommentPosterFactoryGitLabDiscussions(t *testing.T) {
+	for _, discussions := range []GitLabDiscussions{"", GitLabDiscussionsOff, GitLabDiscussionsGeneral, GitLabDiscussionsDiff} {
+		cfg := Config{
+			Comment: &CommentConfig{
+				Provider: CommentProviderGitLab,
+				GitLab: GitLabCommentConfig{
+					BaseURL:         "https://gitlab.example.com",
+					Token:           "token",
+					ProjectID:       "1",
+					MergeRequestIID: 7,
+					Discussions:     discussions,
+				},
+			},
+		}
+
+		poster, err := defaultCommentPosterFactory(cfg)
+		require.NoError(t, err, discussions)
+		assert.Implements(t, (*comment.FilePoster)(nil), poster)
+	}
+}
+
+func TestDefaultCommentPosterFactoryGitHub(t *testing.T) {
+	cfg := Config{
+		Comment: &CommentConfig{
+			Provider: CommentProviderGitHub,
+			GitHub: GitHubCommentConfig{
+				BaseURL:     "https://github.example.com/api/v3",
+				Token:       "token",
+				Repository:  "octo/deploy",
+				PullRequest: 7,
+			},
+		},
+	}
+
+	poster, err := defaultCommentPosterFactory(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, poster)
+	assert.Equal(t, 65536, commentLengthLimit(poster))
+}
+
+func TestDefaultCommentPosterFactoryGitea(t *testing.T) {
+	cfg := Config{
+		Comment: &CommentConfig{
+			Provider: CommentProviderGitea,
+			Gitea: GiteaCommentConfig{
+				BaseURL:     "https://forgejo.example.com",
+				Token:       "token",
+				Repository:  "platform/gitops",
+				PullRequest: 3,
+			},
+		},
+	}
+
+	poster, err := defaultCommentPosterFactory(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, poster)
+
+	cfg.Comment.Gitea.CABundle = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = defaultCommentPosterFactory(cfg)
+	require.Error(t, err)
+}
+
+func TestDefaultCommentPosterFactoryBitbucket(t *testing.T) {
+	cfg := Config{
+		Comment: &CommentConfig{
+			Provider: CommentProviderBitbucket,
+			Bitbucket: BitbucketCommentConfig{
+				Workspace:   "acme",
+				Repository:  "gitops",
+				PullRequest: 5,
+				Token:       "token",
+			},
+		},
+	}
+
+	poster, err := defaultCommentPosterFactory(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, poster)
+	assert.Equal(t, 32768, commentLengthLimit(poster))
+}
+
+func TestDefaultCommentPosterFactoryAzureDevOps(t *testing.T) {
+	cfg := Config{
+		Comment: &CommentConfig{
+			Provider: CommentProviderAzureDevOps,
+			AzureDevOps: AzureDevOpsCommentConfig{
+				OrganizationURL: "https://dev.azure.com/acme",
+				Project:         "platform",
+				Repository:      "gitops",
+				PullRequest:     77,
+				Token:           "token",
+			},
+		},
+	}
+
+	poster, err := defaultCommentPosterFactory(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, poster)
+	assert.Equal(t, 150000, commentLengthLimit(poster))
+}
+
 func TestNewWithValidationEnabledCreatesValidator(t *testing.T) {
 	cfg, err := NewConfig("main",
 		WithCacheDir("/tmp/cache"),
@@ -462,6 +590,59 @@ func TestNewWithInjectedValidator(t *testing.T) {
 	assert.Same(t, injectedValidator, appInstance.validator)
 }
 
+func TestNewBuildsMaskerFromRulesFile(t *testing.T) {
+	cfg, err := NewConfig("main",
+		WithCacheDir("/tmp/cache"),
+		WithMaskingKey("test-key"),
+		WithMaskingRulesFile("/repo/masking-rules.yaml"),
+	)
+	require.NoError(t, err)
+
+	reader := mapFileReader{files: map[string][]byte{
+		"/repo/masking-rules.yaml": []byte("keys: [\"*_DSN\"]\n"),
+	}}
+
+	appInstance, err := New(cfg, Dependencies{
+		FS:         afero.NewMemMapFs(),
+		FileReader: reader,
+		Logger:     setupTestLogger(t, "app-masking-rules"),
+	})
+	require.NoError(t, err)
+
+	masked, changed, err := appInstance.sensitiveDataMasker.Mask([]byte("kind: ConfigMap\ndata:\n  SENTRY_DSN: https://key@sentry.example.com/1\n  DB_PASSWORD: hunter2\n"))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.NotContains(t, string(masked), "sentry.example.com", "rule from the file should apply")
+	assert.NotContains(t, string(masked), "hunter2", "built-in rules should still apply")
+}
+
+func TestNewRejectsUnusableMaskingRulesFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		reader  mapFileReader
+		wantErr string
+	}{
+		{name: "missing", reader: mapFileReader{}, wantErr: "not found"},
+		{name: "unreadable", reader: mapFileReader{err: errors.New("permission denied")}, wantErr: "permission denied"},
+		{name: "invalid", reader: mapFileReader{files: map[string][]byte{"rules.yaml": []byte("unknown: true\n")}}, wantErr: "rules.yaml"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := NewConfig("main", WithCacheDir("/tmp/cache"), WithMaskingRulesFile("rules.yaml"))
+			require.NoError(t, err)
+
+			_, err = New(cfg, Dependencies{
+				FS:         afero.NewMemMapFs(),
+				FileReader: tc.reader,
+				Logger:     setupTestLogger(t, "app-masking-rules-"+tc.name),
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
 func TestProcessFileRecordsValidatorInvocationError(t *testing.T) {
 	// When the validator itself fails (e.g. binary missing), processFile must
 	// record a synthetic ValidationResult with InvocationError populated so the
@@ -500,7 +681,7 @@ func TestProcessFileRecordsValidatorInvocationError(t *testing.T) {
 	appFile := filepath.Join(t.TempDir(), "test-app.yaml")
 
 	validationResults := make(map[string]ports.ValidationResult)
-	err = appInstance.processFile(context.Background(), nil, appFile, TargetTypeSource, models.Application{}, tmpDir, validationResults)
+	_, err = appInstance.processFile(context.Background(), nil, appFile, TargetTypeSource, models.Application{}, tmpDir, validationResults)
 	require.NoError(t, err, "validator invocation failure should not propagate from processFile")
 
 	require.Contains(t, validationResults, TargetTypeSource)
@@ -555,7 +736,7 @@ func TestProcessFileRecordsInvalidValidationResult(t *testing.T) {
 	appFile := filepath.Join(t.TempDir(), "test-app.yaml")
 
 	validationResults := make(map[string]ports.ValidationResult)
-	err = appInstance.processFile(context.Background(), nil, appFile, TargetTypeSource, models.Application{}, tmpDir, validationResults)
+	_, err = appInstance.processFile(context.Background(), nil, appFile, TargetTypeSource, models.Application{}, tmpDir, validationResults)
 	require.NoError(t, err, "schema-invalid manifests must not cause processFile to fail")
 
 	require.Contains(t, validationResults, TargetTypeSource)
@@ -595,7 +776,7 @@ func TestProcessFileSkipsValidationWhenValidatorNil(t *testing.T) {
 	validationResults := make(map[string]ports.ValidationResult)
 	// Use TargetTypeSource so parse() is exercised and the nil-validator guard is
 	// reached on the same code path that production code takes.
-	err = appInstance.processFile(context.Background(), nil, appFile, TargetTypeSource, models.Application{}, tmpDir, validationResults)
+	_, err = appInstance.processFile(context.Background(), nil, appFile, TargetTypeSource, models.Application{}, tmpDir, validationResults)
 	require.NoError(t, err)
 
 	assert.Empty(t, validationResults, "validationResults must remain empty when no validator is configured")
@@ -655,7 +836,7 @@ func TestProcessFileCallsValidatorForSource(t *testing.T) {
 	appFile := filepath.Join(t.TempDir(), "test-app.yaml")
 
 	validationResults := make(map[string]ports.ValidationResult)
-	err = appInstance.processFile(context.Background(), nil, appFile, TargetTypeSource, models.Application{}, tmpDir, validationResults)
+	_, err = appInstance.processFile(context.Background(), nil, appFile, TargetTypeSource, models.Application{}, tmpDir, validationResults)
 	require.NoError(t, err)
 
 	require.Contains(t, validationResults, TargetTypeSource)
@@ -701,8 +882,17 @@ func TestProcessFileSkipsValidationForDestination(t *testing.T) {
 	testApp.Spec.Destination = &models.Destination{Server: "https://kubernetes.default.svc", Namespace: "default"}
 
 	validationResults := make(map[string]ports.ValidationResult)
-	err = appInstance.processFile(context.Background(), nil, "apps/test.yaml", TargetTypeDestination, testApp, tmpDir, validationResults)
+	_, err = appInstance.processFile(context.Background(), nil, "apps/test.yaml", TargetTypeDestination, testApp, tmpDir, validationResults)
 	require.NoError(t, err)
 
 	assert.Empty(t, validationResults, "validation must be skipped for destination manifests")
 }
+
+func TestOutcomeOfRecordsDifferences(t *testing.T) {
+	a := &App{}
+	assert.False(t, a.outcomeOf(ComparisonResult{}).differencesFound)
+	assert.True(t, a.outcomeOf(ComparisonResult{Changed: []DiffOutput{{File: File{Name: "/cm.yaml"}}}}).differencesFound)
+
+	outcome := comparisonOutcome{differencesFound: true}
+	assert.NoError(t, outcome.err(), "differences alone are not a gate failure")
+}
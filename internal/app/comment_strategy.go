@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/shini4i/argo-compare/cmd/argo-compare/utils/logger"
 
@@ -17,14 +18,22 @@ import (
 type CommentStrategy struct {
 	Log             *logger.Logger
 	Poster          comment.Poster
+	Template        *template.Template // Renders the comments instead of the built-in layout when set.
+	Mode            CommentMode        // How comments of earlier runs are treated; empty means CommentModeUpdate.
+	Revision        string             // Commit the results belong to; named when a diff comment goes stale.
+	FailOnRisk      RiskSeverity       // Findings at or above it make an edited comment need review again.
+	RandomMaskKey   bool               // Masked values get new digests every run; they are ignored when comparing with earlier comments.
 	ShowAdded       bool
 	ShowRemoved     bool
 	ApplicationPath string
+	DiffFile        string // Changed file new comments are attached to by posters implementing comment.FilePoster.
 }
 
 const (
-	// gitlabNoteLengthLimit reflects GitLab's documented 1 MB limit for note bodies.
-	gitlabNoteLengthLimit = 1_000_000
+	// defaultCommentLengthLimit applies to posters that do not implement
+	// comment.BodyLimiter and to the GitHub job summary. It matches GitLab's
+	// documented 1 MB limit for note bodies.
+	defaultCommentLengthLimit = 1_000_000
 	// commentPartReserve keeps room for part numbering suffixes when chunking comments.
 	commentPartReserve = 32
 	crdNoticeTemplate  = "> CRD manifest `%s` detected in the %s section. Diff omitted to keep merge request comments concise. Review the job logs for full details.\n"
@@ -37,15 +46,119 @@ func (s CommentStrategy) Present(ctx context.Context, result ComparisonResult) e
 		return err
 	}
 
-	bodies := buildCommentBodies(result, s.ShowAdded, s.ShowRemoved, s.ApplicationPath)
-	if err := s.postBodies(ctx, bodies); err != nil {
+	upserter := s.upserter()
+	limit := commentLengthLimit(s.Poster)
+	if upserter != nil {
+		limit -= commentMarkerReserve(s.ApplicationPath)
+	}
+
+	bodies, err := commentBodies(s.Template, result, s.ShowAdded, s.ShowRemoved, s.ApplicationPath, limit)
+	if err != nil {
 		return err
 	}
+	bodies = numberCommentParts(bodies)
+
+	if upserter == nil {
+		if err := s.postBodies(ctx, bodies); err != nil {
+			return err
+		}
+		s.logResult(result, len(bodies))
+		return nil
+	}
 
-	s.logResult(result, len(bodies))
+	existing, err := findComments(ctx, upserter, s.ApplicationPath)
+	if err != nil {
+		return err
+	}
+	bodies = s.markBodies(result, existing, bodies)
+
+	// A resolved comment is reopened only when the new result needs a
+	// reviewer, not when a diff merely went away.
+	activity, err := syncComments(ctx, s.poster(), upserter, s.ApplicationPath, existing, bodies, syncOptions{
+		Reopen:            s.needsReview(result),
+		IgnoreMaskDigests: s.RandomMaskKey,
+	})
+	if err != nil {
+		return err
+	}
+	s.logActivity(activity)
 	return nil
 }
 
+// markBodies appends the markers to the rendered bodies. When the
+// Application no longer differs but an earlier comment still shows a diff,
+// the diff is replaced by a short stale notice instead, which later runs
+// keep as long as there is nothing new to report.
+func (s CommentStrategy) markBodies(result ComparisonResult, existing []comment.Note, bodies []string) []string {
+	if !s.needsReview(result) {
+		if showsDiff(existing, s.ApplicationPath) {
+			return []string{markComment(staleCommentBody(s.ApplicationPath, s.Revision), s.ApplicationPath, 1, commentStateStale)}
+		}
+		if stale, ok := staleComment(existing, s.ApplicationPath); ok {
+			return []string{stale.Body}
+		}
+	}
+
+	state := ""
+	if result.IsEmpty() {
+		state = commentStateEmpty
+	}
+	marked := make([]string, len(bodies))
+	for idx, body := range bodies {
+		marked[idx] = markComment(body, s.ApplicationPath, idx+1, state)
+	}
+	return marked
+}
+
+// needsReview reports whether result shows a diff or fails a gate, as opposed
+// to confirming that nothing is left to look at.
+func (s CommentStrategy) needsReview(result ComparisonResult) bool {
+	return !result.IsEmpty() || resultOutcome(result, s.FailOnRisk).err() != nil
+}
+
+// upserter returns the poster's update capability when comments are to be
+// updated in place, and nil when they are appended.
+func (s CommentStrategy) upserter() comment.Upserter {
+	if s.Mode == CommentModeAppend {
+		return nil
+	}
+	upserter, ok := s.Poster.(comment.Upserter)
+	if !ok {
+		if s.Mode == CommentModeUpdate {
+			s.Log.Debugf("Comment provider cannot update comments in place; appending new comments")
+		}
+		return nil
+	}
+	return upserter
+}
+
+// poster returns the Poster new comments are published with, attaching them
+// to DiffFile when the poster supports it.
+func (s CommentStrategy) poster() comment.Poster {
+	if filePoster, ok := s.Poster.(comment.FilePoster); ok && s.DiffFile != "" {
+		return onFilePoster{poster: filePoster, path: s.DiffFile}
+	}
+	return s.Poster
+}
+
+// onFilePoster posts every comment attached to one file.
+type onFilePoster struct {
+	poster comment.FilePoster
+	path   string
+}
+
+func (p onFilePoster) Post(ctx context.Context, body string) error {
+	return p.poster.PostOnFile(ctx, body, p.path)
+}
+
+// commentLengthLimit returns the largest comment body poster accepts.
+func commentLengthLimit(poster comment.Poster) int {
+	if limiter, ok := poster.(comment.BodyLimiter); ok && limiter.MaxBodyLength() > 0 {
+		return limiter.MaxBodyLength()
+	}
+	return defaultCommentLengthLimit
+}
+
 func (s CommentStrategy) validate() error {
 	if s.Poster == nil {
 		return errors.New("comment strategy requires a poster implementation")
@@ -56,27 +169,29 @@ func (s CommentStrategy) validate() error {
 	return nil
 }
 
+// numberCommentParts labels every body of a multi-part result with its part number.
+func numberCommentParts(bodies []string) []string {
+	if len(bodies) < 2 {
+		return bodies
+	}
+	numbered := make([]string, len(bodies))
+	for idx, body := range bodies {
+		numbered[idx] = ensureTrailingNewline(strings.TrimRight(body, "\n") + fmt.Sprintf("\n\n_Part %d of %d_", idx+1, len(bodies)))
+	}
+	return numbered
+}
+
 func (s CommentStrategy) postBodies(ctx context.Context, bodies []string) error {
 	for idx, body := range bodies {
-		bodyToPost := body
-		if len(bodies) > 1 {
-			bodyToPost = ensureTrailingNewline(strings.TrimRight(body, "\n") + fmt.Sprintf("\n\n_Part %d of %d_", idx+1, len(bodies)))
-		}
-		if err := s.Poster.Post(ctx, bodyToPost); err != nil {
-			if len(bodies) > 1 {
-				return fmt.Errorf("post diff comment (part %d/%d): %w", idx+1, len(bodies), err)
-			}
-			return fmt.Errorf("post diff comment: %w", err)
+		if err := s.poster().Post(ctx, body); err != nil {
+			return commentPartError("post diff comment", idx, len(bodies), err)
 		}
 	}
 	return nil
 }
 
 func (s CommentStrategy) logResult(result ComparisonResult, commentCount int) {
-	app := strings.TrimSpace(s.ApplicationPath)
-	if app == "" {
-		app = "unknown application"
-	}
+	app := s.applicationLabel()
 
 	switch {
 	case result.IsEmpty():
@@ -88,7 +203,19 @@ func (s CommentStrategy) logResult(result ComparisonResult, commentCount int) {
 	}
 }
 
-func buildCommentBodies(result ComparisonResult, showAdded, showRemoved bool, applicationPath string) []string {
+func (s CommentStrategy) logActivity(activity commentActivity) {
+	s.Log.Infof("Synced diff comments for %s: %d posted, %d updated, %d unchanged, %d deleted",
+		s.applicationLabel(), activity.Posted, activity.Updated, activity.Unchanged, activity.Deleted)
+}
+
+func (s CommentStrategy) applicationLabel() string {
+	if app := strings.TrimSpace(s.ApplicationPath); app != "" {
+		return app
+	}
+	return "unknown application"
+}
+
+func buildCommentBodies(result ComparisonResult, showAdded, showRemoved bool, applicationPath string, limit int) []string {
 	appLabel := strings.TrimSpace(applicationPath)
 	if appLabel == "" {
 		appLabel = "unknown"
@@ -98,10 +225,14 @@ func buildCommentBodies(result ComparisonResult, showAdded, showRemoved bool, ap
 	var headerBuilder strings.Builder
 	headerBuilder.WriteString("## Argo Compare Results\n\n")
 	headerBuilder.WriteString(fmt.Sprintf("**Application:** `%s`\n\n", appDisplay))
+	headerBuilder.WriteString(buildImageSummary(result.Images))
+	headerBuilder.WriteString(buildRiskSummary(result.Findings))
 
 	if validationSummary := buildValidationSummary(result.ValidationResults); validationSummary != "" {
 		headerBuilder.WriteString(validationSummary)
 	}
+	headerBuilder.WriteString(buildPolicySummary(result.PolicyViolations))
+	headerBuilder.WriteString(buildDeprecationSummary(result.Deprecations))
 
 	if summary := buildSummaryLines(result, showAdded, showRemoved); summary != "" {
 		headerBuilder.WriteString(summary)
@@ -112,7 +243,7 @@ func buildCommentBodies(result ComparisonResult, showAdded, showRemoved bool, ap
 		return []string{ensureTrailingNewline(header + "No manifest differences detected :white_check_mark:\n")}
 	}
 
-	maxPerComment := computeMaxPerComment(len(header))
+	maxPerComment := computeMaxPerComment(len(header), limit)
 
 	maxChunkLen := maxPerComment - len(header)
 	if maxChunkLen <= 0 {
@@ -133,7 +264,7 @@ func buildCommentBodies(result ComparisonResult, showAdded, showRemoved bool, ap
 		chunks = append(chunks, noticeBuilder.String())
 	}
 
-	return assembleCommentBodies(header, chunks)
+	return assembleCommentBodies(header, chunks, limit)
 }
 
 // escapeInlineMarkdown sanitizes a string for safe interpolation into Markdown.
@@ -151,6 +282,107 @@ func escapeInlineMarkdown(s string) string {
 	return s
 }
 
+// riskBadges prefixes each finding with an emoji so severities stand out in the rendered comment.
+var riskBadges = map[RiskSeverity]string{
+	RiskCritical: ":rotating_light:",
+	RiskHigh:     ":red_circle:",
+	RiskMedium:   ":warning:",
+	RiskLow:      ":information_source:",
+}
+
+// buildRiskSummary lists risk findings, most severe first. It returns an empty
+// string when there are none.
+func buildRiskSummary(findings []RiskFinding) string {
+	if len(findings) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**Risk Findings**\n")
+	for _, finding := range findings {
+		fmt.Fprintf(&b, "- %s **%s** `%s` — %s (`%s`)\n",
+			riskBadges[finding.Severity], strings.ToUpper(finding.Severity.String()),
+			escapeInlineMarkdown(finding.Resource), escapeInlineMarkdown(finding.Message), finding.Rule)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// buildImageSummary renders changed container images as a Markdown table.
+// It returns an empty string when no image changed.
+func buildImageSummary(images []ImageChange) string {
+	if len(images) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**Image Changes**\n\n")
+	b.WriteString("| Workload | Container | Image | Old | New |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, change := range images {
+		image, oldVersion, newVersion := describeImageChange(change)
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			tableCode(change.Workload), tableCode(change.containerLabel()), tableCode(image),
+			tableCode(oldVersion), tableCode(newVersion))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// tableCode renders a value as inline code inside a Markdown table cell,
+// escaping pipes so they do not split the cell.
+func tableCode(s string) string {
+	return "`" + strings.ReplaceAll(escapeInlineMarkdown(s), "|", "\\|") + "`"
+}
+
+// buildPolicySummary lists policy violations. It returns an empty string when
+// there are none.
+func buildPolicySummary(violations []PolicyViolation) string {
+	if len(violations) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**Policy Violations**\n")
+	for _, violation := range violations {
+		fmt.Fprintf(&b, "- :no_entry: `%s` `%s` — %s\n",
+			escapeInlineMarkdown(violation.Policy), escapeInlineMarkdown(violation.Resource), escapeInlineMarkdown(violation.Message))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// buildDeprecationSummary renders deprecated API usage as a Markdown table,
+// flagging removed APIs and resources the change introduced on them. It
+// returns an empty string when there is none.
+func buildDeprecationSummary(deprecated []DeprecatedAPI) string {
+	if len(deprecated) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("**Deprecated APIs**\n\n")
+	b.WriteString("| | Resource | API version | Status | Replacement |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, api := range deprecated {
+		badge := ":warning:"
+		if api.Removed {
+			badge = ":no_entry:"
+		}
+		resource := tableCode(api.Resource)
+		if api.Introduced {
+			resource += " **new**"
+		}
+		replacement := "none"
+		if api.Replacement != "" {
+			replacement = tableCode(api.Replacement)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", badge, resource, tableCode(api.APIVersion), api.status(), replacement)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
 // buildValidationSummary formats validation results for a GitLab comment in a stable order.
 // Each failing resource renders as a parent bullet (with cleaned filename when available)
 // followed by one nested sub-bullet per non-empty line of the kubeconform message — keeping
@@ -379,8 +611,8 @@ func splitDiffContent(content string, limit int) (string, string) {
 	return chunk, strings.TrimPrefix(remaining, "\n")
 }
 
-func assembleCommentBodies(header string, chunks []string) []string {
-	maxPerComment := computeMaxPerComment(len(header))
+func assembleCommentBodies(header string, chunks []string, limit int) []string {
+	maxPerComment := computeMaxPerComment(len(header), limit)
 
 	var bodies []string
 	var builder strings.Builder
@@ -425,10 +657,10 @@ func ensureTrailingNewline(body string) string {
 	return body
 }
 
-func computeMaxPerComment(headerLen int) int {
-	maxPerComment := gitlabNoteLengthLimit - commentPartReserve
+func computeMaxPerComment(headerLen, limit int) int {
+	maxPerComment := limit - commentPartReserve
 	if maxPerComment <= 0 {
-		maxPerComment = gitlabNoteLengthLimit
+		maxPerComment = limit
 	}
 	if headerLen >= maxPerComment {
 		maxPerComment = headerLen + 1
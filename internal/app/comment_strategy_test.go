@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/shini4i/argo-compare/cmd/argo-compare/utils/logger"
+	"github.com/shini4i/argo-compare/internal/deprecation"
 	"github.com/shini4i/argo-compare/internal/ports"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -134,6 +135,33 @@ func TestCommentStrategySplitsLargeBody(t *testing.T) {
 	assert.Contains(t, poster.bodies[len(poster.bodies)-1], "Part "+fmt.Sprint(len(poster.bodies))+" of "+fmt.Sprint(len(poster.bodies)))
 }
 
+type limitedPoster struct {
+	stubPoster
+	limit int
+}
+
+func (p *limitedPoster) MaxBodyLength() int { return p.limit }
+
+func TestCommentStrategyHonoursPosterLimit(t *testing.T) {
+	poster := &limitedPoster{limit: 65536}
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-limit", t),
+		Poster:          poster,
+		ShowAdded:       true,
+		ApplicationPath: "apps/big.yaml",
+	}
+
+	result := ComparisonResult{
+		Added: []DiffOutput{{File: File{Name: "big.yaml"}, Diff: strings.Repeat("+ oversized line\n", 10000)}},
+	}
+
+	require.NoError(t, strategy.Present(context.Background(), result))
+	assert.Greater(t, len(poster.bodies), 1)
+	for _, body := range poster.bodies {
+		assert.LessOrEqual(t, len(body), poster.limit)
+	}
+}
+
 func TestCommentStrategyNotesHiddenSections(t *testing.T) {
 	poster := &stubPoster{}
 	logger := setupSilentLogger("comment-hidden", t)
@@ -552,3 +580,87 @@ func TestCommentStrategyIncludesValidationResults(t *testing.T) {
 	assert.Contains(t, body, "**Validation**")
 	assert.Contains(t, body, "3/3 valid")
 }
+
+func TestCommentStrategyIncludesImageSummary(t *testing.T) {
+	poster := &stubPoster{}
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-images", t),
+		Poster:          poster,
+		ApplicationPath: "apps/web.yaml",
+	}
+
+	result := ComparisonResult{
+		Changed: []DiffOutput{{File: File{Name: "/deploy.yaml"}, Diff: "@@ diff\n- old\n+ new"}},
+		Images: []ImageChange{
+			{Workload: "Deployment/web", Container: "app", Old: "nginx:1.25", New: "nginx:1.26"},
+			{Workload: "Deployment/web", Container: "a|b", Old: "docker.io/x:1", New: "ghcr.io/x:1"},
+		},
+	}
+
+	require.NoError(t, strategy.Present(context.Background(), result))
+	require.Len(t, poster.bodies, 1)
+	body := poster.bodies[0]
+
+	assert.Contains(t, body, "**Image Changes**")
+	assert.Contains(t, body, "| `Deployment/web` | `app` | `nginx` | `1.25` | `1.26` |")
+	assert.Contains(t, body, "| `Deployment/web` | `a\\|b` | `ghcr.io/x` | `docker.io/x:1` | `ghcr.io/x:1` |")
+	assert.Less(t, strings.Index(body, "**Image Changes**"), strings.Index(body, "**Summary**"))
+}
+
+func TestBuildImageSummaryEmpty(t *testing.T) {
+	assert.Empty(t, buildImageSummary(nil))
+}
+
+func TestCommentStrategyIncludesRiskSummary(t *testing.T) {
+	poster := &stubPoster{}
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-risks", t),
+		Poster:          poster,
+		ApplicationPath: "apps/web.yaml",
+	}
+
+	result := ComparisonResult{
+		Changed: []DiffOutput{{File: File{Name: "/deploy.yaml"}, Diff: "@@ diff\n- old\n+ new"}},
+		Findings: []RiskFinding{
+			{Severity: RiskHigh, Rule: "scale-to-zero", Resource: "Deployment/web", Message: "replicas scaled from 3 to 0"},
+		},
+	}
+
+	require.NoError(t, strategy.Present(context.Background(), result))
+	require.Len(t, poster.bodies, 1)
+	body := poster.bodies[0]
+
+	assert.Contains(t, body, "**Risk Findings**")
+	assert.Contains(t, body, "**HIGH** `Deployment/web` — replicas scaled from 3 to 0 (`scale-to-zero`)")
+	assert.Less(t, strings.Index(body, "**Risk Findings**"), strings.Index(body, "**Summary**"))
+}
+
+func TestBuildPolicySummary(t *testing.T) {
+	assert.Empty(t, buildPolicySummary(nil))
+
+	summary := buildPolicySummary([]PolicyViolation{
+		{Policy: "no-latest-tags", Resource: "Deployment/web", Message: "Images must be pinned to a `version`"},
+	})
+	assert.Equal(t, "**Policy Violations**\n- :no_entry: `no-latest-tags` `Deployment/web` — Images must be pinned to a \\`version\\`\n\n", summary)
+}
+
+func TestBuildDeprecationSummary(t *testing.T) {
+	assert.Empty(t, buildDeprecationSummary(nil))
+
+	summary := buildDeprecationSummary([]DeprecatedAPI{
+		{
+			Resource: "CronJob/cleanup", APIVersion: "batch/v1beta1", Replacement: "batch/v1",
+			DeprecatedIn: deprecation.Version{Major: 1, Minor: 21}, RemovedIn: deprecation.Version{Major: 1, Minor: 25},
+			Removed: true, Introduced: true,
+		},
+		{
+			Resource: "PodSecurityPolicy/restricted", APIVersion: "policy/v1beta1",
+			DeprecatedIn: deprecation.Version{Major: 1, Minor: 21}, RemovedIn: deprecation.Version{Major: 1, Minor: 25},
+		},
+	})
+	assert.Equal(t, "**Deprecated APIs**\n\n"+
+		"| | Resource | API version | Status | Replacement |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| :no_entry: | `CronJob/cleanup` **new** | `batch/v1beta1` | removed in 1.25 | `batch/v1` |\n"+
+		"| :warning: | `PodSecurityPolicy/restricted` | `policy/v1beta1` | deprecated in 1.21, removed in 1.25 | none |\n\n", summary)
+}
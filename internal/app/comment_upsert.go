@@ -0,0 +1,280 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shini4i/argo-compare/internal/comment"
+	"github.com/shini4i/argo-compare/internal/sanitizer"
+)
+
+// commentMarkerPrefix starts the hidden marker that identifies the comments
+// published for an Application, so later runs can find and update them.
+const commentMarkerPrefix = "<!-- argo-compare:application="
+
+// maxMarkedCommentParts bounds the part number the size of a marker is
+// reserved for.
+const maxMarkedCommentParts = 9999
+
+// Comment states recorded in the marker next to the part number. Comments
+// showing a diff carry no state.
+const (
+	commentStateEmpty = "empty" // The result had no differences.
+	commentStateStale = "stale" // A diff was replaced because the Application no longer differs.
+)
+
+// markerSuffixPattern matches what follows the Application in a marker.
+var markerSuffixPattern = regexp.MustCompile(`^part=(\d+)(?: state=(\w+))? -->`)
+
+// applicationMarker returns the part of the marker shared by every comment of
+// the Application. The path is escaped so it cannot end the HTML comment, and
+// followed by a space so one path never matches a longer one.
+func applicationMarker(applicationPath string) string {
+	return commentMarkerPrefix + url.QueryEscape(applicationPath) + " "
+}
+
+// commentMarker returns the marker of one comment part; parts count from 1.
+func commentMarker(applicationPath string, part int, state string) string {
+	if state == "" {
+		return fmt.Sprintf("%spart=%d -->", applicationMarker(applicationPath), part)
+	}
+	return fmt.Sprintf("%spart=%d state=%s -->", applicationMarker(applicationPath), part, state)
+}
+
+// commentMarkerReserve is the room a marker takes up in a comment body.
+func commentMarkerReserve(applicationPath string) int {
+	return len("\n\n") + len(commentMarker(applicationPath, maxMarkedCommentParts, commentStateStale))
+}
+
+// markComment appends the marker of the given part and state to body.
+func markComment(body, applicationPath string, part int, state string) string {
+	return ensureTrailingNewline(strings.TrimRight(body, "\n") + "\n\n" + commentMarker(applicationPath, part, state))
+}
+
+// parseCommentMarker returns the part number and state recorded in body by
+// the Application's marker. The part is 0 when body carries no valid marker.
+func parseCommentMarker(body, applicationPath string) (int, string) {
+	marker := applicationMarker(applicationPath)
+	idx := strings.Index(body, marker)
+	if idx < 0 {
+		return 0, ""
+	}
+	match := markerSuffixPattern.FindStringSubmatch(body[idx+len(marker):])
+	if match == nil {
+		return 0, ""
+	}
+	part, err := strconv.Atoi(match[1])
+	if err != nil || part < 1 {
+		return 0, ""
+	}
+	return part, match[2]
+}
+
+// staleCommentBody replaces the diff of an Application that no longer
+// differs as of revision.
+func staleCommentBody(applicationPath, revision string) string {
+	asOf := "the latest run"
+	if revision != "" {
+		asOf = "commit `" + shortRevision(revision) + "`"
+	}
+	return fmt.Sprintf("## Argo Compare Results\n\n**Application:** `%s`\n\n"+
+		"This Application no longer differs from the target branch as of %s. The previously reported diff has been removed.\n",
+		escapeInlineMarkdown(applicationPath), asOf)
+}
+
+// shortRevision abbreviates a commit hash the way git does by default.
+func shortRevision(revision string) string {
+	if len(revision) > 8 {
+		return revision[:8]
+	}
+	return revision
+}
+
+// commentActivity counts what syncing an Application's comments did.
+type commentActivity struct {
+	Posted    int
+	Updated   int
+	Unchanged int
+	Deleted   int
+}
+
+// findComments returns the comments earlier runs published for the Application.
+func findComments(ctx context.Context, upserter comment.Upserter, applicationPath string) ([]comment.Note, error) {
+	existing, err := upserter.Find(ctx, applicationMarker(applicationPath))
+	if err != nil {
+		return nil, fmt.Errorf("find previous diff comments: %w", err)
+	}
+	return existing, nil
+}
+
+// markedApplication returns the Application named by the marker in body.
+func markedApplication(body string) (string, bool) {
+	idx := strings.Index(body, commentMarkerPrefix)
+	if idx < 0 {
+		return "", false
+	}
+	escaped, _, found := strings.Cut(body[idx+len(commentMarkerPrefix):], " ")
+	if !found {
+		return "", false
+	}
+	applicationPath, err := url.QueryUnescape(escaped)
+	if err != nil || applicationPath == "" {
+		return "", false
+	}
+	return applicationPath, true
+}
+
+// markUncomparedStale replaces the diff comments of every Application that
+// was not compared with the stale notice, the way CommentStrategy does for
+// compared Applications that no longer differ. An Application drops out of
+// the comparison when a later push reverts its file, and its comments would
+// otherwise keep showing the outdated diff. It returns the Applications whose
+// comments were marked stale.
+func markUncomparedStale(ctx context.Context, poster comment.Poster, upserter comment.Upserter, compared []string, revision string) ([]string, error) {
+	notes, err := upserter.Find(ctx, commentMarkerPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("find previous diff comments: %w", err)
+	}
+
+	skip := make(map[string]bool, len(compared))
+	for _, applicationPath := range compared {
+		skip[applicationPath] = true
+	}
+	var applications []string
+	byApplication := map[string][]comment.Note{}
+	for _, note := range notes {
+		applicationPath, ok := markedApplication(note.Body)
+		if !ok || skip[applicationPath] {
+			continue
+		}
+		if _, seen := byApplication[applicationPath]; !seen {
+			applications = append(applications, applicationPath)
+		}
+		byApplication[applicationPath] = append(byApplication[applicationPath], note)
+	}
+
+	var marked []string
+	for _, applicationPath := range applications {
+		existing := byApplication[applicationPath]
+		if !showsDiff(existing, applicationPath) {
+			continue
+		}
+		body := markComment(staleCommentBody(applicationPath, revision), applicationPath, 1, commentStateStale)
+		if _, err := syncComments(ctx, poster, upserter, applicationPath, existing, []string{body}, syncOptions{}); err != nil {
+			return marked, fmt.Errorf("mark diff comments of %s stale: %w", applicationPath, err)
+		}
+		marked = append(marked, applicationPath)
+	}
+	return marked, nil
+}
+
+// showsDiff reports whether any of the Application's comments shows a diff,
+// as opposed to an empty or stale result.
+func showsDiff(notes []comment.Note, applicationPath string) bool {
+	for _, note := range notes {
+		if part, state := parseCommentMarker(note.Body, applicationPath); part > 0 && state == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// staleComment returns the Application's comment that was already marked
+// stale by an earlier run.
+func staleComment(notes []comment.Note, applicationPath string) (comment.Note, bool) {
+	for _, note := range notes {
+		if part, state := parseCommentMarker(note.Body, applicationPath); part == 1 && state == commentStateStale {
+			return note, true
+		}
+	}
+	return comment.Note{}, false
+}
+
+// syncOptions tunes how syncComments treats existing comments.
+type syncOptions struct {
+	// Reopen reopens edited comments that a reviewer resolved, if the
+	// upserter supports it.
+	Reopen bool
+	// IgnoreMaskDigests compares comments without the digests of masked
+	// values, which differ on every run when the masking key is random.
+	IgnoreMaskDigests bool
+}
+
+// syncComments makes the Application's existing comments match bodies:
+// comments whose part is still needed are edited when their content differs
+// and otherwise left alone, missing parts are posted, and parts the result no
+// longer needs (or duplicates of a part) are deleted.
+func syncComments(ctx context.Context, poster comment.Poster, upserter comment.Upserter, applicationPath string, existing []comment.Note, bodies []string, opts syncOptions) (commentActivity, error) {
+	reopener, _ := upserter.(comment.Reopener)
+	if !opts.Reopen {
+		reopener = nil
+	}
+
+	var activity commentActivity
+
+	byPart := make(map[int]comment.Note, len(existing))
+	var surplus []comment.Note
+	for _, note := range existing {
+		part, _ := parseCommentMarker(note.Body, applicationPath)
+		if _, duplicate := byPart[part]; duplicate || part < 1 || part > len(bodies) {
+			surplus = append(surplus, note)
+			continue
+		}
+		byPart[part] = note
+	}
+
+	for idx, body := range bodies {
+		note, found := byPart[idx+1]
+		switch {
+		case !found:
+			if err := poster.Post(ctx, body); err != nil {
+				return activity, commentPartError("post diff comment", idx, len(bodies), err)
+			}
+			activity.Posted++
+		case normalizeCommentBody(note.Body, opts.IgnoreMaskDigests) == normalizeCommentBody(body, opts.IgnoreMaskDigests):
+			activity.Unchanged++
+		default:
+			if err := upserter.Update(ctx, note.ID, body); err != nil {
+				return activity, commentPartError("update diff comment", idx, len(bodies), err)
+			}
+			if reopener != nil {
+				if err := reopener.Reopen(ctx, note.ID); err != nil {
+					return activity, commentPartError("reopen diff comment", idx, len(bodies), err)
+				}
+			}
+			activity.Updated++
+		}
+	}
+
+	for _, note := range surplus {
+		if err := upserter.Delete(ctx, note.ID); err != nil {
+			return activity, fmt.Errorf("delete outdated diff comment %d: %w", note.ID, err)
+		}
+		activity.Deleted++
+	}
+	return activity, nil
+}
+
+// normalizeCommentBody ignores the line ending and trailing whitespace
+// changes upstream systems apply when storing a comment and, with
+// ignoreMaskDigests, the digests of masked values.
+func normalizeCommentBody(body string, ignoreMaskDigests bool) string {
+	body = strings.TrimSpace(strings.ReplaceAll(body, "\r\n", "\n"))
+	if ignoreMaskDigests {
+		body = sanitizer.StripPlaceholderDigests(body)
+	}
+	return body
+}
+
+// commentPartError wraps err with the action and, for multi-part results, the
+// part it failed on.
+func commentPartError(action string, idx, total int, err error) error {
+	if total > 1 {
+		return fmt.Errorf("%s (part %d/%d): %w", action, idx+1, total, err)
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
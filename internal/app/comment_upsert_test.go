@@ -0,0 +1,388 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/shini4i/argo-compare/internal/comment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notesPoster is an in-memory comment thread supporting updates in place.
+type notesPoster struct {
+	notes   map[int64]string
+	nextID  int64
+	limit   int
+	calls   []string
+	findErr error
+}
+
+func newNotesPoster() *notesPoster {
+	return &notesPoster{notes: map[int64]string{}, nextID: 1}
+}
+
+func (p *notesPoster) Post(_ context.Context, body string) error {
+	p.calls = append(p.calls, "post")
+	p.notes[p.nextID] = body
+	p.nextID++
+	return nil
+}
+
+func (p *notesPoster) Find(_ context.Context, marker string) ([]comment.Note, error) {
+	if p.findErr != nil {
+		return nil, p.findErr
+	}
+	var found []comment.Note
+	for id, body := range p.notes {
+		if strings.Contains(body, marker) {
+			found = append(found, comment.Note{ID: id, Body: body})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found, nil
+}
+
+func (p *notesPoster) Update(_ context.Context, id int64, body string) error {
+	p.calls = append(p.calls, "update")
+	p.notes[id] = body
+	return nil
+}
+
+func (p *notesPoster) Delete(_ context.Context, id int64) error {
+	p.calls = append(p.calls, "delete")
+	delete(p.notes, id)
+	return nil
+}
+
+func (p *notesPoster) MaxBodyLength() int {
+	if p.limit > 0 {
+		return p.limit
+	}
+	return defaultCommentLengthLimit
+}
+
+func changedResult(lines int) ComparisonResult {
+	return ComparisonResult{
+		Changed: []DiffOutput{{File: File{Name: "deploy.yaml"}, Diff: strings.Repeat("+ replicas: 3\n", lines)}},
+	}
+}
+
+func TestCommentStrategyUpdatesCommentsInPlace(t *testing.T) {
+	poster := newNotesPoster()
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-upsert", t),
+		Poster:          poster,
+		ApplicationPath: "apps/web.yaml",
+	}
+	ctx := context.Background()
+
+	require.NoError(t, strategy.Present(ctx, changedResult(1)))
+	require.Len(t, poster.notes, 1)
+	assert.Contains(t, poster.notes[1], "+ replicas: 3")
+	assert.True(t, strings.HasSuffix(poster.notes[1], commentMarker("apps/web.yaml", 1, "")+"\n"))
+
+	poster.calls = nil
+	require.NoError(t, strategy.Present(ctx, changedResult(1)))
+	assert.Empty(t, poster.calls, "an unchanged result leaves the comment alone")
+
+	require.NoError(t, strategy.Present(ctx, changedResult(2)))
+	assert.Equal(t, []string{"update"}, poster.calls)
+	require.Len(t, poster.notes, 1)
+	assert.Contains(t, poster.notes[1], "+ replicas: 3\n+ replicas: 3")
+}
+
+func TestCommentStrategyDeletesSurplusParts(t *testing.T) {
+	poster := newNotesPoster()
+	poster.limit = 4096
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-upsert-shrink", t),
+		Poster:          poster,
+		ApplicationPath: "apps/web.yaml",
+	}
+	ctx := context.Background()
+
+	require.NoError(t, strategy.Present(ctx, changedResult(1000)))
+	parts := len(poster.notes)
+	require.Greater(t, parts, 2)
+	for id, body := range poster.notes {
+		assert.LessOrEqual(t, len(body), poster.limit)
+		part, _ := parseCommentMarker(body, "apps/web.yaml")
+		assert.Equal(t, int(id), part)
+	}
+
+	poster.calls = nil
+	require.NoError(t, strategy.Present(ctx, changedResult(1)))
+	require.Len(t, poster.notes, 1)
+	assert.Equal(t, "update", poster.calls[0])
+	assert.Len(t, poster.calls, parts, "one update and a delete per surplus part")
+	assert.NotContains(t, poster.notes[1], "Part 1 of")
+}
+
+func TestSyncCommentsIgnoresOtherApplicationsAndDropsDuplicates(t *testing.T) {
+	poster := newNotesPoster()
+	poster.notes = map[int64]string{
+		1: "other app\n" + commentMarker("apps/web.yaml.bak", 1, ""),
+		2: "stale\n" + commentMarker("apps/web.yaml", 1, ""),
+		3: "duplicate\n" + commentMarker("apps/web.yaml", 1, ""),
+		4: "a reviewer's comment",
+	}
+	poster.nextID = 5
+
+	existing, err := findComments(context.Background(), poster, "apps/web.yaml")
+	require.NoError(t, err)
+	body := "fresh\n" + commentMarker("apps/web.yaml", 1, "")
+	activity, err := syncComments(context.Background(), poster, poster, "apps/web.yaml", existing, []string{body}, syncOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, commentActivity{Updated: 1, Deleted: 1}, activity)
+	assert.Equal(t, map[int64]string{
+		1: "other app\n" + commentMarker("apps/web.yaml.bak", 1, ""),
+		2: body,
+		4: "a reviewer's comment",
+	}, poster.notes)
+}
+
+func TestFindCommentsReportsErrors(t *testing.T) {
+	poster := newNotesPoster()
+	poster.findErr = errors.New("403 Forbidden")
+
+	_, err := findComments(context.Background(), poster, "apps/web.yaml")
+	require.ErrorContains(t, err, "find previous diff comments: 403 Forbidden")
+}
+
+func TestCommentStrategyAppendModePostsEveryRun(t *testing.T) {
+	poster := newNotesPoster()
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-append", t),
+		Poster:          poster,
+		Mode:            CommentModeAppend,
+		ApplicationPath: "apps/web.yaml",
+	}
+
+	require.NoError(t, strategy.Present(context.Background(), changedResult(1)))
+	require.NoError(t, strategy.Present(context.Background(), changedResult(1)))
+	assert.Equal(t, []string{"post", "post"}, poster.calls)
+	assert.NotContains(t, poster.notes[1], commentMarkerPrefix)
+}
+
+func TestCommentStrategyMarksStaleDiffComments(t *testing.T) {
+	poster := newNotesPoster()
+	poster.limit = 4096
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-stale", t),
+		Poster:          poster,
+		ApplicationPath: "apps/web.yaml",
+		Revision:        "0123456789abcdef0123456789abcdef01234567",
+	}
+	ctx := context.Background()
+
+	require.NoError(t, strategy.Present(ctx, changedResult(1000)))
+	require.Greater(t, len(poster.notes), 1)
+
+	require.NoError(t, strategy.Present(ctx, ComparisonResult{}))
+	require.Len(t, poster.notes, 1)
+	assert.Contains(t, poster.notes[1], "no longer differs from the target branch as of commit `01234567`")
+	assert.NotContains(t, poster.notes[1], "+ replicas: 3")
+	part, state := parseCommentMarker(poster.notes[1], "apps/web.yaml")
+	assert.Equal(t, 1, part)
+	assert.Equal(t, commentStateStale, state)
+
+	poster.calls = nil
+	strategy.Revision = "fedcba9876543210fedcba9876543210fedcba98"
+	require.NoError(t, strategy.Present(ctx, ComparisonResult{}))
+	assert.Empty(t, poster.calls, "a stale comment keeps naming the commit the diff went away")
+
+	require.NoError(t, strategy.Present(ctx, changedResult(1)))
+	assert.Contains(t, poster.notes[1], "+ replicas: 3")
+}
+
+func TestMarkUncomparedStale(t *testing.T) {
+	poster := newNotesPoster()
+	poster.limit = 4096
+	ctx := context.Background()
+	for _, applicationPath := range []string{"apps/web.yaml", "apps/api.yaml"} {
+		strategy := CommentStrategy{Log: setupSilentLogger("comment-uncompared", t), Poster: poster, ApplicationPath: applicationPath}
+		require.NoError(t, strategy.Present(ctx, changedResult(1000)))
+	}
+	empty := CommentStrategy{Log: setupSilentLogger("comment-uncompared", t), Poster: poster, ApplicationPath: "apps/db.yaml"}
+	require.NoError(t, empty.Present(ctx, ComparisonResult{}))
+
+	marked, err := markUncomparedStale(ctx, poster, poster, []string{"apps/api.yaml"}, "0123456789abcdef")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apps/web.yaml"}, marked)
+
+	web, err := findComments(ctx, poster, "apps/web.yaml")
+	require.NoError(t, err)
+	require.Len(t, web, 1)
+	assert.Contains(t, web[0].Body, "no longer differs from the target branch as of commit `01234567`")
+	api, err := findComments(ctx, poster, "apps/api.yaml")
+	require.NoError(t, err)
+	assert.True(t, showsDiff(api, "apps/api.yaml"), "compared Applications are left to CommentStrategy")
+
+	marked, err = markUncomparedStale(ctx, poster, poster, nil, "fedcba9876543210")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apps/api.yaml"}, marked, "stale and empty comments are left alone")
+}
+
+func TestMarkedApplication(t *testing.T) {
+	applicationPath, ok := markedApplication("body\n\n" + commentMarker("apps/a b.yaml", 2, commentStateStale))
+	assert.True(t, ok)
+	assert.Equal(t, "apps/a b.yaml", applicationPath)
+
+	_, ok = markedApplication("no marker")
+	assert.False(t, ok)
+	_, ok = markedApplication(commentMarkerPrefix + "%zz part=1 -->")
+	assert.False(t, ok)
+}
+
+// resolvablePoster is a notesPoster whose comments reviewers can resolve.
+type resolvablePoster struct {
+	*notesPoster
+	reopened []int64
+}
+
+func (p *resolvablePoster) Reopen(_ context.Context, id int64) error {
+	p.reopened = append(p.reopened, id)
+	return nil
+}
+
+func TestCommentStrategyReopensOnlyResultsNeedingReview(t *testing.T) {
+	poster := &resolvablePoster{notesPoster: newNotesPoster()}
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-reopen", t),
+		Poster:          poster,
+		ApplicationPath: "apps/web.yaml",
+		FailOnRisk:      RiskHigh,
+	}
+	ctx := context.Background()
+
+	require.NoError(t, strategy.Present(ctx, changedResult(1)))
+	require.NoError(t, strategy.Present(ctx, changedResult(1)))
+	assert.Empty(t, poster.reopened, "an unchanged comment is not reopened")
+
+	require.NoError(t, strategy.Present(ctx, changedResult(2)))
+	assert.Equal(t, []int64{1}, poster.reopened, "a changed diff needs review")
+
+	poster.reopened = nil
+	require.NoError(t, strategy.Present(ctx, ComparisonResult{}))
+	assert.Contains(t, poster.notes[1], "no longer differs")
+	assert.Empty(t, poster.reopened, "the stale notice does not reopen the comment")
+
+	_, err := markUncomparedStale(ctx, poster, poster, nil, "")
+	require.NoError(t, err)
+	assert.Empty(t, poster.reopened)
+
+	require.NoError(t, strategy.Present(ctx, ComparisonResult{
+		PolicyViolations: []PolicyViolation{{Policy: "require-limits", Resource: "Deployment/web", Message: "limits missing"}},
+	}))
+	assert.Equal(t, []int64{1}, poster.reopened, "a gate failure needs review without a diff")
+
+	poster.reopened = nil
+	require.NoError(t, strategy.Present(ctx, ComparisonResult{
+		Findings: []RiskFinding{{Severity: RiskLow, Rule: "scale-to-zero", Resource: "Deployment/web", Message: "scaled to zero"}},
+	}))
+	assert.Empty(t, poster.reopened, "findings below the threshold do not gate")
+}
+
+func TestCommentStrategyIgnoresRandomMaskDigests(t *testing.T) {
+	poster := &resolvablePoster{notesPoster: newNotesPoster()}
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-mask-digests", t),
+		Poster:          poster,
+		ApplicationPath: "apps/web.yaml",
+		RandomMaskKey:   true,
+	}
+	ctx := context.Background()
+	maskedResult := func(digest string) ComparisonResult {
+		return ComparisonResult{
+			Changed: []DiffOutput{{File: File{Name: "secret.yaml"}, Diff: "- password: ENC[hmac-sha256:" + digest + "]\n+ password: ENC[hmac-sha256:ff" + digest + "]\n"}},
+		}
+	}
+
+	require.NoError(t, strategy.Present(ctx, maskedResult("0a1b")))
+	poster.calls = nil
+	require.NoError(t, strategy.Present(ctx, maskedResult("9c8d")))
+	assert.Empty(t, poster.calls, "new digests of the same values leave the comment alone")
+	assert.Empty(t, poster.reopened)
+	assert.Contains(t, poster.notes[1], "ENC[hmac-sha256:0a1b]")
+
+	strategy.RandomMaskKey = false
+	require.NoError(t, strategy.Present(ctx, maskedResult("9c8d")))
+	assert.Equal(t, []string{"update"}, poster.calls, "digests of a stable key are compared")
+	assert.Equal(t, []int64{1}, poster.reopened)
+}
+
+func TestCommentStrategyKeepsEmptyResultComments(t *testing.T) {
+	poster := newNotesPoster()
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-empty", t),
+		Poster:          poster,
+		ApplicationPath: "apps/web.yaml",
+		Revision:        "0123456789abcdef",
+	}
+	ctx := context.Background()
+
+	require.NoError(t, strategy.Present(ctx, ComparisonResult{}))
+	require.Len(t, poster.notes, 1)
+	assert.NotContains(t, poster.notes[1], "no longer differs")
+	_, state := parseCommentMarker(poster.notes[1], "apps/web.yaml")
+	assert.Equal(t, commentStateEmpty, state)
+
+	poster.calls = nil
+	require.NoError(t, strategy.Present(ctx, ComparisonResult{}))
+	assert.Empty(t, poster.calls)
+}
+
+func TestStaleCommentBodyWithoutRevision(t *testing.T) {
+	assert.Contains(t, staleCommentBody("apps/web.yaml", ""), "as of the latest run.")
+}
+
+func TestParseCommentMarker(t *testing.T) {
+	part, state := parseCommentMarker("body\n"+commentMarker("apps/a b.yaml", 3, ""), "apps/a b.yaml")
+	assert.Equal(t, 3, part)
+	assert.Empty(t, state)
+	part, state = parseCommentMarker("body\n"+commentMarker("apps/a.yaml", 1, commentStateStale), "apps/a.yaml")
+	assert.Equal(t, 1, part)
+	assert.Equal(t, commentStateStale, state)
+	part, _ = parseCommentMarker("body\n"+commentMarker("apps/a.yaml", 3, ""), "apps/b.yaml")
+	assert.Equal(t, 0, part)
+	part, _ = parseCommentMarker("body\n"+applicationMarker("apps/a.yaml")+"part=x -->", "apps/a.yaml")
+	assert.Equal(t, 0, part)
+	assert.NotContains(t, commentMarker("apps/--> evil.yaml", 1, ""), "--> evil")
+}
+
+// filePoster records the file every comment was attached to.
+type filePoster struct {
+	*notesPoster
+	files []string
+}
+
+func (p *filePoster) PostOnFile(ctx context.Context, body, path string) error {
+	p.files = append(p.files, path)
+	return p.Post(ctx, body)
+}
+
+func TestCommentStrategyAttachesCommentsToDiffFile(t *testing.T) {
+	poster := &filePoster{notesPoster: newNotesPoster()}
+	poster.limit = 4096
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-diff-file", t),
+		Poster:          poster,
+		ApplicationPath: "apps/web.yaml",
+		DiffFile:        "charts/web/values.yaml",
+	}
+
+	require.NoError(t, strategy.Present(context.Background(), changedResult(1000)))
+	require.Greater(t, len(poster.notes), 1)
+	assert.Len(t, poster.files, len(poster.notes))
+	assert.Equal(t, "charts/web/values.yaml", poster.files[0])
+
+	strategy.Mode = CommentModeAppend
+	poster.files = nil
+	require.NoError(t, strategy.Present(context.Background(), changedResult(1)))
+	assert.Equal(t, []string{"charts/web/values.yaml"}, poster.files)
+}
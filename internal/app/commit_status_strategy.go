@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shini4i/argo-compare/cmd/argo-compare/utils/logger"
+
+	"github.com/shini4i/argo-compare/internal/status"
+)
+
+// commitStatusPrefix starts the name of every commit status, followed by the
+// Application path.
+const commitStatusPrefix = "argo-compare/"
+
+// CommitStatusStrategy reports the outcome of a comparison as a commit
+// status, so the merge request widget lists every Application even when
+// comments are disabled.
+type CommitStatusStrategy struct {
+	Log             *logger.Logger
+	Reporter        status.Reporter
+	SHA             string       // Commit the status is set on.
+	TargetURL       string       // Optional link shown with the status.
+	FailOnRisk      RiskSeverity // Risk findings at or above it fail the status; zero disables the check.
+	ApplicationPath string
+}
+
+// Present implements DiffPresenter. The status fails when the result fails a
+// gate (validation, policies or the risk threshold); differences alone do not
+// fail it.
+func (s CommitStatusStrategy) Present(ctx context.Context, result ComparisonResult) error {
+	if s.Reporter == nil {
+		return errors.New("commit status strategy requires a reporter")
+	}
+	if s.SHA == "" {
+		return errors.New("commit status requires the commit SHA; set --gitlab-status-sha")
+	}
+
+	outcome := resultOutcome(result, s.FailOnRisk)
+	state := status.StateSuccess
+	if outcome.err() != nil {
+		state = status.StateFailed
+	}
+
+	name := commitStatusPrefix + s.ApplicationPath
+	err := s.Reporter.Report(ctx, s.SHA, status.Status{
+		Name:        name,
+		State:       state,
+		Description: commitStatusDescription(result, outcome),
+		TargetURL:   s.TargetURL,
+	})
+	if err != nil {
+		return fmt.Errorf("report commit status %s: %w", name, err)
+	}
+	s.Log.Debugf("Reported commit status %s as %s", name, state)
+	return nil
+}
+
+// commitStatusDescription summarises result, e.g.
+// "3 changed, 1 added, validation OK".
+func commitStatusDescription(result ComparisonResult, outcome comparisonOutcome) string {
+	var parts []string
+	for _, count := range []struct {
+		n    int
+		verb string
+	}{
+		{len(result.Changed), "changed"},
+		{len(result.Added), "added"},
+		{len(result.Removed), "removed"},
+	} {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.verb))
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "no differences")
+	}
+
+	if len(result.ValidationResults) > 0 {
+		if outcome.validationFailed {
+			parts = append(parts, "validation failed")
+		} else {
+			parts = append(parts, "validation OK")
+		}
+	}
+	switch n := len(result.PolicyViolations); {
+	case n == 1:
+		parts = append(parts, "1 policy violation")
+	case n > 1:
+		parts = append(parts, fmt.Sprintf("%d policy violations", n))
+	}
+	if outcome.riskExceeded {
+		parts = append(parts, "risk threshold exceeded")
+	}
+	return strings.Join(parts, ", ")
+}
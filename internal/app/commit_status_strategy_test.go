@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+	"github.com/shini4i/argo-compare/internal/status"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingReporter records the statuses reported to it.
+type recordingReporter struct {
+	shas     []string
+	statuses []status.Status
+	err      error
+}
+
+func (r *recordingReporter) Report(_ context.Context, sha string, st status.Status) error {
+	r.shas = append(r.shas, sha)
+	r.statuses = append(r.statuses, st)
+	return r.err
+}
+
+func TestCommitStatusStrategyReportsOutcome(t *testing.T) {
+	reporter := &recordingReporter{}
+	strategy := CommitStatusStrategy{
+		Log:             setupSilentLogger("commit-status", t),
+		Reporter:        reporter,
+		SHA:             "abc123",
+		TargetURL:       "https://gitlab.example.com/jobs/1",
+		ApplicationPath: "apps/web.yaml",
+	}
+
+	result := ComparisonResult{
+		Changed:           []DiffOutput{{}, {}, {}},
+		Added:             []DiffOutput{{}},
+		ValidationResults: map[string]ports.ValidationResult{"src": {Valid: true}},
+	}
+	require.NoError(t, strategy.Present(context.Background(), result))
+
+	require.Len(t, reporter.statuses, 1)
+	assert.Equal(t, []string{"abc123"}, reporter.shas)
+	assert.Equal(t, status.Status{
+		Name:        "argo-compare/apps/web.yaml",
+		State:       status.StateSuccess,
+		Description: "3 changed, 1 added, validation OK",
+		TargetURL:   "https://gitlab.example.com/jobs/1",
+	}, reporter.statuses[0])
+}
+
+func TestCommitStatusStrategyFailsOnGates(t *testing.T) {
+	reporter := &recordingReporter{}
+	strategy := CommitStatusStrategy{
+		Log:             setupSilentLogger("commit-status-failed", t),
+		Reporter:        reporter,
+		SHA:             "abc123",
+		FailOnRisk:      RiskHigh,
+		ApplicationPath: "apps/web.yaml",
+	}
+
+	result := ComparisonResult{
+		ValidationResults: map[string]ports.ValidationResult{"src": {Valid: false}},
+		PolicyViolations:  []PolicyViolation{{Policy: "a"}, {Policy: "b"}},
+		Findings:          []RiskFinding{{Severity: RiskHigh}},
+	}
+	require.NoError(t, strategy.Present(context.Background(), result))
+
+	assert.Equal(t, status.StateFailed, reporter.statuses[0].State)
+	assert.Equal(t, "no differences, validation failed, 2 policy violations, risk threshold exceeded", reporter.statuses[0].Description)
+}
+
+func TestCommitStatusStrategyErrors(t *testing.T) {
+	reporter := &recordingReporter{err: assert.AnError}
+	strategy := CommitStatusStrategy{
+		Log:             setupSilentLogger("commit-status-error", t),
+		Reporter:        reporter,
+		ApplicationPath: "apps/web.yaml",
+	}
+
+	require.ErrorContains(t, strategy.Present(context.Background(), ComparisonResult{}), "commit SHA")
+	assert.Empty(t, reporter.statuses)
+
+	strategy.SHA = "abc123"
+	err := strategy.Present(context.Background(), ComparisonResult{})
+	require.ErrorIs(t, err, assert.AnError)
+	assert.Contains(t, err.Error(), "report commit status argo-compare/apps/web.yaml")
+}
+
+func TestSelectDiffStrategiesWithCommitStatus(t *testing.T) {
+	cfg, err := NewConfig("main",
+		WithCacheDir("/tmp/cache"),
+		WithCommitStatus(CommitStatusConfig{BaseURL: "https://gitlab.example.com", Token: "token", ProjectID: "1"}),
+	)
+	require.NoError(t, err)
+
+	reporter := &recordingReporter{}
+	appInstance, err := New(cfg, Dependencies{
+		FS:                   afero.NewMemMapFs(),
+		Logger:               setupSilentLogger("commit-status-select", t),
+		CommitStatusReporter: reporter,
+	})
+	require.NoError(t, err)
+	appInstance.revision = "head-sha"
+
+	strategies, err := appInstance.selectDiffStrategies("apps/foo.yaml", "apps/foo.yaml")
+	require.NoError(t, err)
+	require.Len(t, strategies, 2)
+	statusStrategy, ok := strategies[1].(CommitStatusStrategy)
+	require.True(t, ok)
+	assert.Equal(t, "head-sha", statusStrategy.SHA, "HEAD is used without an explicit SHA")
+
+	appInstance.cfg.CommitStatus.SHA = "source-sha"
+	strategies, err = appInstance.selectDiffStrategies("apps/foo.yaml", "apps/foo.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "source-sha", strategies[1].(CommitStatusStrategy).SHA)
+}
@@ -24,8 +24,9 @@ type File struct {
 
 // DiffOutput contains the unified diff for a single manifest.
 type DiffOutput struct {
-	File File
-	Diff string
+	File   File
+	Diff   string
+	Masked bool // Whether sensitive values were redacted from either side of the diff.
 }
 
 // ComparisonResult aggregates the additions, removals, and changes discovered.
@@ -34,6 +35,11 @@ type ComparisonResult struct {
 	Removed           []DiffOutput
 	Changed           []DiffOutput
 	ValidationResults map[string]ports.ValidationResult // Validation results keyed by target (e.g., "src", "dst")
+	PolicyViolations  []PolicyViolation                 // Resources that violate a configured policy.
+	Resources         []ResourceChange                  // Every rendered resource, paired across both legs.
+	Images            []ImageChange                     // Container images that differ between the legs.
+	Findings          []RiskFinding                     // Risky changes, most severe first.
+	Deprecations      []DeprecatedAPI                   // Deprecated or removed API versions used by the current branch.
 }
 
 // IsEmpty reports whether there are no changes to present.
@@ -59,6 +65,13 @@ type Compare struct {
 	addedFiles   []File
 	removedFiles []File
 	diffFiles    []File
+	masked       map[string]maskedContent // Manifests read so far, keyed by path.
+}
+
+// maskedContent is a manifest after sensitive data masking.
+type maskedContent struct {
+	content []byte
+	masked  bool // Whether masking redacted anything.
 }
 
 // fs returns the filesystem to use, defaulting to the cached OS filesystem if none is configured.
@@ -180,68 +193,119 @@ func (c *Compare) buildResult() (ComparisonResult, error) {
 		return ComparisonResult{}, err
 	}
 
+	resources, err := c.collectResources()
+	if err != nil {
+		return ComparisonResult{}, err
+	}
+
 	return ComparisonResult{
-		Added:   added,
-		Removed: removed,
-		Changed: changed,
+		Added:     added,
+		Removed:   removed,
+		Changed:   changed,
+		Resources: resources,
+		Images:    collectImageChanges(resources),
 	}, nil
 }
 
+// collectResources parses the masked manifests of both legs and pairs their
+// resources by identity.
+func (c *Compare) collectResources() ([]ResourceChange, error) {
+	dstResources, err := c.loadResources(TargetTypeDestination, c.dstFiles)
+	if err != nil {
+		return nil, err
+	}
+	srcResources, err := c.loadResources(TargetTypeSource, c.srcFiles)
+	if err != nil {
+		return nil, err
+	}
+	return pairResources(dstResources, srcResources), nil
+}
+
+// loadResources reads and parses every manifest of one leg.
+func (c *Compare) loadResources(target string, files []File) ([]Resource, error) {
+	var resources []Resource
+	for _, f := range files {
+		content, _, err := c.readMasked(filepath.Join(c.TmpDir, "templates", target, f.Name))
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, parseResources(f.Name, content)...)
+	}
+	return resources, nil
+}
+
 // generateDiffs collects unified diff outputs for each provided file.
 func (c *Compare) generateDiffs(files []File) ([]DiffOutput, error) {
 	outputs := make([]DiffOutput, 0, len(files))
 
 	for _, f := range files {
-		diff, err := c.generateDiff(f)
+		diff, masked, err := c.generateDiff(f)
 		if err != nil {
 			return nil, err
 		}
-		outputs = append(outputs, DiffOutput{File: f, Diff: diff})
+		outputs = append(outputs, DiffOutput{File: f, Diff: diff, Masked: masked})
 	}
 
 	return outputs, nil
 }
 
-// generateDiff creates the unified diff for a single manifest entry.
-func (c *Compare) generateDiff(f File) (string, error) {
+// generateDiff creates the unified diff for a single manifest entry and
+// reports whether masking redacted anything in it.
+func (c *Compare) generateDiff(f File) (string, bool, error) {
 	dstFilePath := filepath.Join(c.TmpDir, "templates", TargetTypeDestination, f.Name)
 	srcFilePath := filepath.Join(c.TmpDir, "templates", TargetTypeSource, f.Name)
 
-	srcFile, err := c.readFileContent(srcFilePath)
+	srcFile, srcMasked, err := c.readMasked(srcFilePath)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
-	dstFile, err := c.readFileContent(dstFilePath)
+	dstFile, dstMasked, err := c.readMasked(dstFilePath)
 	if err != nil {
-		return "", err
+		return "", false, err
+	}
+
+	edits := myers.ComputeEdits(span.URIFromPath(srcFilePath), string(dstFile), string(srcFile))
+
+	return fmt.Sprint(gotextdiff.ToUnified(srcFilePath, dstFilePath, string(dstFile), edits)), srcMasked || dstMasked, nil
+}
+
+// readMasked returns the content of the manifest at path with sensitive data
+// masked, and whether masking redacted anything. Each manifest is read and
+// masked once; diffs and parsed resources share the result.
+func (c *Compare) readMasked(path string) ([]byte, bool, error) {
+	if cached, ok := c.masked[path]; ok {
+		return cached.content, cached.masked, nil
 	}
 
+	content, err := c.readFileContent(path)
+	if err != nil {
+		return nil, false, err
+	}
+	var masked bool
 	if c.Masker != nil {
-		srcFile, err = c.applyMask(srcFile)
-		if err != nil {
-			return "", err
-		}
-		dstFile, err = c.applyMask(dstFile)
-		if err != nil {
-			return "", err
+		if content, masked, err = c.applyMask(content); err != nil {
+			return nil, false, err
 		}
 	}
 
-	edits := myers.ComputeEdits(span.URIFromPath(srcFilePath), string(dstFile), string(srcFile))
-
-	return fmt.Sprint(gotextdiff.ToUnified(srcFilePath, dstFilePath, string(dstFile), edits)), nil
+	if c.masked == nil {
+		c.masked = make(map[string]maskedContent)
+	}
+	c.masked[path] = maskedContent{content: content, masked: masked}
+	return content, masked, nil
 }
 
-// applyMask redacts sensitive manifest data when a masker dependency is configured.
-func (c *Compare) applyMask(content []byte) ([]byte, error) {
+// applyMask redacts sensitive manifest data when a masker dependency is
+// configured, reporting whether anything was redacted.
+func (c *Compare) applyMask(content []byte) ([]byte, bool, error) {
 	masked, changed, err := c.Masker.Mask(content)
 	if err != nil {
-		return nil, fmt.Errorf("mask manifest content: %w", err)
+		return nil, false, fmt.Errorf("mask manifest content: %w", err)
 	}
 	if !changed {
-		return content, nil
+		return content, false, nil
 	}
-	return masked, nil
+	return masked, true, nil
 }
 
 // stripHelmLabels removes Helm-managed metadata that would otherwise produce noisy diffs.
@@ -12,6 +12,7 @@ import (
 	"github.com/shini4i/argo-compare/cmd/argo-compare/utils/logger"
 
 	"github.com/shini4i/argo-compare/cmd/argo-compare/utils"
+	"github.com/shini4i/argo-compare/internal/ports"
 	"github.com/shini4i/argo-compare/internal/sanitizer"
 	"github.com/shini4i/argo-compare/internal/testfixtures"
 	"github.com/spf13/afero"
@@ -191,6 +192,66 @@ func TestCompareExecuteProducesDiffs(t *testing.T) {
 	assert.Contains(t, result.Changed[0].Diff, "+    side: src")
 }
 
+// TestCompareExecutePairsResourcesAndImages ensures both legs are parsed into
+// resources and image changes are derived from them.
+func TestCompareExecutePairsResourcesAndImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "templates", "src")
+	dstDir := filepath.Join(tmpDir, "templates", "dst")
+	require.NoError(t, os.MkdirAll(srcDir, 0o755))
+	require.NoError(t, os.MkdirAll(dstDir, 0o755))
+
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:%s
+          env:
+            - name: DB_PASSWORD
+              value: hunter2
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "web.yaml"), []byte(fmt.Sprintf(deployment, "1.26")), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dstDir, "web.yaml"), []byte(fmt.Sprintf(deployment, "1.25")), 0o644))
+
+	masker, err := sanitizer.NewMasker(sanitizer.DefaultMaskingRules())
+	require.NoError(t, err)
+
+	compare := Compare{
+		Fs:                 afero.NewOsFs(),
+		Globber:            utils.CustomGlobber{},
+		TmpDir:             tmpDir,
+		PreserveHelmLabels: true,
+		Masker:             masker,
+	}
+
+	result, err := compare.Execute()
+	require.NoError(t, err)
+
+	require.Len(t, result.Resources, 2)
+	byKind := make(map[string]ResourceChange)
+	for _, change := range result.Resources {
+		byKind[change.Current().Kind] = change
+	}
+	assert.True(t, byKind["Deployment"].IsChanged(), "deployment changed")
+	assert.False(t, byKind["Service"].IsChanged(), "service unchanged")
+	assert.Equal(t, "/web.yaml", byKind["Deployment"].New.File)
+	assert.NotContains(t, fmt.Sprint(byKind["Deployment"].New.Object), "hunter2", "resources are parsed after masking")
+
+	assert.Equal(t, []ImageChange{
+		{Workload: "Deployment/web", Container: "app", Old: "nginx:1.25", New: "nginx:1.26"},
+	}, result.Images)
+}
+
 // TestCompareExecuteMasksSecretDiff ensures secret diffs redact sensitive values before presentation.
 func TestCompareExecuteMasksSecretDiff(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -235,9 +296,66 @@ data:
 
 	assert.NotContains(t, diff, "c2VjcmV0")
 	assert.NotContains(t, diff, "ZGlmZmVyZW50")
-	assert.Contains(t, diff, "ENC[sha256:")
-	assert.Contains(t, diff, "-  password: ENC[sha256:")
-	assert.Contains(t, diff, "+  password: ENC[sha256:")
+	assert.Contains(t, diff, "ENC[hmac-sha256:")
+	assert.Contains(t, diff, "-  password: ENC[hmac-sha256:")
+	assert.Contains(t, diff, "+  password: ENC[hmac-sha256:")
+	assert.True(t, result.Changed[0].Masked)
+}
+
+// countingMasker counts how often each manifest content is masked.
+type countingMasker struct {
+	masker ports.SensitiveDataMasker
+	calls  map[string]int
+}
+
+func (m countingMasker) Mask(content []byte) ([]byte, bool, error) {
+	m.calls[string(content)]++
+	return m.masker.Mask(content)
+}
+
+// TestCompareExecuteMasksEachManifestOnce ensures diffs and parsed resources
+// share the masked content instead of masking every manifest twice.
+func TestCompareExecuteMasksEachManifestOnce(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "templates", "src")
+	dstDir := filepath.Join(tmpDir, "templates", "dst")
+	require.NoError(t, os.MkdirAll(srcDir, 0o755))
+	require.NoError(t, os.MkdirAll(dstDir, 0o755))
+
+	files := map[string]string{
+		filepath.Join(srcDir, "added.yaml"):     "kind: ConfigMap\nmetadata:\n  name: added\n",
+		filepath.Join(dstDir, "removed.yaml"):   "kind: ConfigMap\nmetadata:\n  name: removed\n",
+		filepath.Join(srcDir, "changed.yaml"):   "kind: Secret\nmetadata:\n  name: changed\ndata:\n  password: c2VjcmV0\n",
+		filepath.Join(dstDir, "changed.yaml"):   "kind: Secret\nmetadata:\n  name: changed\ndata:\n  password: b2xk\n",
+		filepath.Join(srcDir, "unchanged.yaml"): "kind: ConfigMap\nmetadata:\n  name: unchanged\n",
+		filepath.Join(dstDir, "unchanged.yaml"): "kind: ConfigMap\nmetadata:\n  name: unchanged\n",
+	}
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	masker := countingMasker{masker: sanitizer.NewKubernetesSecretMasker(), calls: map[string]int{}}
+	compare := Compare{
+		Fs:                 afero.NewOsFs(),
+		Globber:            utils.CustomGlobber{},
+		TmpDir:             tmpDir,
+		PreserveHelmLabels: true,
+		Masker:             masker,
+	}
+
+	result, err := compare.Execute()
+	require.NoError(t, err)
+	require.Len(t, result.Changed, 1)
+	assert.True(t, result.Changed[0].Masked)
+	assert.Len(t, result.Resources, 4)
+
+	for path, content := range files {
+		want := 1
+		if strings.HasSuffix(path, "unchanged.yaml") {
+			want = 2 // Both legs have the same content.
+		}
+		assert.Equal(t, want, masker.calls[content], path)
+	}
 }
 
 // TestCompareGenerateDiffMaskError verifies masking failures are surfaced with context.
@@ -259,7 +377,7 @@ func TestCompareGenerateDiffMaskError(t *testing.T) {
 		Masker: failingMasker{err: maskErr},
 	}
 
-	_, err := compare.generateDiff(File{Name: "/demo.yaml"})
+	_, _, err := compare.generateDiff(File{Name: "/demo.yaml"})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "mask manifest content")
 	assert.Contains(t, err.Error(), maskErr.Error())
@@ -3,7 +3,12 @@ package app
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
+
+	"github.com/shini4i/argo-compare/internal/deprecation"
+	"github.com/shini4i/argo-compare/internal/sanitizer"
 )
 
 // Config captures runtime parameters for a comparison run.
@@ -27,6 +32,26 @@ type Config struct {
 	AnchorFileName          string
 	GitUsername             string
 	GitToken                string
+	MaskingKey              string
+	MaskDigestLength        int
+	MaskingRulesFile        string
+	FailOnRisk              RiskSeverity
+	PolicyFile              string
+	DiffExitCode            bool
+	KubeVersion             string
+	ReportJSON              string
+	ReportSARIF             string
+	ReportJUnit             string
+	ReportCodeQuality       string
+	ReportHTML              string
+	OutputDir               string
+	PatchFile               string
+	GitHubActions           *GitHubActionsConfig
+	CommitStatus            *CommitStatusConfig
+	Labels                  *LabelsConfig
+	Webhook                 *WebhookConfig
+	CommentTemplate         string
+	ReportTemplates         []ReportTemplate
 }
 
 // ConfigOption mutates a Config during construction.
@@ -54,6 +79,35 @@ func NewConfig(targetBranch string, opts ...ConfigOption) (Config, error) {
 		}
 	}
 
+	if cfg.CommitStatus != nil {
+		if err := cfg.CommitStatus.validate(); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if cfg.Labels != nil {
+		if err := cfg.Labels.validate(); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if cfg.Webhook != nil {
+		if err := cfg.Webhook.validate(); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if cfg.MaskDigestLength != 0 && (cfg.MaskDigestLength < sanitizer.MinDigestLength || cfg.MaskDigestLength > sanitizer.MaxDigestLength) {
+		return Config{}, fmt.Errorf("mask digest length must be between %d and %d, got %d",
+			sanitizer.MinDigestLength, sanitizer.MaxDigestLength, cfg.MaskDigestLength)
+	}
+
+	if cfg.KubeVersion != "" {
+		if _, err := deprecation.ParseVersion(cfg.KubeVersion); err != nil {
+			return Config{}, err
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -65,12 +119,50 @@ const (
 	CommentProviderNone CommentProvider = ""
 	// CommentProviderGitLab enables posting comments to GitLab merge requests.
 	CommentProviderGitLab CommentProvider = "gitlab"
+	// CommentProviderGitHub enables posting comments to GitHub pull requests.
+	CommentProviderGitHub CommentProvider = "github"
+	// CommentProviderGitea enables posting comments to Gitea and Forgejo pull requests.
+	CommentProviderGitea CommentProvider = "gitea"
+	// CommentProviderBitbucket enables posting comments to Bitbucket Cloud and Data Center pull requests.
+	CommentProviderBitbucket CommentProvider = "bitbucket"
+	// CommentProviderAzureDevOps enables posting threads to Azure DevOps pull requests.
+	CommentProviderAzureDevOps CommentProvider = "azuredevops"
+)
+
+// CommentMode controls how the comments of earlier runs are treated.
+type CommentMode string
+
+const (
+	// CommentModeUpdate edits the comments an earlier run published for an
+	// Application in place, for providers that support it. It is the default.
+	CommentModeUpdate CommentMode = "update"
+	// CommentModeAppend posts new comments on every run.
+	CommentModeAppend CommentMode = "append"
+)
+
+// GitLabDiscussions controls whether GitLab results are posted as resolvable
+// discussion threads.
+type GitLabDiscussions string
+
+const (
+	// GitLabDiscussionsOff posts plain notes. It is the default.
+	GitLabDiscussionsOff GitLabDiscussions = "off"
+	// GitLabDiscussionsGeneral opens a discussion on the merge request.
+	GitLabDiscussionsGeneral GitLabDiscussions = "general"
+	// GitLabDiscussionsDiff opens a discussion on the Application file, or the
+	// first changed file of an anchor group, in the merge request diff.
+	GitLabDiscussionsDiff GitLabDiscussions = "diff"
 )
 
 // CommentConfig stores configuration necessary to publish comparison results as comments.
 type CommentConfig struct {
-	Provider CommentProvider
-	GitLab   GitLabCommentConfig
+	Provider    CommentProvider
+	Mode        CommentMode // Empty selects CommentModeUpdate.
+	GitLab      GitLabCommentConfig
+	GitHub      GitHubCommentConfig
+	Gitea       GiteaCommentConfig
+	Bitbucket   BitbucketCommentConfig
+	AzureDevOps AzureDevOpsCommentConfig
 }
 
 // GitLabCommentConfig supplies the details required to comment on a GitLab Merge Request.
@@ -79,9 +171,60 @@ type GitLabCommentConfig struct {
 	Token           string
 	ProjectID       string
 	MergeRequestIID int
+	Discussions     GitLabDiscussions // Empty selects GitLabDiscussionsOff.
+}
+
+// GitHubCommentConfig supplies the details required to comment on a GitHub pull request.
+type GitHubCommentConfig struct {
+	BaseURL     string // REST API root; empty selects github.com.
+	Token       string
+	Repository  string // Repository in owner/name form.
+	PullRequest int
+}
+
+// GiteaCommentConfig supplies the details required to comment on a Gitea or Forgejo pull request.
+type GiteaCommentConfig struct {
+	BaseURL     string
+	Token       string
+	Repository  string // Repository in owner/name form.
+	PullRequest int
+	CABundle    string // PEM file with additional CAs trusted for BaseURL.
+}
+
+// BitbucketCommentConfig supplies the details required to comment on a Bitbucket pull request.
+type BitbucketCommentConfig struct {
+	BaseURL     string // Cloud API root (empty selects bitbucket.org) or Data Center server root.
+	DataCenter  bool
+	Workspace   string // Cloud workspace, or Data Center project key.
+	Repository  string // Repository slug.
+	PullRequest int
+	Username    string // Selects Basic auth with Token as app password; empty sends Token as a bearer token.
+	Token       string
+}
+
+// AzureDevOpsCommentConfig supplies the details required to open threads on an Azure DevOps pull request.
+type AzureDevOpsCommentConfig struct {
+	OrganizationURL     string // Collection URI, e.g. https://dev.azure.com/acme.
+	Project             string
+	Repository          string // Repository name or ID.
+	PullRequest         int
+	Token               string
+	PersonalAccessToken bool // Sends Token with Basic auth; otherwise it is a bearer token such as System.AccessToken.
 }
 
 func (c CommentConfig) validate() error {
+	switch c.Mode {
+	case "", CommentModeUpdate, CommentModeAppend:
+	default:
+		return fmt.Errorf("unsupported comment mode %q (use update or append)", c.Mode)
+	}
+
+	switch c.GitLab.Discussions {
+	case "", GitLabDiscussionsOff, GitLabDiscussionsGeneral, GitLabDiscussionsDiff:
+	default:
+		return fmt.Errorf("unsupported gitlab discussions setting %q (use off, general or diff)", c.GitLab.Discussions)
+	}
+
 	switch c.Provider {
 	case CommentProviderNone:
 		return nil
@@ -90,6 +233,30 @@ func (c CommentConfig) validate() error {
 			return fmt.Errorf("gitlab comment configuration requires base URL, token, project ID, and merge request IID")
 		}
 		return nil
+	case CommentProviderGitHub:
+		if c.GitHub.Token == "" || c.GitHub.Repository == "" || c.GitHub.PullRequest == 0 {
+			return fmt.Errorf("github comment configuration requires token, repository, and pull request number")
+		}
+		return nil
+	case CommentProviderGitea:
+		if c.Gitea.BaseURL == "" || c.Gitea.Token == "" || c.Gitea.Repository == "" || c.Gitea.PullRequest == 0 {
+			return fmt.Errorf("gitea comment configuration requires base URL, token, repository, and pull request number")
+		}
+		return nil
+	case CommentProviderBitbucket:
+		if c.Bitbucket.Token == "" || c.Bitbucket.Workspace == "" || c.Bitbucket.Repository == "" || c.Bitbucket.PullRequest == 0 {
+			return fmt.Errorf("bitbucket comment configuration requires token, workspace, repository, and pull request ID")
+		}
+		if c.Bitbucket.DataCenter && c.Bitbucket.BaseURL == "" {
+			return fmt.Errorf("bitbucket data center comment configuration requires base URL")
+		}
+		return nil
+	case CommentProviderAzureDevOps:
+		azure := c.AzureDevOps
+		if azure.OrganizationURL == "" || azure.Project == "" || azure.Repository == "" || azure.PullRequest == 0 || azure.Token == "" {
+			return fmt.Errorf("azuredevops comment configuration requires organization URL, project, repository, pull request ID, and token")
+		}
+		return nil
 	default:
 		return fmt.Errorf("unsupported comment provider %q", c.Provider)
 	}
@@ -99,12 +266,108 @@ func (c CommentConfig) validate() error {
 func WithCommentConfig(commentCfg CommentConfig) ConfigOption {
 	return func(cfg *Config) {
 		cfg.Comment = &CommentConfig{
-			Provider: commentCfg.Provider,
-			GitLab:   commentCfg.GitLab,
+			Provider:    commentCfg.Provider,
+			Mode:        commentCfg.Mode,
+			GitLab:      commentCfg.GitLab,
+			GitHub:      commentCfg.GitHub,
+			Gitea:       commentCfg.Gitea,
+			Bitbucket:   commentCfg.Bitbucket,
+			AzureDevOps: commentCfg.AzureDevOps,
 		}
 	}
 }
 
+// CommitStatusConfig supplies the details required to report a GitLab commit
+// status per Application.
+type CommitStatusConfig struct {
+	BaseURL   string
+	Token     string
+	ProjectID string
+	SHA       string // Commit the statuses are set on; empty selects the checked-out HEAD.
+	TargetURL string // Optional link shown with every status, such as the job log.
+}
+
+func (c CommitStatusConfig) validate() error {
+	if c.BaseURL == "" || c.Token == "" || c.ProjectID == "" {
+		return fmt.Errorf("gitlab commit status configuration requires base URL, token, and project ID")
+	}
+	return nil
+}
+
+// WithCommitStatus reports the outcome of every Application as a GitLab
+// commit status named argo-compare/<application>.
+func WithCommitStatus(statusCfg CommitStatusConfig) ConfigOption {
+	return func(cfg *Config) {
+		cfg.CommitStatus = &statusCfg
+	}
+}
+
+// LabelsConfig supplies the details required to label a GitLab Merge Request
+// based on the results.
+type LabelsConfig struct {
+	BaseURL         string
+	Token           string
+	ProjectID       string
+	MergeRequestIID int
+	RulesFile       string // YAML file mapping conditions to labels; empty selects the default rules.
+}
+
+func (c LabelsConfig) validate() error {
+	if c.BaseURL == "" || c.Token == "" || c.ProjectID == "" || c.MergeRequestIID == 0 {
+		return fmt.Errorf("gitlab labels configuration requires base URL, token, project ID, and merge request IID")
+	}
+	return nil
+}
+
+// WithMergeRequestLabels adds and removes GitLab merge request labels once
+// the run completes, according to the rules of labelsCfg.RulesFile.
+func WithMergeRequestLabels(labelsCfg LabelsConfig) ConfigOption {
+	return func(cfg *Config) {
+		cfg.Labels = &labelsCfg
+	}
+}
+
+// WebhookConfig supplies the endpoint the JSON run report is POSTed to.
+type WebhookConfig struct {
+	URL     string
+	Headers map[string]string // Extra request headers, e.g. Authorization.
+	Secret  string            // Signs the report with HMAC-SHA256; empty sends it unsigned.
+}
+
+func (c WebhookConfig) validate() error {
+	endpoint, err := url.Parse(c.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("webhook URL must be an absolute http or https URL, got %q", c.URL)
+	}
+	for name := range c.Headers {
+		if name == "" || strings.ContainsAny(name, " \t:") {
+			return fmt.Errorf("invalid webhook header name %q", name)
+		}
+	}
+	return nil
+}
+
+// WithWebhook POSTs the JSON run report to webhookCfg.URL once the run
+// completes.
+func WithWebhook(webhookCfg WebhookConfig) ConfigOption {
+	return func(cfg *Config) {
+		cfg.Webhook = &webhookCfg
+	}
+}
+
+// GitHubActionsConfig enables output tailored to GitHub Actions runners.
+type GitHubActionsConfig struct {
+	StepSummary string // Job summary file ($GITHUB_STEP_SUMMARY); empty skips the summary.
+}
+
+// WithGitHubActions groups the console output per Application, emits workflow
+// command annotations and appends results to the job summary.
+func WithGitHubActions(actionsCfg GitHubActionsConfig) ConfigOption {
+	return func(cfg *Config) {
+		cfg.GitHubActions = &GitHubActionsConfig{StepSummary: actionsCfg.StepSummary}
+	}
+}
+
 // WithFileToCompare sets the specific manifest file to inspect.
 func WithFileToCompare(file string) ConfigOption {
 	return func(cfg *Config) {
@@ -231,3 +494,141 @@ func WithAnchorFileName(name string) ConfigOption {
 		cfg.AnchorFileName = name
 	}
 }
+
+// WithMaskingKey sets the HMAC key used to derive secret placeholders. A stable
+// per-repository key keeps placeholders comparable across runs while preventing
+// offline brute-forcing of short secret values from published diffs. An empty
+// key makes the masker fall back to a random per-run key.
+func WithMaskingKey(key string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.MaskingKey = key
+	}
+}
+
+// WithMaskingRulesFile points the rule-based masker at a YAML rules file that
+// extends (or, with `includeDefaults: false`, replaces) the built-in masking
+// rules. An empty path keeps the built-in rules.
+func WithMaskingRulesFile(path string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.MaskingRulesFile = path
+	}
+}
+
+// WithMaskDigestLength sets how many hex characters of the HMAC digest appear in
+// each secret placeholder. Zero keeps the masker's default.
+func WithMaskDigestLength(length int) ConfigOption {
+	return func(cfg *Config) {
+		cfg.MaskDigestLength = length
+	}
+}
+
+// WithFailOnRisk makes Run return ErrRiskThresholdExceeded when any risk
+// finding is at or above severity. The zero value disables the gate; findings
+// are still reported.
+func WithFailOnRisk(severity RiskSeverity) ConfigOption {
+	return func(cfg *Config) {
+		cfg.FailOnRisk = severity
+	}
+}
+
+// WithPolicyFile enables policy evaluation with the policies defined in the
+// given YAML file. An empty path disables policy evaluation.
+func WithPolicyFile(path string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.PolicyFile = path
+	}
+}
+
+// WithDiffExitCode makes Run return ErrDifferencesFound when any comparison
+// produced a diff, so callers can distinguish "changes cluster state" from
+// "no changes" by exit status.
+func WithDiffExitCode(enabled bool) ConfigOption {
+	return func(cfg *Config) {
+		cfg.DiffExitCode = enabled
+	}
+}
+
+// WithKubeVersion sets the Kubernetes version that deprecated API versions are
+// checked against. Applications that declare spec.source.helm.kubeVersion
+// override it.
+func WithKubeVersion(version string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.KubeVersion = version
+	}
+}
+
+// WithReportJSON writes a machine-readable JSON report of the run to path once
+// every comparison has finished. An empty path disables the report.
+func WithReportJSON(path string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ReportJSON = path
+	}
+}
+
+// WithReportSARIF writes manifest validation findings as a SARIF 2.1.0 log to
+// path once the run completes. An empty path disables the report.
+func WithReportSARIF(path string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ReportSARIF = path
+	}
+}
+
+// WithReportJUnit writes manifest validation results as JUnit XML to path once
+// the run completes. An empty path disables the report.
+func WithReportJUnit(path string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ReportJUnit = path
+	}
+}
+
+// WithReportCodeQuality writes validation errors, policy violations and risk
+// findings as a GitLab Code Quality report to path once the run completes. An
+// empty path disables the report.
+func WithReportCodeQuality(path string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ReportCodeQuality = path
+	}
+}
+
+// WithReportHTML writes a self-contained HTML diff report of the run to path
+// once the run completes. An empty path disables the report.
+func WithReportHTML(path string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ReportHTML = path
+	}
+}
+
+// WithOutputDir persists the normalised, masked manifests of both legs of
+// every compared Application below dir. An empty dir disables the export.
+func WithOutputDir(dir string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.OutputDir = dir
+	}
+}
+
+// WithPatchFile writes the changed resources of the run as a single
+// git-apply-compatible patch to path once the run completes. An empty path
+// disables the patch.
+func WithPatchFile(path string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.PatchFile = path
+	}
+}
+
+// WithCommentTemplate renders merge request comments and the GitHub job
+// summary through the text/template file at path instead of the built-in
+// layout. An empty path keeps the built-in layout.
+func WithCommentTemplate(path string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.CommentTemplate = path
+	}
+}
+
+// WithReportTemplate renders the run report through the text/template file
+// at template and writes the result to output once the run completes. It can
+// be applied several times to produce several reports.
+func WithReportTemplate(template, output string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ReportTemplates = append(cfg.ReportTemplates, ReportTemplate{Template: template, Output: output})
+	}
+}
@@ -116,9 +116,247 @@ func TestNewConfigWithInvalidGitLabComment(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestNewConfigWithGitHubComment(t *testing.T) {
+	cfg, err := NewConfig("main",
+		WithCommentConfig(CommentConfig{
+			Provider: CommentProviderGitHub,
+			GitHub: GitHubCommentConfig{
+				Token:       "secret",
+				Repository:  "octo/deploy",
+				PullRequest: 42,
+			},
+		}),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Comment)
+	assert.Equal(t, CommentProviderGitHub, cfg.Comment.Provider)
+	assert.Equal(t, "octo/deploy", cfg.Comment.GitHub.Repository)
+	assert.Equal(t, 42, cfg.Comment.GitHub.PullRequest)
+
+	_, err = NewConfig("main", WithCommentConfig(CommentConfig{
+		Provider: CommentProviderGitHub,
+		GitHub:   GitHubCommentConfig{Token: "secret", Repository: "octo/deploy"},
+	}))
+	require.Error(t, err)
+}
+
+func TestNewConfigWithGiteaComment(t *testing.T) {
+	gitea := GiteaCommentConfig{
+		BaseURL:     "https://forgejo.example.com",
+		Token:       "secret",
+		Repository:  "platform/gitops",
+		PullRequest: 8,
+		CABundle:    "/etc/ssl/forgejo-ca.pem",
+	}
+	cfg, err := NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderGitea, Gitea: gitea}))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Comment)
+	assert.Equal(t, gitea, cfg.Comment.Gitea)
+
+	gitea.BaseURL = ""
+	_, err = NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderGitea, Gitea: gitea}))
+	require.Error(t, err)
+}
+
+func TestNewConfigWithBitbucketComment(t *testing.T) {
+	bitbucket := BitbucketCommentConfig{Workspace: "acme", Repository: "gitops", PullRequest: 5, Token: "secret"}
+	cfg, err := NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderBitbucket, Bitbucket: bitbucket}))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Comment)
+	assert.Equal(t, bitbucket, cfg.Comment.Bitbucket)
+
+	bitbucket.DataCenter = true
+	_, err = NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderBitbucket, Bitbucket: bitbucket}))
+	require.Error(t, err, "Data Center has no default base URL")
+
+	bitbucket.BaseURL = "https://bitbucket.example.com"
+	_, err = NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderBitbucket, Bitbucket: bitbucket}))
+	require.NoError(t, err)
+}
+
+func TestNewConfigWithAzureDevOpsComment(t *testing.T) {
+	azure := AzureDevOpsCommentConfig{
+		OrganizationURL: "https://dev.azure.com/acme",
+		Project:         "platform",
+		Repository:      "gitops",
+		PullRequest:     77,
+		Token:           "secret",
+	}
+	cfg, err := NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderAzureDevOps, AzureDevOps: azure}))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Comment)
+	assert.Equal(t, azure, cfg.Comment.AzureDevOps)
+
+	azure.Token = ""
+	_, err = NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderAzureDevOps, AzureDevOps: azure}))
+	require.Error(t, err)
+}
+
+func TestNewConfigValidatesCommentMode(t *testing.T) {
+	gitlab := GitLabCommentConfig{BaseURL: "https://gitlab.example.com", Token: "secret", ProjectID: "1", MergeRequestIID: 42}
+
+	cfg, err := NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderGitLab, Mode: CommentModeAppend, GitLab: gitlab}))
+	require.NoError(t, err)
+	assert.Equal(t, CommentModeAppend, cfg.Comment.Mode)
+
+	_, err = NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderGitLab, Mode: "replace", GitLab: gitlab}))
+	require.ErrorContains(t, err, `unsupported comment mode "replace"`)
+}
+
+func TestNewConfigValidatesGitLabDiscussions(t *testing.T) {
+	gitlab := GitLabCommentConfig{BaseURL: "https://gitlab.example.com", Token: "secret", ProjectID: "1", MergeRequestIID: 42, Discussions: GitLabDiscussionsDiff}
+
+	cfg, err := NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderGitLab, GitLab: gitlab}))
+	require.NoError(t, err)
+	assert.Equal(t, GitLabDiscussionsDiff, cfg.Comment.GitLab.Discussions)
+
+	gitlab.Discussions = "line"
+	_, err = NewConfig("main", WithCommentConfig(CommentConfig{Provider: CommentProviderGitLab, GitLab: gitlab}))
+	require.ErrorContains(t, err, `unsupported gitlab discussions setting "line"`)
+}
+
+func TestNewConfigWithCommitStatus(t *testing.T) {
+	cfg, err := NewConfig("main", WithCommitStatus(CommitStatusConfig{BaseURL: "https://gitlab.example.com", Token: "secret", ProjectID: "1", SHA: "abc123"}))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.CommitStatus)
+	assert.Equal(t, "abc123", cfg.CommitStatus.SHA)
+
+	_, err = NewConfig("main", WithCommitStatus(CommitStatusConfig{BaseURL: "https://gitlab.example.com", ProjectID: "1"}))
+	require.ErrorContains(t, err, "gitlab commit status configuration requires base URL, token, and project ID")
+}
+
+func TestNewConfigWithMergeRequestLabels(t *testing.T) {
+	cfg, err := NewConfig("main", WithMergeRequestLabels(LabelsConfig{BaseURL: "https://gitlab.example.com", Token: "secret", ProjectID: "1", MergeRequestIID: 7}))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Labels)
+	assert.Equal(t, 7, cfg.Labels.MergeRequestIID)
+
+	_, err = NewConfig("main", WithMergeRequestLabels(LabelsConfig{BaseURL: "https://gitlab.example.com", Token: "secret", ProjectID: "1"}))
+	require.ErrorContains(t, err, "gitlab labels configuration requires base URL, token, project ID, and merge request IID")
+}
+
 func TestNewConfigWithUnsupportedCommentProvider(t *testing.T) {
 	_, err := NewConfig("main",
-		WithCommentConfig(CommentConfig{Provider: "bitbucket"}),
+		WithCommentConfig(CommentConfig{Provider: "sourcehut"}),
 	)
 	require.Error(t, err)
 }
+
+func TestWithMaskingOptions(t *testing.T) {
+	cfg, err := NewConfig("main", WithMaskingKey("repo-key"), WithMaskDigestLength(16), WithMaskingRulesFile("masking.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "repo-key", cfg.MaskingKey)
+	assert.Equal(t, 16, cfg.MaskDigestLength)
+	assert.Equal(t, "masking.yaml", cfg.MaskingRulesFile)
+}
+
+func TestNewConfigRejectsOutOfRangeMaskDigestLength(t *testing.T) {
+	for _, length := range []int{-1, 4, 65} {
+		_, err := NewConfig("main", WithMaskDigestLength(length))
+		require.Error(t, err, "length %d should be rejected", length)
+		assert.Contains(t, err.Error(), "mask digest length")
+	}
+}
+
+func TestWithFailOnRisk(t *testing.T) {
+	cfg, err := NewConfig("main", WithFailOnRisk(RiskHigh))
+	require.NoError(t, err)
+	assert.Equal(t, RiskHigh, cfg.FailOnRisk)
+}
+
+func TestWithPolicyFile(t *testing.T) {
+	cfg, err := NewConfig("main", WithPolicyFile("policies.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "policies.yaml", cfg.PolicyFile)
+}
+
+func TestWithDiffExitCode(t *testing.T) {
+	cfg, err := NewConfig("main", WithDiffExitCode(true))
+	require.NoError(t, err)
+	assert.True(t, cfg.DiffExitCode)
+}
+
+func TestWithKubeVersion(t *testing.T) {
+	cfg, err := NewConfig("main", WithKubeVersion("v1.29.4"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.29.4", cfg.KubeVersion)
+
+	_, err = NewConfig("main", WithKubeVersion("1"))
+	assert.ErrorContains(t, err, "invalid Kubernetes version")
+}
+
+func TestWithReportJSON(t *testing.T) {
+	cfg, err := NewConfig("main", WithReportJSON("out/report.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "out/report.json", cfg.ReportJSON)
+}
+
+func TestWithValidationReports(t *testing.T) {
+	cfg, err := NewConfig("main", WithReportSARIF("out/validation.sarif"), WithReportJUnit("out/junit.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, "out/validation.sarif", cfg.ReportSARIF)
+	assert.Equal(t, "out/junit.xml", cfg.ReportJUnit)
+}
+
+func TestWithReportCodeQuality(t *testing.T) {
+	cfg, err := NewConfig("main", WithReportCodeQuality("out/gl-code-quality.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "out/gl-code-quality.json", cfg.ReportCodeQuality)
+}
+
+func TestWithReportHTML(t *testing.T) {
+	cfg, err := NewConfig("main", WithReportHTML("out/report.html"))
+	require.NoError(t, err)
+	assert.Equal(t, "out/report.html", cfg.ReportHTML)
+}
+
+func TestWithOutputDir(t *testing.T) {
+	cfg, err := NewConfig("main", WithOutputDir("rendered"))
+	require.NoError(t, err)
+	assert.Equal(t, "rendered", cfg.OutputDir)
+}
+
+func TestWithGitHubActions(t *testing.T) {
+	cfg, err := NewConfig("main", WithGitHubActions(GitHubActionsConfig{StepSummary: "summary.md"}))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.GitHubActions)
+	assert.Equal(t, "summary.md", cfg.GitHubActions.StepSummary)
+}
+
+func TestWithTemplates(t *testing.T) {
+	cfg, err := NewConfig("main",
+		WithCommentTemplate("comment.tmpl"),
+		WithReportTemplate("summary.tmpl", "out/summary.md"),
+		WithReportTemplate("owners.tmpl", "out/owners.csv"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "comment.tmpl", cfg.CommentTemplate)
+	assert.Equal(t, []ReportTemplate{
+		{Template: "summary.tmpl", Output: "out/summary.md"},
+		{Template: "owners.tmpl", Output: "out/owners.csv"},
+	}, cfg.ReportTemplates)
+}
+
+func TestWithPatchFile(t *testing.T) {
+	cfg, err := NewConfig("main", WithPatchFile("out/changes.patch"))
+	require.NoError(t, err)
+	assert.Equal(t, "out/changes.patch", cfg.PatchFile)
+}
+
+func TestNewConfigWithWebhook(t *testing.T) {
+	webhookCfg := WebhookConfig{
+		URL:     "https://hooks.example.com/argo-compare",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Secret:  "s3cret",
+	}
+	cfg, err := NewConfig("main", WithWebhook(webhookCfg))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Webhook)
+	assert.Equal(t, webhookCfg, *cfg.Webhook)
+
+	_, err = NewConfig("main", WithWebhook(WebhookConfig{URL: "hooks.example.com/argo-compare"}))
+	require.ErrorContains(t, err, "webhook URL must be an absolute http or https URL")
+
+	_, err = NewConfig("main", WithWebhook(WebhookConfig{URL: "https://hooks.example.com", Headers: map[string]string{"X Team": "platform"}}))
+	require.ErrorContains(t, err, `invalid webhook header name "X Team"`)
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shini4i/argo-compare/internal/deprecation"
+	"github.com/shini4i/argo-compare/internal/models"
+)
+
+// DeprecatedAPI reports a resource rendered from the current branch that uses
+// a deprecated or removed Kubernetes API version.
+type DeprecatedAPI struct {
+	Resource     string              `json:"resource"` // Kind/name of the affected resource.
+	Namespace    string              `json:"namespace,omitempty"`
+	File         string              `json:"file"` // Manifest the resource was rendered into.
+	APIVersion   string              `json:"apiVersion"`
+	Replacement  string              `json:"replacement,omitempty"` // API version to migrate to; empty when there is none.
+	DeprecatedIn deprecation.Version `json:"deprecatedIn"`
+	RemovedIn    deprecation.Version `json:"removedIn"`
+	Removed      bool                `json:"removed"`    // Whether the target Kubernetes version no longer serves the API.
+	Introduced   bool                `json:"introduced"` // Whether the change added the resource or moved it onto this API version.
+}
+
+// targetKubeVersion resolves the Kubernetes version deprecations are checked
+// against: spec.source.helm.kubeVersion of the Application wins over the
+// configured default. Unparsable Application values are ignored with a
+// warning, falling back to the default (or to no target at all).
+func (a *App) targetKubeVersion(application models.Application) deprecation.Version {
+	if declared := application.KubeVersion(); declared != "" {
+		version, err := deprecation.ParseVersion(declared)
+		if err == nil {
+			return version
+		}
+		a.logger.Warningf("Ignoring helm.kubeVersion of Application %s: %v", application.Metadata.Name, err)
+	}
+	// NewConfig already rejected invalid values.
+	version, _ := deprecation.ParseVersion(a.cfg.KubeVersion)
+	return version
+}
+
+// checkDeprecations looks up the API version of every resource rendered from
+// the current branch. Resources using an API that is not yet deprecated in
+// target are skipped; without a target, every deprecated API is reported.
+// Results list removed APIs first, then the ones introduced by the change, in
+// resource order otherwise.
+func checkDeprecations(target deprecation.Version, changes []ResourceChange) []DeprecatedAPI {
+	var deprecated []DeprecatedAPI
+	for _, change := range changes {
+		current := change.New
+		if current == nil {
+			continue
+		}
+		api, ok := deprecation.Lookup(current.APIVersion, current.Kind)
+		if !ok {
+			continue
+		}
+		removed := api.RemovedBy(target)
+		if !removed && !api.DeprecatedBy(target) {
+			continue
+		}
+		deprecated = append(deprecated, DeprecatedAPI{
+			Resource:     current.DisplayName(),
+			Namespace:    current.Namespace,
+			File:         current.File,
+			APIVersion:   current.APIVersion,
+			Replacement:  api.Replacement,
+			DeprecatedIn: api.DeprecatedIn,
+			RemovedIn:    api.RemovedIn,
+			Removed:      removed,
+			Introduced:   change.Old == nil || change.Old.APIVersion != current.APIVersion,
+		})
+	}
+
+	sort.SliceStable(deprecated, func(i, j int) bool {
+		if deprecated[i].Removed != deprecated[j].Removed {
+			return deprecated[i].Removed
+		}
+		return deprecated[i].Introduced && !deprecated[j].Introduced
+	})
+	return deprecated
+}
+
+// status describes the API's lifecycle relative to the target version.
+func (d DeprecatedAPI) status() string {
+	if d.Removed {
+		return fmt.Sprintf("removed in %s", d.RemovedIn)
+	}
+	return fmt.Sprintf("deprecated in %s, removed in %s", d.DeprecatedIn, d.RemovedIn)
+}
+
+// origin tells whether the change brought the deprecated API in.
+func (d DeprecatedAPI) origin() string {
+	if d.Introduced {
+		return "introduced by this change"
+	}
+	return "pre-existing"
+}
+
+// migrationHint names the replacement API version, if any, as a suffix.
+func (d DeprecatedAPI) migrationHint() string {
+	if d.Replacement == "" {
+		return "; no replacement"
+	}
+	return "; migrate to " + d.Replacement
+}
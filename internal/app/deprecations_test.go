@@ -0,0 +1,114 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shini4i/argo-compare/cmd/argo-compare/utils/logger"
+	"github.com/shini4i/argo-compare/internal/deprecation"
+	"github.com/shini4i/argo-compare/internal/models"
+)
+
+const (
+	pdbV1beta1 = `apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata: {name: web, namespace: prod}
+spec: {minAvailable: 1}
+`
+	pdbV1 = `apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata: {name: web, namespace: prod}
+spec: {minAvailable: 1}
+`
+	cronJobV1beta1 = `apiVersion: batch/v1beta1
+kind: CronJob
+metadata: {name: cleanup, namespace: prod}
+spec: {schedule: "@daily"}
+`
+	deploymentV1 = `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web, namespace: prod}
+`
+	hpaV2beta2 = `apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata: {name: web, namespace: prod}
+spec: {maxReplicas: 3}
+`
+)
+
+func TestCheckDeprecations(t *testing.T) {
+	changes := []ResourceChange{
+		riskChange(t, hpaV2beta2, hpaV2beta2), // Pre-existing, removed in 1.26.
+		riskChange(t, pdbV1, pdbV1beta1),      // Downgraded by the change, removed in 1.25.
+		riskChange(t, "", cronJobV1beta1),     // Added by the change, removed in 1.25.
+		riskChange(t, cronJobV1beta1, ""),     // Removed resources are not checked.
+		riskChange(t, "", deploymentV1),       // Not deprecated.
+	}
+
+	deprecated := checkDeprecations(deprecation.Version{Major: 1, Minor: 25}, changes)
+	require.Len(t, deprecated, 3)
+	assert.Equal(t, DeprecatedAPI{
+		Resource:     "PodDisruptionBudget/web",
+		Namespace:    "prod",
+		File:         "test.yaml",
+		APIVersion:   "policy/v1beta1",
+		Replacement:  "policy/v1",
+		DeprecatedIn: deprecation.Version{Major: 1, Minor: 21},
+		RemovedIn:    deprecation.Version{Major: 1, Minor: 25},
+		Removed:      true,
+		Introduced:   true,
+	}, deprecated[0])
+	assert.Equal(t, "CronJob/cleanup", deprecated[1].Resource)
+	assert.True(t, deprecated[1].Removed)
+	assert.True(t, deprecated[1].Introduced)
+
+	assert.Equal(t, "HorizontalPodAutoscaler/web", deprecated[2].Resource, "pre-existing usage is listed last")
+	assert.False(t, deprecated[2].Removed, "autoscaling/v2beta2 is still served by 1.25")
+	assert.False(t, deprecated[2].Introduced)
+
+	deprecated = checkDeprecations(deprecation.Version{Major: 1, Minor: 22}, changes)
+	require.Len(t, deprecated, 2, "autoscaling/v2beta2 is not deprecated until 1.23")
+	assert.False(t, deprecated[0].Removed)
+
+	deprecated = checkDeprecations(deprecation.Version{Major: 1, Minor: 20}, changes)
+	assert.Empty(t, deprecated, "none of the APIs is deprecated yet in 1.20")
+}
+
+func TestCheckDeprecationsWithoutTargetVersion(t *testing.T) {
+	deprecated := checkDeprecations(deprecation.Version{}, []ResourceChange{
+		riskChange(t, "", hpaV2beta2),
+		riskChange(t, pdbV1beta1, pdbV1beta1),
+	})
+
+	require.Len(t, deprecated, 2)
+	for _, api := range deprecated {
+		assert.False(t, api.Removed, "removal cannot be judged without a target version")
+	}
+	assert.True(t, deprecated[0].Introduced)
+	assert.Equal(t, "deprecated in 1.23, removed in 1.26", deprecated[0].status())
+	assert.False(t, deprecated[1].Introduced)
+}
+
+func TestTargetKubeVersion(t *testing.T) {
+	var buf bytes.Buffer
+	logger.RedirectForTest(t, &buf)
+
+	a := &App{cfg: Config{KubeVersion: "1.24"}, logger: logger.New("test-kube-version")}
+
+	assert.Equal(t, deprecation.Version{Major: 1, Minor: 24}, a.targetKubeVersion(models.Application{}))
+
+	pinned := models.Application{}
+	pinned.Spec.Sources = []*models.Source{{}, {Helm: models.HelmSource{KubeVersion: "v1.27.3"}}}
+	assert.Equal(t, deprecation.Version{Major: 1, Minor: 27}, a.targetKubeVersion(pinned), "the Application wins over the default")
+
+	invalid := models.Application{}
+	invalid.Metadata.Name = "web"
+	invalid.Spec.Source = &models.Source{Helm: models.HelmSource{KubeVersion: "latest"}}
+	assert.Equal(t, deprecation.Version{Major: 1, Minor: 24}, a.targetKubeVersion(invalid))
+	assert.Contains(t, buf.String(), "Ignoring helm.kubeVersion of Application web")
+
+	assert.True(t, (&App{logger: a.logger}).targetKubeVersion(models.Application{}).IsZero())
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/shini4i/argo-compare/cmd/argo-compare/utils/logger"
 
 	"github.com/shini4i/argo-compare/internal/ports"
+	"github.com/shini4i/argo-compare/internal/ui"
 )
 
 // DiffPresenter presents comparison results to the user.
@@ -39,7 +40,11 @@ type ExternalDiffStrategy struct {
 // Present prints comparison results using the configured stdout logger.
 // The context parameter is accepted for interface compliance but not used.
 func (s StdoutStrategy) Present(_ context.Context, result ComparisonResult) error {
+	logImageChanges(s.Log, result.Images)
+	logRiskFindings(s.Log, result.Findings)
 	logValidationResults(s.Log, result.ValidationResults)
+	logPolicyViolations(s.Log, result.PolicyViolations)
+	logDeprecatedAPIs(s.Log, result.Deprecations)
 
 	if result.IsEmpty() {
 		s.Log.Info("No diff was found in rendered manifests!")
@@ -59,6 +64,39 @@ func (s StdoutStrategy) Present(_ context.Context, result ComparisonResult) erro
 	return nil
 }
 
+// logRiskFindings prints risk findings, most severe first, right after the
+// image summary so they are not buried under long diffs.
+func logRiskFindings(log *logger.Logger, findings []RiskFinding) {
+	if len(findings) == 0 {
+		return
+	}
+
+	log.Warning(ui.Red("===> Risk Findings"))
+	for _, finding := range findings {
+		severity := strings.ToUpper(finding.Severity.String())
+		if finding.Severity >= RiskHigh {
+			severity = ui.Red(severity)
+		} else {
+			severity = ui.Yellow(severity)
+		}
+		log.Warningf("▶ [%s] %s: %s (%s)", severity, finding.Resource, finding.Message, finding.Rule)
+	}
+}
+
+// logImageChanges prints the container image summary ahead of everything
+// else, since "which images change?" is usually the first review question.
+func logImageChanges(log *logger.Logger, images []ImageChange) {
+	if len(images) == 0 {
+		return
+	}
+
+	log.Info("===> Image Changes")
+	for _, change := range images {
+		image, oldVersion, newVersion := describeImageChange(change)
+		log.Infof("▶ %s [%s] %s: %s → %s", change.Workload, change.containerLabel(), image, oldVersion, newVersion)
+	}
+}
+
 // logValidationResults emits validation status for each target in a stable order
 // through the supplied logger. Shared by the stdout and external-diff strategies
 // so terminal output stays consistent regardless of which one is active.
@@ -91,6 +129,37 @@ func logValidationResults(log *logger.Logger, results map[string]ports.Validatio
 	}
 }
 
+// logPolicyViolations prints policy violations right after the validation
+// results, since both gate the run.
+func logPolicyViolations(log *logger.Logger, violations []PolicyViolation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	log.Warning(ui.Red("===> Policy Violations"))
+	for _, violation := range violations {
+		log.Warningf("▶ [%s] %s: %s", violation.Policy, violation.Resource, violation.Message)
+	}
+}
+
+// logDeprecatedAPIs prints deprecated API usage after the gate results.
+// Removed APIs are highlighted in red, and resources the change introduced on
+// a deprecated API are marked as new so they stand out from existing debt.
+func logDeprecatedAPIs(log *logger.Logger, deprecated []DeprecatedAPI) {
+	if len(deprecated) == 0 {
+		return
+	}
+
+	log.Warning(ui.Yellow("===> Deprecated APIs"))
+	for _, api := range deprecated {
+		status := api.status()
+		if api.Removed {
+			status = ui.Red(status)
+		}
+		log.Warningf("▶ [%s] %s uses %s (%s%s)", status, api.Resource, api.APIVersion, api.origin(), api.migrationHint())
+	}
+}
+
 // printSection logs a summary of diff entries and prints their unified diffs.
 func (s StdoutStrategy) printSection(operation string, entries []DiffOutput) {
 	if len(entries) == 0 {
@@ -113,7 +182,11 @@ func (s StdoutStrategy) printSection(operation string, entries []DiffOutput) {
 // Present streams diff content to the configured external tool.
 // The context is used for cancellation of external tool execution.
 func (s ExternalDiffStrategy) Present(ctx context.Context, result ComparisonResult) error {
+	logImageChanges(s.Log, result.Images)
+	logRiskFindings(s.Log, result.Findings)
 	logValidationResults(s.Log, result.ValidationResults)
+	logPolicyViolations(s.Log, result.PolicyViolations)
+	logDeprecatedAPIs(s.Log, result.Deprecations)
 
 	if result.IsEmpty() {
 		s.Log.Info("No diff was found in rendered manifests!")
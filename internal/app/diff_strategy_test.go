@@ -5,9 +5,11 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/shini4i/argo-compare/cmd/argo-compare/utils/logger"
+	"github.com/shini4i/argo-compare/internal/deprecation"
 	"github.com/shini4i/argo-compare/internal/ports"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -457,3 +459,79 @@ func TestValidateToolName(t *testing.T) {
 		})
 	}
 }
+
+func TestStdoutStrategyPrintsImageChangesFirst(t *testing.T) {
+	var buf bytes.Buffer
+	logger.RedirectForTest(t, &buf)
+
+	strategy := StdoutStrategy{Log: logger.New("test-stdout-images")}
+	result := ComparisonResult{
+		Images: []ImageChange{
+			{Workload: "Deployment/web", Container: "app", Old: "nginx:1.25", New: "nginx:1.26"},
+			{Workload: "Job/migrate", Container: "init", Init: true, New: "migrate:3"},
+		},
+		ValidationResults: map[string]ports.ValidationResult{
+			"src": {Target: "src", Valid: true, ResourceCount: 1},
+		},
+	}
+	require.NoError(t, strategy.Present(context.Background(), result))
+
+	out := buf.String()
+	assert.Contains(t, out, "Deployment/web [app] nginx: 1.25 → 1.26")
+	assert.Contains(t, out, "Job/migrate [init (init)] migrate: (none) → 3")
+	assert.Less(t, strings.Index(out, "Image Changes"), strings.Index(out, "Manifest Validation Results"))
+}
+
+func TestStdoutStrategyPrintsRiskFindings(t *testing.T) {
+	var buf bytes.Buffer
+	logger.RedirectForTest(t, &buf)
+
+	strategy := StdoutStrategy{Log: logger.New("test-stdout-risks")}
+	result := ComparisonResult{
+		Findings: []RiskFinding{
+			{Severity: RiskCritical, Rule: "namespace-deletion", Resource: "Namespace/prod", Message: "namespace is deleted"},
+		},
+		Images: []ImageChange{{Workload: "Deployment/web", Container: "app", Old: "nginx:1.25", New: "nginx:1.26"}},
+	}
+	require.NoError(t, strategy.Present(context.Background(), result))
+
+	out := buf.String()
+	assert.Contains(t, out, "[CRITICAL] Namespace/prod: namespace is deleted (namespace-deletion)")
+	assert.Less(t, strings.Index(out, "Image Changes"), strings.Index(out, "Risk Findings"), "images come first")
+}
+
+func TestStdoutStrategyPrintsDeprecatedAPIs(t *testing.T) {
+	var buf bytes.Buffer
+	logger.RedirectForTest(t, &buf)
+
+	strategy := StdoutStrategy{Log: logger.New("test-stdout-deprecations")}
+	result := ComparisonResult{
+		Deprecations: []DeprecatedAPI{{
+			Resource: "PodDisruptionBudget/web", APIVersion: "policy/v1beta1", Replacement: "policy/v1",
+			DeprecatedIn: deprecation.Version{Major: 1, Minor: 21}, RemovedIn: deprecation.Version{Major: 1, Minor: 25},
+		}},
+	}
+	require.NoError(t, strategy.Present(context.Background(), result))
+
+	out := buf.String()
+	assert.Contains(t, out, "===> Deprecated APIs")
+	assert.Contains(t, out, "[deprecated in 1.21, removed in 1.25] PodDisruptionBudget/web uses policy/v1beta1 (pre-existing; migrate to policy/v1)")
+}
+
+func TestStdoutStrategyPrintsPolicyViolationsAfterValidation(t *testing.T) {
+	var buf bytes.Buffer
+	logger.RedirectForTest(t, &buf)
+
+	strategy := StdoutStrategy{Log: logger.New("test-stdout-policies")}
+	result := ComparisonResult{
+		PolicyViolations: []PolicyViolation{{Policy: "min-replicas", Resource: "Deployment/web", Message: "too few replicas"}},
+		ValidationResults: map[string]ports.ValidationResult{
+			"src": {Target: "src", Valid: true, ResourceCount: 1},
+		},
+	}
+	require.NoError(t, strategy.Present(context.Background(), result))
+
+	out := buf.String()
+	assert.Contains(t, out, "[min-replicas] Deployment/web: too few replicas")
+	assert.Less(t, strings.Index(out, "Manifest Validation Results"), strings.Index(out, "Policy Violations"))
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/shini4i/argo-compare/internal/models"
+)
+
+// clusterScopeDir is the namespace directory of cluster-scoped resources in
+// exported manifests.
+const clusterScopeDir = "_cluster"
+
+// exportManifests persists the rendered resources of both legs under
+// cfg.OutputDir, one file per resource:
+//
+//	<output-dir>/<application>/<src|dst>/<namespace|_cluster>/<Kind[.group]>/<name>.yaml
+//
+// Resources are written after Helm label stripping and masking, with keys
+// sorted, so the two legs can be diffed with any tool. The Application's
+// directory is replaced on every run so that deleted resources do not linger.
+func (a *App) exportManifests(applicationFile string, application models.Application, changes []ResourceChange) error {
+	if a.cfg.OutputDir == "" {
+		return nil
+	}
+
+	if a.exported == nil {
+		a.exported = make(applicationDirs)
+	}
+	dir := filepath.Join(a.cfg.OutputDir, a.exported.claim(applicationFile, application.Metadata.Name))
+	if err := a.fs.RemoveAll(dir); err != nil {
+		return fmt.Errorf("clear exported manifests %s: %w", dir, err)
+	}
+
+	for _, change := range changes {
+		for leg, resource := range map[string]*Resource{TargetTypeSource: change.New, TargetTypeDestination: change.Old} {
+			if resource == nil {
+				continue
+			}
+			if err := a.exportResource(filepath.Join(dir, leg), resource); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applicationDirs assigns every Application of a run a directory name, keyed
+// to the Application file that claimed it.
+type applicationDirs map[string]string
+
+// claim names the directory of an Application after its metadata.name,
+// falling back to the manifest file name. Applications sharing a name within
+// one run (e.g. the same app deployed to several clusters) are told apart by
+// their manifest path.
+func (d applicationDirs) claim(applicationFile, name string) string {
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(applicationFile), filepath.Ext(applicationFile))
+	}
+	name = exportPathSegment(name)
+
+	if owner, taken := d[name]; taken && owner != applicationFile {
+		name += "_" + exportPathSegment(strings.TrimPrefix(filepath.ToSlash(applicationFile), "./"))
+	}
+	d[name] = applicationFile
+	return name
+}
+
+// exportResource writes a single resource below legDir.
+func (a *App) exportResource(legDir string, resource *Resource) error {
+	namespace := clusterScopeDir
+	if resource.Namespace != "" {
+		namespace = exportPathSegment(resource.Namespace)
+	}
+	kind := resource.Kind
+	if group := apiGroup(resource.APIVersion); group != "" {
+		kind += "." + group
+	}
+	path := filepath.Join(legDir, namespace, exportPathSegment(kind), exportPathSegment(resource.Name)+".yaml")
+
+	data, err := yaml.Marshal(resource.Object)
+	if err != nil {
+		return fmt.Errorf("encode exported manifest %s: %w", path, err)
+	}
+	if err := a.fs.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create export directory for %s: %w", path, err)
+	}
+	if err := afero.WriteFile(a.fs, path, data, 0o644); err != nil {
+		return fmt.Errorf("write exported manifest %s: %w", path, err)
+	}
+	return nil
+}
+
+// exportPathSegment makes a value safe to use as a single path element.
+func exportPathSegment(value string) string {
+	value = strings.NewReplacer("/", "_", `\`, "_").Replace(value)
+	if value == "" || value == "." || value == ".." {
+		return "_" + value
+	}
+	return value
+}
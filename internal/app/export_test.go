@@ -0,0 +1,75 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shini4i/argo-compare/internal/models"
+)
+
+func exportedFile(t *testing.T, fs afero.Fs, path string) string {
+	t.Helper()
+	data, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestExportManifests(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	a := &App{cfg: Config{OutputDir: "rendered"}, fs: fs}
+	require.NoError(t, afero.WriteFile(fs, "rendered/web/src/stale.yaml", []byte("stale"), 0o644))
+
+	application := models.Application{}
+	application.Metadata.Name = "web"
+	changes := pairResources(
+		parseResources("/web/templates/deployment.yaml", []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: web, namespace: prod}\nspec: {replicas: 2}\n")),
+		parseResources("/web/templates/all.yaml", []byte(
+			"kind: Deployment\napiVersion: apps/v1\nmetadata: {namespace: prod, name: web}\nspec: {replicas: 3}\n"+
+				"---\napiVersion: v1\nkind: Namespace\nmetadata: {name: prod}\n")),
+	)
+
+	require.NoError(t, a.exportManifests("apps/web.yaml", application, changes))
+
+	assert.Equal(t, "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n    name: web\n    namespace: prod\nspec:\n    replicas: 3\n",
+		exportedFile(t, fs, "rendered/web/src/prod/Deployment.apps/web.yaml"))
+	assert.Contains(t, exportedFile(t, fs, "rendered/web/dst/prod/Deployment.apps/web.yaml"), "replicas: 2")
+	assert.Contains(t, exportedFile(t, fs, "rendered/web/src/_cluster/Namespace/prod.yaml"), "kind: Namespace")
+
+	exists, err := afero.Exists(fs, "rendered/web/dst/_cluster/Namespace/prod.yaml")
+	require.NoError(t, err)
+	assert.False(t, exists, "resources missing on a leg are not exported for it")
+	exists, err = afero.Exists(fs, "rendered/web/src/stale.yaml")
+	require.NoError(t, err)
+	assert.False(t, exists, "previous exports of the Application are replaced")
+}
+
+func TestExportManifestsIsDisabledWithoutOutputDir(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	a := &App{fs: fs}
+	changes := pairResources(nil, parseResources("/cm.yaml", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata: {name: cm}\n")))
+
+	require.NoError(t, a.exportManifests("apps/web.yaml", models.Application{}, changes))
+
+	entries, err := afero.ReadDir(fs, "/")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestApplicationDirsClaim(t *testing.T) {
+	dirs := make(applicationDirs)
+
+	assert.Equal(t, "web", dirs.claim("clusters/a/web.yaml", "web"))
+	assert.Equal(t, "web", dirs.claim("clusters/a/web.yaml", "web"), "the same Application keeps its directory")
+	assert.Equal(t, "web_clusters_b_web.yaml", dirs.claim("./clusters/b/web.yaml", "web"))
+	assert.Equal(t, "api", dirs.claim("apps/api.yaml", ""))
+}
+
+func TestExportPathSegment(t *testing.T) {
+	assert.Equal(t, "system:controller:web", exportPathSegment("system:controller:web"))
+	assert.Equal(t, "a_b_c", exportPathSegment(`a/b\c`))
+	assert.Equal(t, "_..", exportPathSegment(".."))
+	assert.Equal(t, "_", exportPathSegment(""))
+}
@@ -214,6 +214,15 @@ func (g *GitRepo) OriginURL() (string, error) {
 	return urls[0], nil
 }
 
+// HeadCommit returns the hash of the commit checked out at HEAD.
+func (g *GitRepo) HeadCommit() (string, error) {
+	headRef, err := g.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	return headRef.Hash().String(), nil
+}
+
 // mergeBaseTree returns the tree of the merge-base commit between headCommit
 // and targetCommit — the snapshot from which the source branch diverged.
 // Diffing against this snapshot yields only the changes the source branch
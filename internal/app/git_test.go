@@ -313,6 +313,17 @@ func TestGitRepoTreeForBranchMissingRemote(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestGitRepoHeadCommit(t *testing.T) {
+	repoInstance, repo := buildGitRepo(t, true)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	hash, err := repoInstance.HeadCommit()
+	require.NoError(t, err)
+	require.Equal(t, head.Hash().String(), hash)
+}
+
 func TestGitRepoTargetFileContent(t *testing.T) {
 	repoInstance, _ := buildGitRepo(t, true)
 
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/afero"
+)
+
+// githubStepSummaryLimit is GitHub's size limit for a step's job summary.
+// Larger summaries are rejected as a whole, so sections that would overflow
+// it are replaced by a short notice.
+const githubStepSummaryLimit = 1024 * 1024
+
+// stopCommandsToken returns the token that pauses workflow command processing
+// while the grouped output is printed. It must not be guessable, or content
+// in the output could end the pause and inject commands of its own.
+var stopCommandsToken = rand.Text
+
+// GitHubActionsStrategy adapts the console output to GitHub Actions. It wraps
+// the output of Inner in a collapsible log group per Application, within which
+// workflow commands are disabled because the diff is controlled by whoever
+// authored the change; it emits
+// ::error and ::warning workflow commands for validation failures, policy
+// violations and risky changes so they show up as annotations, and appends a
+// Markdown section to the job summary.
+type GitHubActionsStrategy struct {
+	Inner           DiffPresenter // Console presenter whose output is grouped.
+	Out             io.Writer     // Receives workflow commands; the runner reads them from stdout.
+	Fs              afero.Fs
+	StepSummary     string             // Path from $GITHUB_STEP_SUMMARY; empty skips the job summary.
+	Template        *template.Template // Renders the job summary instead of the built-in comment layout when set.
+	FailOnRisk      RiskSeverity       // Findings at or above it are errors rather than warnings.
+	ShowAdded       bool
+	ShowRemoved     bool
+	ApplicationPath string
+}
+
+// Present implements DiffPresenter.
+func (s GitHubActionsStrategy) Present(ctx context.Context, result ComparisonResult) error {
+	if s.Inner == nil || s.Out == nil {
+		return errors.New("GitHub Actions strategy requires an inner presenter and an output")
+	}
+
+	token := stopCommandsToken()
+	fmt.Fprintf(s.Out, "::group::%s\n", escapeWorkflowData("argo-compare: "+s.ApplicationPath))
+	fmt.Fprintf(s.Out, "::stop-commands::%s\n", token)
+	err := s.Inner.Present(ctx, result)
+	fmt.Fprintf(s.Out, "::%s::\n", token)
+	fmt.Fprintln(s.Out, "::endgroup::")
+	if err != nil {
+		return err
+	}
+
+	s.annotate(result)
+	return s.appendStepSummary(result)
+}
+
+// annotate emits one workflow command per gating or risky finding.
+func (s GitHubActionsStrategy) annotate(result ComparisonResult) {
+	for _, target := range sortedValidationTargets(result.ValidationResults) {
+		validation := result.ValidationResults[target]
+		if validation.InvocationError != "" {
+			s.command("error", "Manifest validator failed", "Manifest validation could not run: "+validation.InvocationError)
+		}
+		for _, validationErr := range validation.Errors {
+			s.command("error", "Manifest validation failed",
+				fmt.Sprintf("%s/%s (%s): %s", validationErr.Kind, validationErr.Name, validationErr.Filename, validationErr.Message))
+		}
+	}
+
+	for _, violation := range result.PolicyViolations {
+		s.command("error", "Policy violation: "+violation.Policy,
+			fmt.Sprintf("%s (%s): %s", violation.Resource, violation.File, violation.Message))
+	}
+
+	for _, finding := range result.Findings {
+		level := "warning"
+		if s.FailOnRisk > 0 && finding.Severity >= s.FailOnRisk {
+			level = "error"
+		}
+		s.command(level, fmt.Sprintf("Risky change (%s): %s", finding.Severity, finding.Rule),
+			fmt.Sprintf("%s: %s", finding.Resource, finding.Message))
+	}
+}
+
+// command writes a workflow command annotating the Application file.
+func (s GitHubActionsStrategy) command(level, title, message string) {
+	fmt.Fprintf(s.Out, "::%s file=%s,title=%s::%s\n", level,
+		escapeWorkflowProperty(strings.TrimPrefix(s.ApplicationPath, "./")),
+		escapeWorkflowProperty(title),
+		escapeWorkflowData(message))
+}
+
+// appendStepSummary appends the Markdown results of the Application to the
+// job summary.
+func (s GitHubActionsStrategy) appendStepSummary(result ComparisonResult) error {
+	if s.StepSummary == "" {
+		return nil
+	}
+
+	bodies, err := commentBodies(s.Template, result, s.ShowAdded, s.ShowRemoved, s.ApplicationPath, defaultCommentLengthLimit)
+	if err != nil {
+		return err
+	}
+	section := strings.Join(bodies, "\n") + "\n"
+
+	var written int64
+	if info, err := s.Fs.Stat(s.StepSummary); err == nil {
+		written = info.Size()
+	}
+	if written+int64(len(section)) > githubStepSummaryLimit {
+		section = fmt.Sprintf("## Argo Compare Results\n\n**Application:** `%s`\n\n"+
+			"Results omitted because the job summary size limit was reached. See the job log for the full diff.\n\n",
+			escapeInlineMarkdown(s.ApplicationPath))
+	}
+
+	file, err := s.Fs.OpenFile(s.StepSummary, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open GitHub step summary: %w", err)
+	}
+	if _, err := file.WriteString(section); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write GitHub step summary: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("write GitHub step summary: %w", err)
+	}
+	return nil
+}
+
+// escapeWorkflowData escapes the message of a workflow command.
+func escapeWorkflowData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// escapeWorkflowProperty escapes a workflow command property value.
+func escapeWorkflowProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+// recordingPresenter writes a marker to out when presenting.
+type recordingPresenter struct {
+	out *bytes.Buffer
+	err error
+}
+
+func (p recordingPresenter) Present(context.Context, ComparisonResult) error {
+	p.out.WriteString("diff output\n")
+	return p.err
+}
+
+// diffPresenter writes the diffs of the result to out, like the console
+// presenters do.
+type diffPresenter struct {
+	out *bytes.Buffer
+}
+
+func (p diffPresenter) Present(_ context.Context, result ComparisonResult) error {
+	for _, diff := range result.Changed {
+		p.out.WriteString(diff.Diff)
+	}
+	return nil
+}
+
+// stubStopCommandsToken makes the stop-commands token predictable.
+func stubStopCommandsToken(t *testing.T) {
+	t.Helper()
+	stopCommandsToken = func() string { return "TOKEN" }
+	t.Cleanup(func() { stopCommandsToken = rand.Text })
+}
+
+func TestGitHubActionsStrategy(t *testing.T) {
+	stubStopCommandsToken(t)
+	var out bytes.Buffer
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/runner/summary.md", []byte("previous step\n"), 0o644))
+
+	strategy := GitHubActionsStrategy{
+		Inner:           recordingPresenter{out: &out},
+		Out:             &out,
+		Fs:              fs,
+		StepSummary:     "/runner/summary.md",
+		FailOnRisk:      RiskHigh,
+		ApplicationPath: "./apps/web.yaml",
+	}
+	result := ComparisonResult{
+		Changed: []DiffOutput{{File: File{Name: "/web/deployment.yaml"}, Diff: "@@ -1 +1 @@\n-a\n+b\n"}},
+		ValidationResults: map[string]ports.ValidationResult{
+			TargetTypeSource: {Target: TargetTypeSource, ResourceCount: 1, ErrorCount: 1, Errors: []ports.ValidationError{
+				{Filename: "web/deployment.yaml", Kind: "Deployment", Name: "web", Message: "spec.replicas: expected integer\n100% wrong"},
+			}},
+		},
+		PolicyViolations: []PolicyViolation{{Policy: "require-limits", Resource: "Deployment/web", File: "/web/deployment.yaml", Message: "limits missing"}},
+		Findings: []RiskFinding{
+			{Severity: RiskCritical, Rule: "namespace-deletion", Resource: "Namespace/prod", Message: "deleted"},
+			{Severity: RiskMedium, Rule: "scale-to-zero", Resource: "Deployment/web", Message: "scaled to zero"},
+		},
+	}
+
+	require.NoError(t, strategy.Present(context.Background(), result))
+
+	assert.Equal(t, `::group::argo-compare: ./apps/web.yaml
+::stop-commands::TOKEN
+diff output
+::TOKEN::
+::endgroup::
+::error file=apps/web.yaml,title=Manifest validation failed::Deployment/web (web/deployment.yaml): spec.replicas: expected integer%0A100%25 wrong
+::error file=apps/web.yaml,title=Policy violation%3A require-limits::Deployment/web (/web/deployment.yaml): limits missing
+::error file=apps/web.yaml,title=Risky change (critical)%3A namespace-deletion::Namespace/prod: deleted
+::warning file=apps/web.yaml,title=Risky change (medium)%3A scale-to-zero::Deployment/web: scaled to zero
+`, out.String())
+
+	summary, err := afero.ReadFile(fs, "/runner/summary.md")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(summary), "previous step\n## Argo Compare Results\n"))
+	assert.Contains(t, string(summary), "**Application:** `./apps/web.yaml`")
+	assert.Contains(t, string(summary), "+b")
+}
+
+func TestGitHubActionsStrategyClosesGroupOnError(t *testing.T) {
+	stubStopCommandsToken(t)
+	var out bytes.Buffer
+	strategy := GitHubActionsStrategy{
+		Inner:           recordingPresenter{out: &out, err: errors.New("tool failed")},
+		Out:             &out,
+		Fs:              afero.NewMemMapFs(),
+		StepSummary:     "summary.md",
+		ApplicationPath: "apps/web.yaml",
+	}
+
+	err := strategy.Present(context.Background(), ComparisonResult{})
+	require.EqualError(t, err, "tool failed")
+	assert.Equal(t, "::group::argo-compare: apps/web.yaml\n::stop-commands::TOKEN\ndiff output\n::TOKEN::\n::endgroup::\n", out.String())
+}
+
+func TestGitHubActionsStrategyStopsCommandsInDiff(t *testing.T) {
+	var out bytes.Buffer
+	strategy := GitHubActionsStrategy{
+		Inner:           diffPresenter{out: &out},
+		Out:             &out,
+		Fs:              afero.NewMemMapFs(),
+		ApplicationPath: "apps/web.yaml",
+	}
+	diff := "@@ -1,4 +1,5 @@\n kind: ConfigMap\n data:\n   notes: |\n+    ::warning::injected by the change\n     hello\n"
+	result := ComparisonResult{Changed: []DiffOutput{{File: File{Name: "/web/configmap.yaml"}, Diff: diff}}}
+	require.NoError(t, strategy.Present(context.Background(), result))
+
+	lines := strings.Split(out.String(), "\n")
+	require.Greater(t, len(lines), 2)
+	token, found := strings.CutPrefix(lines[1], "::stop-commands::")
+	require.True(t, found, "commands are stopped right after the group opens")
+	assert.Len(t, token, 26)
+	assert.NotContains(t, diff, token)
+	assert.Equal(t, "::group::argo-compare: apps/web.yaml\n::stop-commands::"+token+"\n"+diff+"::"+token+"::\n::endgroup::\n", out.String())
+
+	// Every group stops commands with a token of its own.
+	out.Reset()
+	require.NoError(t, strategy.Present(context.Background(), result))
+	assert.NotContains(t, out.String(), token)
+}
+
+func TestGitHubActionsStrategyRespectsSummaryLimit(t *testing.T) {
+	var out bytes.Buffer
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "summary.md", bytes.Repeat([]byte("x"), githubStepSummaryLimit-100), 0o644))
+
+	strategy := GitHubActionsStrategy{
+		Inner:           recordingPresenter{out: &out},
+		Out:             &out,
+		Fs:              fs,
+		StepSummary:     "summary.md",
+		ApplicationPath: "apps/web.yaml",
+	}
+	result := ComparisonResult{Changed: []DiffOutput{{File: File{Name: "/big.yaml"}, Diff: strings.Repeat("+line\n", 100)}}}
+	require.NoError(t, strategy.Present(context.Background(), result))
+
+	summary, err := afero.ReadFile(fs, "summary.md")
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "Results omitted because the job summary size limit was reached")
+	assert.NotContains(t, string(summary), "+line")
+}
+
+func TestGitHubActionsStrategyRequiresInnerPresenter(t *testing.T) {
+	err := GitHubActionsStrategy{}.Present(context.Background(), ComparisonResult{})
+	assert.ErrorContains(t, err, "requires an inner presenter")
+}
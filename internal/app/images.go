@@ -0,0 +1,193 @@
+package app
+
+import (
+	"sort"
+	"strings"
+)
+
+// podSpecPaths maps workload kinds to the location of their pod spec. Argo
+// Rollouts embed a Deployment-style template; a Rollout that uses workloadRef
+// has no template and therefore no images of its own.
+var podSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+	"Rollout":     {"spec", "template", "spec"},
+}
+
+// ImageChange describes a container whose image differs between the target
+// branch and the current branch. Old is empty for containers (or workloads)
+// added by the change; New is empty for ones it removes.
+type ImageChange struct {
+	Workload  string `json:"workload"` // Kind/name of the owning workload.
+	Namespace string `json:"namespace,omitempty"`
+	Container string `json:"container"`
+	Init      bool   `json:"init"` // Whether the container is an init container.
+	Old       string `json:"old"`
+	New       string `json:"new"`
+}
+
+// containerImage is a container's image keyed for matching across legs.
+type containerImage struct {
+	name  string
+	init  bool
+	image string
+}
+
+// collectImageChanges returns one ImageChange per container whose image was
+// added, removed or replaced, ordered by workload and then container with
+// init containers first.
+func collectImageChanges(changes []ResourceChange) []ImageChange {
+	var images []ImageChange
+
+	for _, change := range changes {
+		current := change.Current()
+		if _, ok := podSpecPaths[current.Kind]; !ok || !change.IsChanged() {
+			continue
+		}
+
+		oldImages := workloadImages(change.Old)
+		newImages := workloadImages(change.New)
+
+		seen := make(map[containerImage]struct{})
+		record := func(c containerImage) {
+			key := containerImage{name: c.name, init: c.init}
+			if _, ok := seen[key]; ok {
+				return
+			}
+			seen[key] = struct{}{}
+
+			oldImage, newImage := oldImages[key], newImages[key]
+			if oldImage == newImage {
+				return
+			}
+			images = append(images, ImageChange{
+				Workload:  current.DisplayName(),
+				Namespace: current.Namespace,
+				Container: c.name,
+				Init:      c.init,
+				Old:       oldImage,
+				New:       newImage,
+			})
+		}
+
+		for _, c := range sortedContainers(newImages) {
+			record(c)
+		}
+		for _, c := range sortedContainers(oldImages) {
+			record(c)
+		}
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		a, b := images[i], images[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Workload != b.Workload {
+			return a.Workload < b.Workload
+		}
+		if a.Init != b.Init {
+			return a.Init
+		}
+		return a.Container < b.Container
+	})
+
+	return images
+}
+
+// workloadImages extracts container and init-container images from a
+// workload, keyed by container name and init flag (image left empty).
+func workloadImages(resource *Resource) map[containerImage]string {
+	images := make(map[containerImage]string)
+	if resource == nil {
+		return images
+	}
+
+	specPath, ok := podSpecPaths[resource.Kind]
+	if !ok {
+		return images
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		for _, item := range nestedSlice(resource.Object, append(append([]string{}, specPath...), field)...) {
+			container, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _ := container["name"].(string)
+			image, _ := container["image"].(string)
+			images[containerImage{name: name, init: field == "initContainers"}] = image
+		}
+	}
+
+	return images
+}
+
+// sortedContainers returns the keys of images in a stable order.
+func sortedContainers(images map[containerImage]string) []containerImage {
+	keys := make([]containerImage, 0, len(images))
+	for key := range images {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].init != keys[j].init {
+			return keys[i].init
+		}
+		return keys[i].name < keys[j].name
+	})
+	return keys
+}
+
+// splitImageReference splits an image reference into its repository and the
+// version part (":tag", "@digest" or ":tag@digest"). A colon before the last
+// slash belongs to a registry port, not a tag.
+func splitImageReference(ref string) (string, string) {
+	repository, digest := ref, ""
+	if idx := strings.Index(ref, "@"); idx >= 0 {
+		repository, digest = ref[:idx], ref[idx:]
+	}
+	if idx := strings.LastIndex(repository, ":"); idx > strings.LastIndex(repository, "/") {
+		return repository[:idx], repository[idx:] + digest
+	}
+	return repository, digest
+}
+
+// describeImageChange renders the old and new references compactly: when the
+// repository is unchanged only the tag/digest is shown on each side.
+func describeImageChange(change ImageChange) (string, string, string) {
+	oldRepo, oldVersion := splitImageReference(change.Old)
+	newRepo, newVersion := splitImageReference(change.New)
+
+	switch {
+	case change.Old == "":
+		return newRepo, "(none)", displayVersion(newVersion)
+	case change.New == "":
+		return oldRepo, displayVersion(oldVersion), "(removed)"
+	case oldRepo == newRepo:
+		return newRepo, displayVersion(oldVersion), displayVersion(newVersion)
+	default:
+		return newRepo, change.Old, change.New
+	}
+}
+
+// displayVersion strips the leading separator from a tag and marks an
+// untagged reference as implicitly "latest".
+func displayVersion(version string) string {
+	if version == "" {
+		return "latest"
+	}
+	return strings.TrimPrefix(version, ":")
+}
+
+// containerLabel renders a container name, marking init containers.
+func (c ImageChange) containerLabel() string {
+	if c.Init {
+		return c.Container + " (init)"
+	}
+	return c.Container
+}
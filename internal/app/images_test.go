@@ -0,0 +1,134 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func workloadResource(t *testing.T, manifest string) *Resource {
+	t.Helper()
+	resources := parseResources("test.yaml", []byte(manifest))
+	require.Len(t, resources, 1)
+	return &resources[0]
+}
+
+func TestCollectImageChangesAcrossWorkloadKinds(t *testing.T) {
+	oldDeployment := workloadResource(t, `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web, namespace: prod}
+spec:
+  template:
+    spec:
+      initContainers:
+        - {name: migrate, image: "registry.local:5000/app/migrate:1.0"}
+      containers:
+        - {name: app, image: "nginx:1.25"}
+        - {name: sidecar, image: "envoy:v1.30"}
+        - {name: legacy, image: "busybox"}
+`)
+	newDeployment := workloadResource(t, `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web, namespace: prod}
+spec:
+  template:
+    spec:
+      initContainers:
+        - {name: migrate, image: "registry.local:5000/app/migrate:1.1"}
+      containers:
+        - {name: app, image: "nginx:1.26"}
+        - {name: sidecar, image: "envoy:v1.30"}
+        - {name: metrics, image: "ghcr.io/org/exporter@sha256:abc"}
+`)
+	newCronJob := workloadResource(t, `apiVersion: batch/v1
+kind: CronJob
+metadata: {name: backup, namespace: prod}
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - {name: backup, image: "restic:0.16"}
+`)
+	oldRollout := workloadResource(t, `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata: {name: canary, namespace: prod}
+spec:
+  template:
+    spec:
+      containers:
+        - {name: app, image: "docker.io/org/app:2.0"}
+`)
+	newRollout := workloadResource(t, `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata: {name: canary, namespace: prod}
+spec:
+  template:
+    spec:
+      containers:
+        - {name: app, image: "ghcr.io/org/app:2.0"}
+`)
+	configMap := workloadResource(t, "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: cfg}\ndata: {image: nginx:1}\n")
+
+	images := collectImageChanges([]ResourceChange{
+		{Old: oldDeployment, New: newDeployment},
+		{New: newCronJob},
+		{Old: oldRollout, New: newRollout},
+		{New: configMap},
+	})
+
+	assert.Equal(t, []ImageChange{
+		{Workload: "CronJob/backup", Namespace: "prod", Container: "backup", New: "restic:0.16"},
+		{Workload: "Deployment/web", Namespace: "prod", Container: "migrate", Init: true,
+			Old: "registry.local:5000/app/migrate:1.0", New: "registry.local:5000/app/migrate:1.1"},
+		{Workload: "Deployment/web", Namespace: "prod", Container: "app", Old: "nginx:1.25", New: "nginx:1.26"},
+		{Workload: "Deployment/web", Namespace: "prod", Container: "legacy", Old: "busybox"},
+		{Workload: "Deployment/web", Namespace: "prod", Container: "metrics", New: "ghcr.io/org/exporter@sha256:abc"},
+		{Workload: "Rollout/canary", Namespace: "prod", Container: "app", Old: "docker.io/org/app:2.0", New: "ghcr.io/org/app:2.0"},
+	}, images)
+}
+
+func TestCollectImageChangesIgnoresUnchangedWorkloads(t *testing.T) {
+	pod := workloadResource(t, "apiVersion: v1\nkind: Pod\nmetadata: {name: p}\nspec:\n  containers:\n    - {name: app, image: nginx:1}\n")
+
+	assert.Empty(t, collectImageChanges([]ResourceChange{{Old: pod, New: pod}}))
+}
+
+func TestSplitImageReference(t *testing.T) {
+	tests := []struct {
+		ref, repository, version string
+	}{
+		{"nginx", "nginx", ""},
+		{"nginx:1.25", "nginx", ":1.25"},
+		{"registry.local:5000/app", "registry.local:5000/app", ""},
+		{"registry.local:5000/app:2", "registry.local:5000/app", ":2"},
+		{"ghcr.io/org/app@sha256:abc", "ghcr.io/org/app", "@sha256:abc"},
+		{"ghcr.io/org/app:1.0@sha256:abc", "ghcr.io/org/app", ":1.0@sha256:abc"},
+	}
+
+	for _, tt := range tests {
+		repository, version := splitImageReference(tt.ref)
+		assert.Equal(t, tt.repository, repository, tt.ref)
+		assert.Equal(t, tt.version, version, tt.ref)
+	}
+}
+
+func TestDescribeImageChange(t *testing.T) {
+	tests := []struct {
+		change                    ImageChange
+		image, oldValue, newValue string
+	}{
+		{ImageChange{Old: "nginx:1.25", New: "nginx:1.26"}, "nginx", "1.25", "1.26"},
+		{ImageChange{Old: "nginx", New: "nginx@sha256:abc"}, "nginx", "latest", "@sha256:abc"},
+		{ImageChange{New: "nginx:1.26"}, "nginx", "(none)", "1.26"},
+		{ImageChange{Old: "nginx:1.25"}, "nginx", "1.25", "(removed)"},
+		{ImageChange{Old: "docker.io/a:1", New: "ghcr.io/a:1"}, "ghcr.io/a", "docker.io/a:1", "ghcr.io/a:1"},
+	}
+
+	for _, tt := range tests {
+		image, oldValue, newValue := describeImageChange(tt.change)
+		assert.Equal(t, []string{tt.image, tt.oldValue, tt.newValue}, []string{image, oldValue, newValue})
+	}
+}
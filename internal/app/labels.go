@@ -0,0 +1,254 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/shini4i/argo-compare/internal/label"
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+// labelApplicationPlaceholder is replaced by the Application name in label
+// names, producing one label per changed Application.
+const labelApplicationPlaceholder = "{application}"
+
+// Conditions a label rule can apply on.
+const (
+	labelWhenChanged          = "changed"           // The Application's manifests differ.
+	labelWhenValidationFailed = "validation-failed" // A rendered manifest failed validation.
+	labelWhenPolicyViolated   = "policy-violated"   // A resource violated a configured policy.
+	labelWhenRisk             = "risk"              // A risk finding reached the rule's severity.
+)
+
+// LabelRule adds a merge request label when an Application's result meets
+// its condition.
+type LabelRule struct {
+	Name     string   `yaml:"name"`     // Label; {application} is replaced by the Application name.
+	When     string   `yaml:"when"`     // changed, validation-failed, policy-violated or risk.
+	Kinds    []string `yaml:"kinds"`    // With changed, only changes to resources of these kinds count.
+	Severity string   `yaml:"severity"` // With risk, the lowest severity that counts; defaults to low.
+
+	severity RiskSeverity
+}
+
+// labelDocument is the top-level layout of a labels file.
+type labelDocument struct {
+	Labels []LabelRule `yaml:"labels"`
+}
+
+// defaultLabelRules apply when no labels file is configured.
+var defaultLabelRules = []LabelRule{
+	{Name: "manifests-changed", When: labelWhenChanged},
+	{Name: "crd-change", When: labelWhenChanged, Kinds: []string{"CustomResourceDefinition"}},
+	{Name: "secrets-changed", When: labelWhenChanged, Kinds: []string{"Secret"}},
+	{Name: "validation-failed", When: labelWhenValidationFailed},
+	{Name: "app::" + labelApplicationPlaceholder, When: labelWhenChanged},
+}
+
+// loadLabelRules reads the rules of cfg.Labels.RulesFile, or returns the
+// default rules when no file is configured. cfg.Labels must be set.
+func loadLabelRules(cfg Config, reader ports.FileReader) ([]LabelRule, error) {
+	if cfg.Labels.RulesFile == "" {
+		return parseLabelRules(defaultLabelRules)
+	}
+	data, err := reader.ReadFile(cfg.Labels.RulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("read labels file %s: %w", cfg.Labels.RulesFile, err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("labels file %s not found", cfg.Labels.RulesFile)
+	}
+
+	var doc labelDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: parse labels: %w", cfg.Labels.RulesFile, err)
+	}
+	rules, err := parseLabelRules(doc.Labels)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.Labels.RulesFile, err)
+	}
+	return rules, nil
+}
+
+// parseLabelRules validates rules and resolves their severities. Unknown
+// conditions and options that do not fit the condition are errors, so that a
+// typo cannot silently stop a label from being applied.
+func parseLabelRules(rules []LabelRule) ([]LabelRule, error) {
+	parsed := make([]LabelRule, 0, len(rules))
+	for i, rule := range rules {
+		rule.Name = strings.TrimSpace(rule.Name)
+		if rule.Name == "" {
+			return nil, fmt.Errorf("label #%d: name is required", i+1)
+		}
+		if strings.Contains(rule.Name, ",") {
+			return nil, fmt.Errorf("label %q: names cannot contain commas", rule.Name)
+		}
+		if strings.Count(rule.Name, labelApplicationPlaceholder) > 1 {
+			return nil, fmt.Errorf("label %q: %s can be used only once", rule.Name, labelApplicationPlaceholder)
+		}
+
+		switch rule.When {
+		case labelWhenChanged, labelWhenValidationFailed, labelWhenPolicyViolated, labelWhenRisk:
+		case "":
+			return nil, fmt.Errorf("label %q: when is required", rule.Name)
+		default:
+			return nil, fmt.Errorf("label %q: unknown condition %q (use changed, validation-failed, policy-violated or risk)", rule.Name, rule.When)
+		}
+		if len(rule.Kinds) > 0 && rule.When != labelWhenChanged {
+			return nil, fmt.Errorf("label %q: kinds can only be used with when: changed", rule.Name)
+		}
+		if rule.Severity != "" && rule.When != labelWhenRisk {
+			return nil, fmt.Errorf("label %q: severity can only be used with when: risk", rule.Name)
+		}
+
+		rule.severity = RiskLow
+		if rule.Severity != "" {
+			severity, err := ParseRiskSeverity(rule.Severity)
+			if err != nil {
+				return nil, fmt.Errorf("label %q: %w", rule.Name, err)
+			}
+			rule.severity = severity
+		}
+		parsed = append(parsed, rule)
+	}
+	return parsed, nil
+}
+
+// applies reports whether the rule's condition holds for report.
+func (r LabelRule) applies(report ApplicationReport) bool {
+	switch r.When {
+	case labelWhenChanged:
+		if len(r.Kinds) == 0 {
+			return report.Differences
+		}
+		return changesKind(report, r.Kinds)
+	case labelWhenValidationFailed:
+		for _, result := range report.Validation {
+			if !result.Valid {
+				return true
+			}
+		}
+		return false
+	case labelWhenPolicyViolated:
+		return len(report.PolicyViolations) > 0
+	case labelWhenRisk:
+		return highestRisk(report.RiskFindings) >= r.severity
+	}
+	return false
+}
+
+// label returns the label the rule adds for report.
+func (r LabelRule) label(report ApplicationReport) string {
+	if !strings.Contains(r.Name, labelApplicationPlaceholder) {
+		return r.Name
+	}
+	return strings.Replace(r.Name, labelApplicationPlaceholder, labelApplicationName(report), 1)
+}
+
+// manages reports whether name is a label the rule may have added on an
+// earlier run, and may therefore remove.
+func (r LabelRule) manages(name string) bool {
+	prefix, suffix, templated := strings.Cut(r.Name, labelApplicationPlaceholder)
+	if !templated {
+		return name == r.Name
+	}
+	return len(name) > len(prefix)+len(suffix) && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix)
+}
+
+// labelApplicationName is the Application's metadata.name, or the manifest
+// file name when the name is unknown.
+func labelApplicationName(report ApplicationReport) string {
+	if report.Name != "" {
+		return report.Name
+	}
+	return strings.TrimSuffix(filepath.Base(report.File), filepath.Ext(report.File))
+}
+
+// changesKind reports whether any added, removed or changed manifest of
+// report contains a resource of one of kinds.
+func changesKind(report ApplicationReport, kinds []string) bool {
+	for _, manifests := range [][]ManifestReport{report.Added, report.Removed, report.Changed} {
+		for _, manifest := range manifests {
+			for _, resource := range manifest.Resources {
+				if slices.Contains(kinds, resource.Kind) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// MergeRequestLabeler is a RunPresenter that keeps the merge request's labels
+// in line with the run: labels whose rule applies to some Application are
+// added, and labels a rule manages but no longer applies to are removed.
+// Labels no rule manages are never touched.
+type MergeRequestLabeler struct {
+	Labeler label.Labeler
+	Rules   []LabelRule
+	// AddOnly keeps labels that no longer apply, for runs that see only part
+	// of the merge request, such as one limited to a single file.
+	AddOnly bool
+}
+
+// PresentRun implements RunPresenter.
+func (l MergeRequestLabeler) PresentRun(ctx context.Context, report RunReport) error {
+	if l.Labeler == nil {
+		return errors.New("merge request labeler requires a labeler implementation")
+	}
+
+	wanted := map[string]bool{}
+	for _, application := range report.Applications {
+		for _, rule := range l.Rules {
+			if rule.applies(application) {
+				wanted[rule.label(application)] = true
+			}
+		}
+	}
+
+	current, err := l.Labeler.Labels(ctx)
+	if err != nil {
+		return fmt.Errorf("read merge request labels: %w", err)
+	}
+
+	var add, remove []string
+	present := make(map[string]bool, len(current))
+	for _, name := range current {
+		present[name] = true
+		if !wanted[name] && !l.AddOnly && l.managed(name) {
+			remove = append(remove, name)
+		}
+	}
+	for name := range wanted {
+		if !present[name] {
+			add = append(add, name)
+		}
+	}
+	sort.Strings(add)
+
+	if err := l.Labeler.UpdateLabels(ctx, add, remove); err != nil {
+		return fmt.Errorf("update merge request labels: %w", err)
+	}
+	return nil
+}
+
+// managed reports whether some rule manages the label name.
+func (l MergeRequestLabeler) managed(name string) bool {
+	for _, rule := range l.Rules {
+		if rule.manages(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLabeler is an in-memory merge request label set.
+type fakeLabeler struct {
+	labels  []string
+	added   []string
+	removed []string
+	calls   int
+}
+
+func (f *fakeLabeler) Labels(context.Context) ([]string, error) {
+	return f.labels, nil
+}
+
+func (f *fakeLabeler) UpdateLabels(_ context.Context, add, remove []string) error {
+	f.calls++
+	f.added, f.removed = add, remove
+	return nil
+}
+
+func labelledApplication(name string, kinds ...string) ApplicationReport {
+	report := ApplicationReport{File: "apps/" + name + ".yaml", Name: name}
+	if len(kinds) == 0 {
+		return report
+	}
+	report.Differences = true
+	manifest := ManifestReport{File: "manifest.yaml"}
+	for _, kind := range kinds {
+		manifest.Resources = append(manifest.Resources, ResourceReport{Kind: kind, Name: name})
+	}
+	report.Changed = []ManifestReport{manifest}
+	return report
+}
+
+func TestMergeRequestLabelerDefaultRules(t *testing.T) {
+	rules, err := parseLabelRules(defaultLabelRules)
+	require.NoError(t, err)
+
+	labeler := &fakeLabeler{labels: []string{"backend", "app::old", "crd-change", "secrets-changed"}}
+	presenter := MergeRequestLabeler{Labeler: labeler, Rules: rules}
+
+	invalid := labelledApplication("api", "Deployment")
+	invalid.Validation = map[string]ports.ValidationResult{"src": {Valid: false}}
+	report := RunReport{Applications: []ApplicationReport{
+		labelledApplication("web", "Deployment", "Secret"),
+		invalid,
+		labelledApplication("unchanged"),
+	}}
+
+	require.NoError(t, presenter.PresentRun(context.Background(), report))
+	assert.Equal(t, []string{"app::api", "app::web", "manifests-changed", "validation-failed"}, labeler.added)
+	assert.Equal(t, []string{"app::old", "crd-change"}, labeler.removed, "labels no rule manages are kept")
+}
+
+func TestMergeRequestLabelerCustomRules(t *testing.T) {
+	rules, err := parseLabelRules([]LabelRule{
+		{Name: "needs-security-review", When: labelWhenRisk, Severity: "high"},
+		{Name: "policy-violation", When: labelWhenPolicyViolated},
+		{Name: "deploy/{application}/changed", When: labelWhenChanged},
+	})
+	require.NoError(t, err)
+
+	labeler := &fakeLabeler{labels: []string{"deploy/web/changed", "deploy/other"}}
+	presenter := MergeRequestLabeler{Labeler: labeler, Rules: rules}
+
+	medium := labelledApplication("web")
+	medium.RiskFindings = []RiskFinding{{Severity: RiskMedium}}
+	high := labelledApplication("api")
+	high.RiskFindings = []RiskFinding{{Severity: RiskCritical}}
+	high.PolicyViolations = []PolicyViolation{{Policy: "p"}}
+
+	require.NoError(t, presenter.PresentRun(context.Background(), RunReport{Applications: []ApplicationReport{medium, high}}))
+	assert.Equal(t, []string{"needs-security-review", "policy-violation"}, labeler.added)
+	assert.Equal(t, []string{"deploy/web/changed"}, labeler.removed)
+}
+
+func TestMergeRequestLabelerAddOnly(t *testing.T) {
+	rules, err := parseLabelRules(defaultLabelRules)
+	require.NoError(t, err)
+
+	labeler := &fakeLabeler{labels: []string{"app::api", "manifests-changed", "crd-change"}}
+	presenter := MergeRequestLabeler{Labeler: labeler, Rules: rules, AddOnly: true}
+
+	require.NoError(t, presenter.PresentRun(context.Background(), RunReport{Applications: []ApplicationReport{labelledApplication("web", "Secret")}}))
+	assert.Equal(t, []string{"app::web", "secrets-changed"}, labeler.added)
+	assert.Empty(t, labeler.removed)
+
+	require.NoError(t, presenter.PresentRun(context.Background(), RunReport{Applications: []ApplicationReport{}}))
+	assert.Empty(t, labeler.removed, "an empty partial run removes nothing")
+}
+
+func TestParseLabelRulesRejectsMistakes(t *testing.T) {
+	for _, tc := range []struct {
+		rule LabelRule
+		err  string
+	}{
+		{LabelRule{When: labelWhenChanged}, "label #1: name is required"},
+		{LabelRule{Name: "a,b", When: labelWhenChanged}, "cannot contain commas"},
+		{LabelRule{Name: "x"}, `label "x": when is required`},
+		{LabelRule{Name: "x", When: "modified"}, `unknown condition "modified"`},
+		{LabelRule{Name: "x", When: labelWhenRisk, Kinds: []string{"Secret"}}, "kinds can only be used with when: changed"},
+		{LabelRule{Name: "x", When: labelWhenChanged, Severity: "high"}, "severity can only be used with when: risk"},
+		{LabelRule{Name: "x", When: labelWhenRisk, Severity: "severe"}, `unknown risk severity "severe"`},
+		{LabelRule{Name: "{application}-{application}", When: labelWhenChanged}, "can be used only once"},
+	} {
+		_, err := parseLabelRules([]LabelRule{tc.rule})
+		assert.ErrorContains(t, err, tc.err)
+	}
+}
+
+func TestNewWithMergeRequestLabels(t *testing.T) {
+	labelsCfg := LabelsConfig{BaseURL: "https://gitlab.example.com", Token: "token", ProjectID: "1", MergeRequestIID: 7, RulesFile: "labels.yaml"}
+	cfg, err := NewConfig("main", WithCacheDir("/tmp/cache"), WithMergeRequestLabels(labelsCfg))
+	require.NoError(t, err)
+
+	newApp := func(content string) (*App, error) {
+		return New(cfg, Dependencies{
+			FS:                  afero.NewMemMapFs(),
+			FileReader:          mapFileReader{files: map[string][]byte{"labels.yaml": []byte(content)}},
+			Logger:              setupSilentLogger("app-labels", t),
+			MergeRequestLabeler: &fakeLabeler{},
+		})
+	}
+
+	appInstance, err := newApp("labels:\n  - name: crd-change\n    when: changed\n    kinds: [CustomResourceDefinition]\n")
+	require.NoError(t, err)
+	require.Len(t, appInstance.runPresenters, 1)
+	presenter, ok := appInstance.runPresenters[0].(MergeRequestLabeler)
+	require.True(t, ok)
+	assert.Equal(t, "crd-change", presenter.Rules[0].Name)
+
+	_, err = newApp("labels:\n  - name: crd-change\n    if: changed\n")
+	require.ErrorContains(t, err, "labels.yaml: parse labels")
+
+	_, err = newApp("labels:\n  - name: crd-change\n")
+	require.ErrorContains(t, err, `labels.yaml: label "crd-change": when is required`)
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shini4i/argo-compare/internal/policy"
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+// ErrPolicyViolations indicates that at least one rendered resource violated a
+// policy from the configured policy file. Like ErrManifestValidationFailed it
+// is returned at the end of Run, after every diff and comment has been emitted.
+var ErrPolicyViolations = errors.New("policy violations found")
+
+// PolicyViolation reports a resource that does not satisfy a policy.
+type PolicyViolation struct {
+	Policy    string `json:"policy"`
+	Resource  string `json:"resource"` // Kind/name of the offending resource.
+	Namespace string `json:"namespace,omitempty"`
+	File      string `json:"file"` // Manifest the resource was rendered into.
+	Message   string `json:"message"`
+}
+
+// loadPolicies reads and compiles cfg.PolicyFile. It returns a nil set when no
+// policy file is configured.
+func loadPolicies(cfg Config, reader ports.FileReader) (*policy.Set, error) {
+	if cfg.PolicyFile == "" {
+		return nil, nil
+	}
+	data, err := reader.ReadFile(cfg.PolicyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read policy file %s: %w", cfg.PolicyFile, err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("policy file %s not found", cfg.PolicyFile)
+	}
+	set, err := policy.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.PolicyFile, err)
+	}
+	return set, nil
+}
+
+// evaluatePolicies checks every resource rendered from the current branch
+// against policies. Resources removed by the change are not evaluated, since
+// they will not exist after the merge; the target branch state is exposed to
+// expressions as oldObject.
+func evaluatePolicies(policies *policy.Set, changes []ResourceChange) []PolicyViolation {
+	if policies.Len() == 0 {
+		return nil
+	}
+
+	var violations []PolicyViolation
+	for _, change := range changes {
+		if change.New == nil {
+			continue
+		}
+		var oldObject map[string]any
+		if change.Old != nil {
+			oldObject = change.Old.Object
+		}
+		for _, violation := range policies.Evaluate(change.New.Object, oldObject) {
+			violations = append(violations, PolicyViolation{
+				Policy:    violation.Policy,
+				Resource:  change.New.DisplayName(),
+				Namespace: change.New.Namespace,
+				File:      change.New.File,
+				Message:   violation.Message,
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const replicaPolicy = `
+policies:
+  - name: min-replicas
+    match: {kinds: [Deployment]}
+    expression: object.spec.replicas >= 2
+    message: Deployments need at least two replicas
+  - name: no-new-load-balancers
+    match: {kinds: [Service]}
+    expression: oldObject != null || object.spec.type != "LoadBalancer"
+`
+
+func newPolicyApp(t *testing.T, files map[string][]byte) (*App, error) {
+	t.Helper()
+	cfg, err := NewConfig("main", WithCacheDir("/tmp/cache"), WithMaskingKey("test-key"), WithPolicyFile("policies.yaml"))
+	require.NoError(t, err)
+	return New(cfg, Dependencies{
+		FS:         afero.NewMemMapFs(),
+		FileReader: mapFileReader{files: files},
+		Logger:     setupTestLogger(t, "app-policies"),
+	})
+}
+
+func TestEvaluatePolicies(t *testing.T) {
+	appInstance, err := newPolicyApp(t, map[string][]byte{"policies.yaml": []byte(replicaPolicy)})
+	require.NoError(t, err)
+	require.Equal(t, 2, appInstance.policies.Len())
+
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: web, namespace: prod}\nspec: {replicas: %d}\n"
+	service := "apiVersion: v1\nkind: Service\nmetadata: {name: web}\nspec: {type: %s}\n"
+
+	changes := []ResourceChange{
+		riskChange(t, sprintf(deployment, 3), sprintf(deployment, 1)),
+		riskChange(t, "", sprintf(service, "LoadBalancer")),
+		riskChange(t, sprintf(service, "LoadBalancer"), sprintf(service, "LoadBalancer")),
+		riskChange(t, sprintf(deployment, 0), ""),
+	}
+
+	violations := evaluatePolicies(appInstance.policies, changes)
+	assert.Equal(t, []PolicyViolation{
+		{Policy: "min-replicas", Resource: "Deployment/web", Namespace: "prod", File: "test.yaml", Message: "Deployments need at least two replicas"},
+		{Policy: "no-new-load-balancers", Resource: "Service/web", File: "test.yaml", Message: `expression "oldObject != null || object.spec.type != \"LoadBalancer\"" is false`},
+	}, violations)
+
+	assert.Nil(t, evaluatePolicies(nil, changes), "no policy file configured")
+}
+
+func TestNewRejectsUnusablePolicyFile(t *testing.T) {
+	_, err := newPolicyApp(t, nil)
+	assert.ErrorContains(t, err, "policy file policies.yaml not found")
+
+	_, err = newPolicyApp(t, map[string][]byte{"policies.yaml": []byte("policies:\n  - {name: bad, expression: 'object.'}\n")})
+	assert.ErrorContains(t, err, `policies.yaml: policy "bad"`)
+
+	cfg, err := NewConfig("main", WithCacheDir("/tmp/cache"), WithPolicyFile("policies.yaml"))
+	require.NoError(t, err)
+	_, err = New(cfg, Dependencies{
+		FS:         afero.NewMemMapFs(),
+		FileReader: mapFileReader{err: errors.New("permission denied")},
+		Logger:     setupTestLogger(t, "app-policies-unreadable"),
+	})
+	assert.ErrorContains(t, err, "read policy file policies.yaml: permission denied")
+}
+
+func TestOutcomeOfPolicyViolations(t *testing.T) {
+	outcome := (&App{}).outcomeOf(ComparisonResult{PolicyViolations: []PolicyViolation{{Policy: "p"}}})
+	assert.True(t, outcome.policyViolated)
+	assert.Equal(t, ErrPolicyViolations, outcome.err())
+}
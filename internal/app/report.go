@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/models"
+	"github.com/shini4i/argo-compare/internal/ports"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// now is the clock used for report timestamps and durations; tests replace it.
+var now = time.Now
+
+// RunPresenter presents the reports of every Application compared in a run.
+// Unlike DiffPresenter it is invoked once, after all comparisons finished.
+type RunPresenter interface {
+	PresentRun(ctx context.Context, report RunReport) error
+}
+
+// RunReport is the machine-readable record of a whole run.
+type RunReport struct {
+	Version      string              `json:"version"`
+	TargetBranch string              `json:"targetBranch"`
+	StartedAt    time.Time           `json:"startedAt"`
+	DurationMs   int64               `json:"durationMs"`
+	Masking      MaskingReport       `json:"masking"`
+	Applications []ApplicationReport `json:"applications"`
+	InvalidFiles []string            `json:"invalidFiles,omitempty"` // Manifests that could not be parsed and were skipped.
+}
+
+// MaskingReport describes how sensitive values were masked in the diffs.
+type MaskingReport struct {
+	KeyConfigured bool   `json:"keyConfigured"` // False when a random per-run key was used.
+	RulesFile     string `json:"rulesFile,omitempty"`
+}
+
+// ApplicationReport is the machine-readable record of one compared Application.
+type ApplicationReport struct {
+	File             string                            `json:"file"` // Application manifest, relative to the repository root.
+	Name             string                            `json:"name,omitempty"`
+	Anchor           *AnchorReport                     `json:"anchor,omitempty"`
+	Charts           []ChartReport                     `json:"charts,omitempty"`
+	DurationMs       int64                             `json:"durationMs"`
+	Differences      bool                              `json:"differences"`
+	Added            []ManifestReport                  `json:"added"`
+	Removed          []ManifestReport                  `json:"removed"`
+	Changed          []ManifestReport                  `json:"changed"`
+	Validation       map[string]ports.ValidationResult `json:"validation,omitempty"`
+	PolicyViolations []PolicyViolation                 `json:"policyViolations,omitempty"`
+	RiskFindings     []RiskFinding                     `json:"riskFindings,omitempty"`
+	Images           []ImageChange                     `json:"images,omitempty"`
+	Deprecations     []DeprecatedAPI                   `json:"deprecations,omitempty"`
+
+	resources []ResourceChange // Every rendered resource; used by presenters that report per resource.
+}
+
+// AnchorReport identifies the anchor that selected an Application for
+// comparison, for Applications processed through the anchor flow.
+type AnchorReport struct {
+	Dir          string   `json:"dir"`
+	Repo         string   `json:"repo,omitempty"`
+	Path         string   `json:"path"`
+	Branch       string   `json:"branch,omitempty"`
+	ChangedFiles []string `json:"changedFiles"`
+}
+
+// ChartReport records a chart rendered for one leg of the comparison.
+// Version is read from the rendered chart's Chart.yaml and is empty when it
+// could not be determined.
+type ChartReport struct {
+	Leg            string `json:"leg"` // TargetTypeSource or TargetTypeDestination.
+	Name           string `json:"name"`
+	RepoURL        string `json:"repoURL,omitempty"`
+	Path           string `json:"path,omitempty"`
+	TargetRevision string `json:"targetRevision,omitempty"`
+	Version        string `json:"version,omitempty"`
+}
+
+// ManifestReport is a rendered manifest together with its diff and the
+// resources it contains.
+type ManifestReport struct {
+	File      string           `json:"file"`
+	Masked    bool             `json:"masked"`
+	Diff      string           `json:"diff"`
+	Resources []ResourceReport `json:"resources"`
+}
+
+// ResourceReport identifies a Kubernetes resource.
+type ResourceReport struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// reporting reports whether the run collects ApplicationReports.
+func (a *App) reporting() bool {
+	return len(a.runPresenters) > 0
+}
+
+// recordReport stores the report of one compared Application, rendered into
+// tmpDir from application (source leg) and targetApp (destination leg).
+// It must be called before tmpDir is removed, since chart versions are read
+// from the rendered charts.
+func (a *App) recordReport(report ApplicationReport, result ComparisonResult, tmpDir string, application, targetApp models.Application, started time.Time) {
+	if !a.reporting() {
+		return
+	}
+
+	report.Name = application.Metadata.Name
+	report.Charts = append(a.chartReports(tmpDir, TargetTypeSource, application), a.chartReports(tmpDir, TargetTypeDestination, targetApp)...)
+	report.DurationMs = now().Sub(started).Milliseconds()
+	report.Differences = !result.IsEmpty()
+	report.Added = manifestReports(result.Added, result.Resources)
+	report.Removed = manifestReports(result.Removed, result.Resources)
+	report.Changed = manifestReports(result.Changed, result.Resources)
+	report.Validation = result.ValidationResults
+	report.PolicyViolations = result.PolicyViolations
+	report.RiskFindings = result.Findings
+	report.Images = result.Images
+	report.Deprecations = result.Deprecations
+	report.resources = result.Resources
+
+	a.reports = append(a.reports, report)
+}
+
+// presentRun hands the collected reports to every run presenter. A failing
+// presenter does not stop the others; their errors are joined.
+func (a *App) presentRun(ctx context.Context, started time.Time, invalid []string) error {
+	if !a.reporting() {
+		return nil
+	}
+
+	applications := a.reports
+	if applications == nil {
+		applications = []ApplicationReport{}
+	}
+	report := RunReport{
+		Version:      a.cfg.Version,
+		TargetBranch: a.cfg.TargetBranch,
+		StartedAt:    started.UTC(),
+		DurationMs:   now().Sub(started).Milliseconds(),
+		Masking: MaskingReport{
+			KeyConfigured: a.cfg.MaskingKey != "",
+			RulesFile:     a.cfg.MaskingRulesFile,
+		},
+		Applications: applications,
+		InvalidFiles: invalid,
+	}
+
+	var errs []error
+	for _, presenter := range a.runPresenters {
+		if err := presenter.PresentRun(ctx, report); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applicationSources lists the sources of an Application regardless of
+// whether it uses spec.source or spec.sources.
+func applicationSources(application models.Application) []*models.Source {
+	if len(application.Spec.Sources) > 0 {
+		return application.Spec.Sources
+	}
+	if application.Spec.Source != nil {
+		return []*models.Source{application.Spec.Source}
+	}
+	return nil
+}
+
+// chartReports describes the charts rendered for one leg. Both registry and
+// path-based charts are materialized under <tmpDir>/charts/<leg>/<name>.
+func (a *App) chartReports(tmpDir, leg string, application models.Application) []ChartReport {
+	var charts []ChartReport
+	for _, source := range applicationSources(application) {
+		if source == nil {
+			continue
+		}
+		name := effectiveChartName(source)
+		charts = append(charts, ChartReport{
+			Leg:            leg,
+			Name:           name,
+			RepoURL:        source.RepoURL,
+			Path:           source.Path,
+			TargetRevision: source.TargetRevision,
+			Version:        a.chartVersion(filepath.Join(tmpDir, "charts", leg, name, "Chart.yaml")),
+		})
+	}
+	return charts
+}
+
+// chartVersion reads the version field of a Chart.yaml, returning an empty
+// string when the file is missing or malformed.
+func (a *App) chartVersion(path string) string {
+	data, err := afero.ReadFile(a.fs, path)
+	if err != nil {
+		return ""
+	}
+	var chart struct {
+		Version string `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &chart); err != nil {
+		return ""
+	}
+	return chart.Version
+}
+
+// manifestReports converts diff outputs into manifest reports, attaching the
+// resources rendered into each manifest on either leg.
+func manifestReports(outputs []DiffOutput, changes []ResourceChange) []ManifestReport {
+	reports := make([]ManifestReport, 0, len(outputs))
+	for _, output := range outputs {
+		reports = append(reports, ManifestReport{
+			File:      output.File.Name,
+			Masked:    output.Masked,
+			Diff:      output.Diff,
+			Resources: resourcesInFile(output.File.Name, changes),
+		})
+	}
+	return reports
+}
+
+// resourcesInFile lists the resources rendered into file, preferring the
+// current branch's state for resources present on both legs.
+func resourcesInFile(file string, changes []ResourceChange) []ResourceReport {
+	resources := []ResourceReport{}
+	for _, change := range changes {
+		var resource *Resource
+		switch {
+		case change.New != nil && change.New.File == file:
+			resource = change.New
+		case change.Old != nil && change.Old.File == file:
+			resource = change.Old
+		default:
+			continue
+		}
+		resources = append(resources, ResourceReport{
+			APIVersion: resource.APIVersion,
+			Kind:       resource.Kind,
+			Namespace:  resource.Namespace,
+			Name:       resource.Name,
+		})
+	}
+	return resources
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Code Quality check names for findings that are not validation errors; the
+// validation rules share their identifiers with the SARIF report.
+const (
+	checkPolicyViolation = "policy-violation"
+	checkRiskPrefix      = "risk/"
+)
+
+// Code Quality severities understood by GitLab.
+const (
+	codeQualityInfo     = "info"
+	codeQualityMinor    = "minor"
+	codeQualityMajor    = "major"
+	codeQualityCritical = "critical"
+	codeQualityBlocker  = "blocker"
+)
+
+// codeQualityRiskSeverities maps risk severities onto Code Quality severities.
+var codeQualityRiskSeverities = map[RiskSeverity]string{
+	RiskLow:      codeQualityMinor,
+	RiskMedium:   codeQualityMajor,
+	RiskHigh:     codeQualityCritical,
+	RiskCritical: codeQualityBlocker,
+}
+
+// CodeQualityReportWriter writes validation errors, policy violations and
+// risk findings as a GitLab Code Quality report, so they show in the merge
+// request widget and next to the Application file in the diff. Like the SARIF
+// report, findings are located in the Application file they were rendered
+// from. Each finding carries a fingerprint derived from what it reports rather
+// than from the run, so GitLab recognises it across pipelines and only lists
+// findings the merge request introduces or resolves.
+type CodeQualityReportWriter struct {
+	Fs   afero.Fs
+	Path string
+}
+
+type (
+	codeQualityIssue struct {
+		Description string              `json:"description"`
+		CheckName   string              `json:"check_name"`
+		Fingerprint string              `json:"fingerprint"`
+		Severity    string              `json:"severity"`
+		Location    codeQualityLocation `json:"location"`
+	}
+
+	codeQualityLocation struct {
+		Path  string           `json:"path"`
+		Lines codeQualityLines `json:"lines"`
+	}
+
+	codeQualityLines struct {
+		Begin int `json:"begin"`
+	}
+)
+
+// PresentRun implements RunPresenter.
+func (w CodeQualityReportWriter) PresentRun(_ context.Context, report RunReport) error {
+	data, err := json.MarshalIndent(codeQualityIssues(report), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode Code Quality report: %w", err)
+	}
+	return writeReportFile(w.Fs, w.Path, append(data, '\n'))
+}
+
+// codeQualityIssues converts every finding of the run into an issue.
+func codeQualityIssues(report RunReport) []codeQualityIssue {
+	issues := []codeQualityIssue{}
+	for _, application := range report.Applications {
+		file := application.File
+		for _, target := range sortedValidationTargets(application.Validation) {
+			validation := application.Validation[target]
+			if validation.InvocationError != "" {
+				issues = append(issues, newCodeQualityIssue(ruleValidatorError, codeQualityCritical, file,
+					fmt.Sprintf("Manifest validation of %s could not run: %s", file, validation.InvocationError),
+					target))
+			}
+			for _, validationErr := range validation.Errors {
+				resource := validationErr.Kind + "/" + validationErr.Name
+				rule := validationRule(validationErr)
+				severity := codeQualityMajor
+				if rule == ruleMissingSchema {
+					severity = codeQualityMinor
+				}
+				issues = append(issues, newCodeQualityIssue(rule, severity, file,
+					fmt.Sprintf("%s rendered into %s by %s: %s", resource, validationErr.Filename, file, validationErr.Message),
+					target, validationErr.Filename, resource, validationErr.Message))
+			}
+		}
+		for _, violation := range application.PolicyViolations {
+			issues = append(issues, newCodeQualityIssue(checkPolicyViolation, codeQualityMajor, file,
+				fmt.Sprintf("Policy violation (%s): %s (%s): %s", violation.Policy, violation.Resource, violation.File, violation.Message),
+				violation.Policy, violation.Namespace, violation.Resource, violation.Message))
+		}
+		for _, finding := range application.RiskFindings {
+			severity, ok := codeQualityRiskSeverities[finding.Severity]
+			if !ok {
+				severity = codeQualityInfo
+			}
+			issues = append(issues, newCodeQualityIssue(checkRiskPrefix+finding.Rule, severity, file,
+				fmt.Sprintf("Risky change (%s): %s: %s", finding.Rule, finding.Resource, finding.Message),
+				finding.Namespace, finding.Resource, finding.Message))
+		}
+	}
+	return issues
+}
+
+// newCodeQualityIssue builds an issue located on the first line of file. The
+// fingerprint hashes the check, the file and identity, which must identify
+// the finding without depending on anything that changes between pipelines.
+func newCodeQualityIssue(check, severity, file, description string, identity ...string) codeQualityIssue {
+	path := strings.TrimPrefix(file, "./")
+	return codeQualityIssue{
+		Description: description,
+		CheckName:   check,
+		Fingerprint: codeQualityFingerprint(append([]string{check, path}, identity...)...),
+		Severity:    severity,
+		Location:    codeQualityLocation{Path: path, Lines: codeQualityLines{Begin: 1}},
+	}
+}
+
+// codeQualityFingerprint hashes parts into a hex digest. Parts are separated
+// by NUL so that shifting text between neighbouring parts changes the result.
+func codeQualityFingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeQualityReportWriter(t *testing.T) {
+	report := validationRunReport(t)
+	report.Applications[0].File = "./apps/web.yaml"
+	report.Applications[0].PolicyViolations = []PolicyViolation{
+		{Policy: "require-limits", Resource: "Deployment/web", Namespace: "prod", File: "web/templates/deployment.yaml", Message: "containers must set memory limits"},
+	}
+	report.Applications[0].RiskFindings = []RiskFinding{
+		{Severity: RiskHigh, Rule: "privileged-container", Resource: "Deployment/web", Namespace: "prod", Message: "container app runs privileged"},
+	}
+
+	fs := afero.NewMemMapFs()
+	writer := CodeQualityReportWriter{Fs: fs, Path: "reports/gl-code-quality.json"}
+	require.NoError(t, writer.PresentRun(context.Background(), report))
+
+	data, err := afero.ReadFile(fs, "reports/gl-code-quality.json")
+	require.NoError(t, err)
+
+	var issues []codeQualityIssue
+	require.NoError(t, json.Unmarshal(data, &issues))
+	require.Len(t, issues, 4)
+
+	location := codeQualityLocation{Path: "apps/web.yaml", Lines: codeQualityLines{Begin: 1}}
+	checks := make([]string, 0, len(issues))
+	fingerprints := map[string]bool{}
+	for _, issue := range issues {
+		assert.Equal(t, location, issue.Location)
+		assert.Len(t, issue.Fingerprint, 64)
+		checks = append(checks, issue.CheckName)
+		fingerprints[issue.Fingerprint] = true
+	}
+	assert.Equal(t, []string{ruleSchemaViolation, ruleMissingSchema, checkPolicyViolation, "risk/privileged-container"}, checks)
+	assert.Len(t, fingerprints, 4, "every finding should have its own fingerprint")
+
+	assert.Equal(t, "Deployment/web rendered into web/templates/deployment.yaml by ./apps/web.yaml: spec.replicas: expected integer", issues[0].Description)
+	assert.Equal(t, "major", issues[0].Severity)
+	assert.Equal(t, "minor", issues[1].Severity)
+	assert.Equal(t, "Policy violation (require-limits): Deployment/web (web/templates/deployment.yaml): containers must set memory limits", issues[2].Description)
+	assert.Equal(t, "major", issues[2].Severity)
+	assert.Equal(t, "Risky change (privileged-container): Deployment/web: container app runs privileged", issues[3].Description)
+	assert.Equal(t, "critical", issues[3].Severity)
+}
+
+func TestCodeQualityFingerprintsAreStable(t *testing.T) {
+	first := codeQualityIssues(validationRunReport(t))
+
+	rerun := validationRunReport(t)
+	rerun.Version = "1.2.4"
+	rerun.DurationMs = 900
+	rerun.Applications[0].DurationMs = 800
+	second := codeQualityIssues(rerun)
+
+	require.Len(t, second, len(first))
+	for i := range first {
+		assert.Equal(t, first[i].Fingerprint, second[i].Fingerprint)
+	}
+
+	moved := validationRunReport(t)
+	moved.Applications[0].File = "apps/api.yaml"
+	assert.NotEqual(t, first[0].Fingerprint, codeQualityIssues(moved)[0].Fingerprint,
+		"the same finding in another Application should be a separate issue")
+}
+
+func TestCodeQualityReportWriterWithoutFindings(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writer := CodeQualityReportWriter{Fs: fs, Path: "gl-code-quality.json"}
+	require.NoError(t, writer.PresentRun(context.Background(), RunReport{Applications: []ApplicationReport{{File: "apps/web.yaml"}}}))
+
+	data, err := afero.ReadFile(fs, "gl-code-quality.json")
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", string(data))
+}
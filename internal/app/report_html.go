@@ -0,0 +1,228 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+//go:embed report_html.tmpl
+var htmlReportTemplate string
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"lineNumber": func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	},
+	"resourceName": func(resource ResourceReport) string {
+		name := resource.Kind + "/" + resource.Name
+		if resource.Namespace != "" {
+			name += " (" + resource.Namespace + ")"
+		}
+		return name
+	},
+	"seconds": junitSeconds,
+}).Parse(htmlReportTemplate))
+
+// HTMLReportWriter writes the run report as a single self-contained HTML page
+// (no external assets) with per-Application navigation, unified and
+// side-by-side diff views, search and the validation summary, so CI can
+// publish it as a job artifact.
+type HTMLReportWriter struct {
+	Fs   afero.Fs
+	Path string
+}
+
+// Diff line kinds used by the HTML report; they double as CSS classes.
+const (
+	htmlLineContext = "ctx"
+	htmlLineAdded   = "add"
+	htmlLineRemoved = "del"
+)
+
+type (
+	htmlPage struct {
+		Report           RunReport
+		Applications     []htmlApplication
+		WithDifferences  int
+		ValidationErrors int
+	}
+
+	htmlApplication struct {
+		ID         string
+		Report     ApplicationReport
+		Manifests  []htmlManifest
+		Validation []htmlValidation
+	}
+
+	htmlValidation struct {
+		Target string
+		Result ports.ValidationResult
+	}
+
+	htmlManifest struct {
+		ID        string
+		Change    string // "added", "removed" or "changed".
+		Report    ManifestReport
+		Hunks     []htmlHunk
+		Unchanged bool // The diff has no hunks to display.
+	}
+
+	htmlHunk struct {
+		Header string
+		Lines  []htmlLine
+		Rows   []htmlRow
+	}
+
+	// htmlLine is one line of a unified diff. Old and New are 1-based line
+	// numbers on each leg; zero means the line does not exist on that leg.
+	htmlLine struct {
+		Kind string
+		Old  int
+		New  int
+		Text string
+	}
+
+	// htmlRow pairs a line of the target branch with a line of the current
+	// branch for the side-by-side view. An empty Kind is a blank cell.
+	htmlRow struct {
+		Left  htmlLine
+		Right htmlLine
+	}
+)
+
+// PresentRun implements RunPresenter.
+func (w HTMLReportWriter) PresentRun(_ context.Context, report RunReport) error {
+	var buf bytes.Buffer
+	if err := htmlReport.Execute(&buf, newHTMLPage(report)); err != nil {
+		return fmt.Errorf("render HTML report: %w", err)
+	}
+	return writeReportFile(w.Fs, w.Path, buf.Bytes())
+}
+
+// newHTMLPage prepares the run report for the HTML template.
+func newHTMLPage(report RunReport) htmlPage {
+	page := htmlPage{Report: report}
+	for i, application := range report.Applications {
+		entry := htmlApplication{ID: fmt.Sprintf("app-%d", i+1), Report: application}
+		for _, group := range []struct {
+			change    string
+			manifests []ManifestReport
+		}{
+			{"added", application.Added},
+			{"removed", application.Removed},
+			{"changed", application.Changed},
+		} {
+			for _, manifest := range group.manifests {
+				hunks := parseUnifiedDiff(manifest.Diff)
+				entry.Manifests = append(entry.Manifests, htmlManifest{
+					ID:        fmt.Sprintf("%s-m%d", entry.ID, len(entry.Manifests)+1),
+					Change:    group.change,
+					Report:    manifest,
+					Hunks:     hunks,
+					Unchanged: len(hunks) == 0,
+				})
+			}
+		}
+		for _, target := range sortedValidationTargets(application.Validation) {
+			result := application.Validation[target]
+			entry.Validation = append(entry.Validation, htmlValidation{Target: target, Result: result})
+			page.ValidationErrors += result.ErrorCount
+			if result.InvocationError != "" {
+				page.ValidationErrors++
+			}
+		}
+		if application.Differences {
+			page.WithDifferences++
+		}
+		page.Applications = append(page.Applications, entry)
+	}
+	return page
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// parseUnifiedDiff splits a unified diff into hunks, numbering every line on
+// both legs. File headers and "\ No newline at end of file" markers are
+// dropped.
+func parseUnifiedDiff(diff string) []htmlHunk {
+	var hunks []htmlHunk
+	var oldLine, newLine int
+	for _, line := range strings.Split(diff, "\n") {
+		if match := hunkHeader.FindStringSubmatch(line); match != nil {
+			oldLine, _ = strconv.Atoi(match[1])
+			newLine, _ = strconv.Atoi(match[2])
+			// A leg without lines is reported as starting at line 0.
+			oldLine = max(oldLine, 1)
+			newLine = max(newLine, 1)
+			hunks = append(hunks, htmlHunk{Header: line})
+			continue
+		}
+		if len(hunks) == 0 || line == "" || strings.HasPrefix(line, `\`) {
+			continue
+		}
+
+		hunk := &hunks[len(hunks)-1]
+		text := line[1:]
+		switch line[0] {
+		case '-':
+			hunk.Lines = append(hunk.Lines, htmlLine{Kind: htmlLineRemoved, Old: oldLine, Text: text})
+			oldLine++
+		case '+':
+			hunk.Lines = append(hunk.Lines, htmlLine{Kind: htmlLineAdded, New: newLine, Text: text})
+			newLine++
+		default:
+			hunk.Lines = append(hunk.Lines, htmlLine{Kind: htmlLineContext, Old: oldLine, New: newLine, Text: text})
+			oldLine++
+			newLine++
+		}
+	}
+
+	for i := range hunks {
+		hunks[i].Rows = sideBySide(hunks[i].Lines)
+	}
+	return hunks
+}
+
+// sideBySide pairs the removed and added lines of each change block so that a
+// modified line is shown next to its replacement.
+func sideBySide(lines []htmlLine) []htmlRow {
+	var rows []htmlRow
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == htmlLineContext {
+			rows = append(rows, htmlRow{Left: lines[i], Right: lines[i]})
+			i++
+			continue
+		}
+
+		var removed, added []htmlLine
+		for ; i < len(lines) && lines[i].Kind == htmlLineRemoved; i++ {
+			removed = append(removed, lines[i])
+		}
+		for ; i < len(lines) && lines[i].Kind == htmlLineAdded; i++ {
+			added = append(added, lines[i])
+		}
+		for j := 0; j < max(len(removed), len(added)); j++ {
+			var row htmlRow
+			if j < len(removed) {
+				row.Left = removed[j]
+			}
+			if j < len(added) {
+				row.Right = added[j]
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
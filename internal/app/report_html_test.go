@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+func unifiedDiff(from, to string) string {
+	edits := myers.ComputeEdits(span.URIFromPath("a"), from, to)
+	return fmt.Sprint(gotextdiff.ToUnified("a", "b", from, edits))
+}
+
+func TestParseUnifiedDiff(t *testing.T) {
+	diff := unifiedDiff("kind: Deployment\nreplicas: 2\nimage: web:1\nport: 80\n", "kind: Deployment\nreplicas: 3\nimage: web:2\nport: 80\nextra: true")
+
+	hunks := parseUnifiedDiff(diff)
+	require.Len(t, hunks, 1)
+	assert.Equal(t, "@@ -1,4 +1,5 @@", hunks[0].Header)
+	assert.Equal(t, []htmlLine{
+		{Kind: htmlLineContext, Old: 1, New: 1, Text: "kind: Deployment"},
+		{Kind: htmlLineRemoved, Old: 2, Text: "replicas: 2"},
+		{Kind: htmlLineRemoved, Old: 3, Text: "image: web:1"},
+		{Kind: htmlLineAdded, New: 2, Text: "replicas: 3"},
+		{Kind: htmlLineAdded, New: 3, Text: "image: web:2"},
+		{Kind: htmlLineContext, Old: 4, New: 4, Text: "port: 80"},
+		{Kind: htmlLineAdded, New: 5, Text: "extra: true"},
+	}, hunks[0].Lines)
+
+	assert.Equal(t, []htmlRow{
+		{Left: hunks[0].Lines[0], Right: hunks[0].Lines[0]},
+		{Left: hunks[0].Lines[1], Right: hunks[0].Lines[3]},
+		{Left: hunks[0].Lines[2], Right: hunks[0].Lines[4]},
+		{Left: hunks[0].Lines[5], Right: hunks[0].Lines[5]},
+		{Right: hunks[0].Lines[6]},
+	}, hunks[0].Rows)
+}
+
+func TestParseUnifiedDiffOfAddedFile(t *testing.T) {
+	hunks := parseUnifiedDiff(unifiedDiff("", "a: 1\nb: 2\n"))
+	require.Len(t, hunks, 1)
+	assert.Equal(t, []htmlLine{
+		{Kind: htmlLineAdded, New: 1, Text: "a: 1"},
+		{Kind: htmlLineAdded, New: 2, Text: "b: 2"},
+	}, hunks[0].Lines)
+
+	assert.Empty(t, parseUnifiedDiff(""))
+}
+
+func TestHTMLReportWriter(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	report := RunReport{
+		Version:      "1.2.3",
+		TargetBranch: "main",
+		InvalidFiles: []string{"apps/broken.yaml"},
+		Applications: []ApplicationReport{
+			{
+				File:        "apps/web.yaml",
+				Name:        "web",
+				Differences: true,
+				Charts:      []ChartReport{{Leg: TargetTypeSource, Name: "web", Version: "2.0.0"}},
+				Changed: []ManifestReport{{
+					File:      "/web/templates/configmap.yaml",
+					Masked:    true,
+					Diff:      unifiedDiff("value: <old>\n", "value: <script>alert(1)</script>\n"),
+					Resources: []ResourceReport{{APIVersion: "v1", Kind: "ConfigMap", Namespace: "prod", Name: "settings"}},
+				}},
+				Validation: map[string]ports.ValidationResult{
+					TargetTypeSource: {Target: TargetTypeSource, ResourceCount: 2, ErrorCount: 1, Errors: []ports.ValidationError{
+						{Filename: "web/templates/deployment.yaml", Kind: "Deployment", Name: "web", Message: "spec.replicas: expected integer"},
+					}},
+				},
+			},
+			{File: "apps/api.yaml"},
+		},
+	}
+
+	writer := HTMLReportWriter{Fs: fs, Path: "out/report.html"}
+	require.NoError(t, writer.PresentRun(context.Background(), report))
+
+	data, err := afero.ReadFile(fs, "out/report.html")
+	require.NoError(t, err)
+	html := string(data)
+
+	assert.Contains(t, html, `<a href="#app-1">apps/web.yaml</a>`)
+	assert.Contains(t, html, `<a href="#app-2">apps/api.yaml</a> <span class="muted">(no changes)</span>`)
+	assert.Contains(t, html, `<details class="manifest" id="app-1-m1" open>`)
+	assert.Contains(t, html, `<span class="badge changed">changed</span><span class="badge">masked</span>`)
+	assert.Contains(t, html, "ConfigMap/settings (prod)")
+	assert.Contains(t, html, "src: web 2.0.0")
+	assert.Contains(t, html, `<td class="del">-value: &lt;old&gt;</td>`)
+	assert.Contains(t, html, `<td class="add">value: &lt;script&gt;alert(1)&lt;/script&gt;</td>`)
+	assert.NotContains(t, html, "<script>alert")
+	assert.Contains(t, html, `Deployment/web (web/templates/deployment.yaml): spec.replicas: expected integer`)
+	assert.Contains(t, html, `<tr><th>Validation errors</th><td class="bad">1</td></tr>`)
+	assert.Contains(t, html, "apps/broken.yaml")
+	assert.Contains(t, html, "No differences.")
+	assert.NotContains(t, html, `src="http`)
+	assert.NotContains(t, html, `<link`)
+}
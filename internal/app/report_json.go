@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// JSONReportWriter writes the run report as indented JSON to Path, creating
+// parent directories as needed.
+type JSONReportWriter struct {
+	Fs   afero.Fs
+	Path string
+}
+
+// PresentRun implements RunPresenter.
+func (w JSONReportWriter) PresentRun(_ context.Context, report RunReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode JSON report: %w", err)
+	}
+	return writeReportFile(w.Fs, w.Path, append(data, '\n'))
+}
+
+// writeReportFile stores a report, creating its directory first.
+func writeReportFile(fs afero.Fs, path string, data []byte) error {
+	if err := fs.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create report directory for %s: %w", path, err)
+	}
+	if err := afero.WriteFile(fs, path, data, 0o644); err != nil {
+		return fmt.Errorf("write report %s: %w", path, err)
+	}
+	return nil
+}
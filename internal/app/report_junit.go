@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+// JUnitReportWriter writes manifest validation results as JUnit XML: one test
+// suite per Application and one test case per resource rendered from the
+// current branch, failed when the validator rejected it. CI test widgets can
+// then show schema failures next to regular test results.
+type JUnitReportWriter struct {
+	Fs   afero.Fs
+	Path string
+}
+
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Errors   int              `xml:"errors,attr"`
+		Skipped  int              `xml:"skipped,attr"`
+		Time     string           `xml:"time,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name     string          `xml:"name,attr"`
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Errors   int             `xml:"errors,attr"`
+		Skipped  int             `xml:"skipped,attr"`
+		Time     string          `xml:"time,attr"`
+		Cases    []junitTestCase `xml:"testcase"`
+	}
+
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		Classname string        `xml:"classname,attr"`
+		File      string        `xml:"file,attr,omitempty"`
+		Failure   *junitProblem `xml:"failure,omitempty"`
+		Error     *junitProblem `xml:"error,omitempty"`
+		Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	}
+
+	junitProblem struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+
+	junitSkipped struct {
+		Message string `xml:"message,attr"`
+	}
+)
+
+// PresentRun implements RunPresenter.
+func (w JUnitReportWriter) PresentRun(_ context.Context, report RunReport) error {
+	suites := junitTestSuites{Name: "argo-compare", Time: junitSeconds(report.DurationMs), Suites: []junitTestSuite{}}
+	for _, application := range report.Applications {
+		suite := junitSuite(application)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode JUnit report: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	return writeReportFile(w.Fs, w.Path, append(data, '\n'))
+}
+
+// junitSuite builds the test suite of one Application. Validation errors that
+// cannot be matched to a rendered resource (for example manifests that failed
+// to parse) get a test case of their own so that no failure is lost.
+func junitSuite(application ApplicationReport) junitTestSuite {
+	suite := junitTestSuite{Name: application.File, Time: junitSeconds(application.DurationMs)}
+
+	var errs []ports.ValidationError
+	for _, target := range sortedValidationTargets(application.Validation) {
+		validation := application.Validation[target]
+		if validation.InvocationError != "" {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "manifest validation (" + target + ")",
+				Classname: application.File,
+				Error:     &junitProblem{Message: validation.InvocationError, Type: ruleValidatorError, Text: validation.InvocationError},
+			})
+		}
+		errs = append(errs, validation.Errors...)
+	}
+
+	matched := make([]bool, len(errs))
+	for _, change := range application.resources {
+		if change.New == nil {
+			continue
+		}
+		file := strings.TrimPrefix(change.New.File, "/")
+		testCase := junitTestCase{Name: change.New.DisplayName(), Classname: application.File, File: file}
+		if len(application.Validation) == 0 {
+			testCase.Skipped = &junitSkipped{Message: "manifest validation is disabled"}
+		}
+		var failures []ports.ValidationError
+		for i, validationErr := range errs {
+			if !matched[i] && validationErr.Kind == change.New.Kind && validationErr.Name == change.New.Name &&
+				(validationErr.Filename == "" || validationErr.Filename == file) {
+				matched[i] = true
+				failures = append(failures, validationErr)
+			}
+		}
+		testCase.Failure = junitFailure(failures)
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	for i, validationErr := range errs {
+		if matched[i] {
+			continue
+		}
+		name := validationErr.Filename
+		if validationErr.Kind != "" || validationErr.Name != "" {
+			name = validationErr.Kind + "/" + validationErr.Name
+		}
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      name,
+			Classname: application.File,
+			File:      validationErr.Filename,
+			Failure:   junitFailure([]ports.ValidationError{validationErr}),
+		})
+	}
+
+	for _, testCase := range suite.Cases {
+		suite.Tests++
+		switch {
+		case testCase.Error != nil:
+			suite.Errors++
+		case testCase.Failure != nil:
+			suite.Failures++
+		case testCase.Skipped != nil:
+			suite.Skipped++
+		}
+	}
+	return suite
+}
+
+// junitFailure combines the validation errors of one resource into a failure
+// element; it returns nil when there are none.
+func junitFailure(errs []ports.ValidationError) *junitProblem {
+	if len(errs) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(errs))
+	for _, validationErr := range errs {
+		messages = append(messages, validationErr.Message)
+	}
+	return &junitProblem{
+		Message: errs[0].Message,
+		Type:    validationRule(errs[0]),
+		Text:    strings.Join(messages, "\n"),
+	}
+}
+
+// junitSeconds formats a duration in milliseconds as JUnit's fractional seconds.
+func junitSeconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
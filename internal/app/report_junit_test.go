@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+func TestJUnitReportWriter(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writer := JUnitReportWriter{Fs: fs, Path: "reports/junit.xml"}
+	require.NoError(t, writer.PresentRun(context.Background(), validationRunReport(t)))
+
+	data, err := afero.ReadFile(fs, "reports/junit.xml")
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="argo-compare" tests="3" failures="2" errors="0" skipped="0" time="1.500">
+  <testsuite name="apps/web.yaml" tests="3" failures="2" errors="0" skipped="0" time="1.250">
+    <testcase name="Deployment/web" classname="apps/web.yaml" file="web/templates/deployment.yaml">
+      <failure message="spec.replicas: expected integer" type="schema-violation">spec.replicas: expected integer</failure>
+    </testcase>
+    <testcase name="ConfigMap/settings" classname="apps/web.yaml" file="web/templates/configmap.yaml"></testcase>
+    <testcase name="Widget/demo" classname="apps/web.yaml" file="web/templates/crd.yaml">
+      <failure message="could not find schema for Widget" type="missing-schema">could not find schema for Widget</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, string(data))
+}
+
+func TestJUnitSuiteWithoutValidation(t *testing.T) {
+	report := validationRunReport(t)
+	application := report.Applications[0]
+	application.Validation = nil
+
+	suite := junitSuite(application)
+	assert.Equal(t, 2, suite.Tests)
+	assert.Equal(t, 2, suite.Skipped)
+	assert.Equal(t, "manifest validation is disabled", suite.Cases[0].Skipped.Message)
+}
+
+func TestJUnitSuiteWithValidatorFailure(t *testing.T) {
+	suite := junitSuite(ApplicationReport{
+		File: "apps/web.yaml",
+		Validation: map[string]ports.ValidationResult{
+			TargetTypeSource: {Target: TargetTypeSource, InvocationError: "kubeconform exited with error"},
+		},
+	})
+
+	require.Len(t, suite.Cases, 1)
+	assert.Equal(t, 1, suite.Errors)
+	assert.Equal(t, "manifest validation (src)", suite.Cases[0].Name)
+	assert.Equal(t, ruleValidatorError, suite.Cases[0].Error.Type)
+}
+
+func TestJUnitFailureCombinesMessages(t *testing.T) {
+	assert.Nil(t, junitFailure(nil))
+
+	failure := junitFailure([]ports.ValidationError{{Message: "first"}, {Message: "second"}})
+	assert.Equal(t, &junitProblem{Message: "first", Type: ruleSchemaViolation, Text: "first\nsecond"}, failure)
+}
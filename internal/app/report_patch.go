@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// devNull is the path git uses for the missing side of an added or deleted file.
+const devNull = "/dev/null"
+
+// PatchWriter writes every changed resource of the run as a single
+// git-apply-compatible unified patch. Each resource is a file at
+// <application>/<Kind>/<name>.yaml, holding its normalised, masked manifest,
+// with the target branch as the "a/" side and the current branch as "b/".
+type PatchWriter struct {
+	Fs   afero.Fs
+	Path string
+}
+
+// PresentRun implements RunPresenter.
+func (w PatchWriter) PresentRun(_ context.Context, report RunReport) error {
+	var patch strings.Builder
+	dirs := make(applicationDirs)
+	for _, application := range report.Applications {
+		dir := dirs.claim(application.File, application.Name)
+		taken := make(map[string]bool)
+		for _, change := range application.resources {
+			if !change.IsChanged() {
+				continue
+			}
+			name, err := patchFileName(dir, change.Current(), taken)
+			if err != nil {
+				return err
+			}
+			if err := writeResourcePatch(&patch, name, change); err != nil {
+				return err
+			}
+		}
+	}
+	return writeReportFile(w.Fs, w.Path, []byte(patch.String()))
+}
+
+// patchFileName returns the patch path of a resource within an Application.
+// Resources sharing a kind and name (e.g. in different namespaces) fall back
+// to their full identity so that every path stays unique.
+func patchFileName(dir string, resource *Resource, taken map[string]bool) (string, error) {
+	name := path.Join(dir, exportPathSegment(resource.Kind), exportPathSegment(resource.Name)+".yaml")
+	if taken[name] {
+		name = path.Join(dir, exportPathSegment(resource.Kind), exportPathSegment(resource.Key())+".yaml")
+	}
+	if taken[name] {
+		return "", fmt.Errorf("duplicate resource %s in patch", resource.Key())
+	}
+	taken[name] = true
+	return name, nil
+}
+
+// writeResourcePatch appends the git diff of one resource to patch.
+func writeResourcePatch(patch *strings.Builder, name string, change ResourceChange) error {
+	from, err := patchContent(change.Old)
+	if err != nil {
+		return err
+	}
+	to, err := patchContent(change.New)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+
+	fromName, toName := "a/"+name, "b/"+name
+	fmt.Fprintf(patch, "diff --git %s %s\n", fromName, toName)
+	switch {
+	case change.Old == nil:
+		patch.WriteString("new file mode 100644\n")
+		fromName = devNull
+	case change.New == nil:
+		patch.WriteString("deleted file mode 100644\n")
+		toName = devNull
+	}
+	fmt.Fprintf(patch, "--- %s\n+++ %s\n", fromName, toName)
+
+	edits := myers.ComputeEdits(span.URIFromPath(name), from, to)
+	for _, hunk := range gotextdiff.ToUnified(fromName, toName, from, edits).Hunks {
+		writePatchHunk(patch, hunk)
+	}
+	return nil
+}
+
+// patchContent renders a resource as YAML with sorted keys; a missing
+// resource is empty.
+func patchContent(resource *Resource) (string, error) {
+	if resource == nil {
+		return "", nil
+	}
+	data, err := yaml.Marshal(resource.Object)
+	if err != nil {
+		return "", fmt.Errorf("encode %s for patch: %w", resource.DisplayName(), err)
+	}
+	return string(data), nil
+}
+
+// writePatchHunk formats a hunk with explicit line counts. gotextdiff omits
+// counts of one and numbers empty ranges from 1, which git apply reads as a
+// one-line range; git expects an empty range to start at the preceding line.
+func writePatchHunk(patch *strings.Builder, hunk *gotextdiff.Hunk) {
+	var fromCount, toCount int
+	for _, line := range hunk.Lines {
+		if line.Kind != gotextdiff.Insert {
+			fromCount++
+		}
+		if line.Kind != gotextdiff.Delete {
+			toCount++
+		}
+	}
+	fromLine, toLine := hunk.FromLine, hunk.ToLine
+	if fromCount == 0 {
+		fromLine--
+	}
+	if toCount == 0 {
+		toLine--
+	}
+
+	fmt.Fprintf(patch, "@@ -%d,%d +%d,%d @@\n", fromLine, fromCount, toLine, toCount)
+	for _, line := range hunk.Lines {
+		switch line.Kind {
+		case gotextdiff.Delete:
+			patch.WriteString("-")
+		case gotextdiff.Insert:
+			patch.WriteString("+")
+		default:
+			patch.WriteString(" ")
+		}
+		patch.WriteString(line.Content)
+		if !strings.HasSuffix(line.Content, "\n") {
+			patch.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchWriter(t *testing.T) {
+	old := parseResources("/web/all.yaml", []byte(
+		"apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: web, namespace: prod}\nspec: {replicas: 2}\n"+
+			"---\napiVersion: v1\nkind: Service\nmetadata: {name: legacy, namespace: prod}\n"+
+			"---\napiVersion: v1\nkind: ConfigMap\nmetadata: {name: same, namespace: prod}\n"))
+	current := parseResources("/web/all.yaml", []byte(
+		"apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: web, namespace: prod}\nspec: {replicas: 3}\n"+
+			"---\napiVersion: v1\nkind: ConfigMap\nmetadata: {name: web, namespace: prod}\n"+
+			"---\napiVersion: v1\nkind: ConfigMap\nmetadata: {name: same, namespace: prod}\n"))
+
+	fs := afero.NewMemMapFs()
+	writer := PatchWriter{Fs: fs, Path: "out/changes.patch"}
+	report := RunReport{Applications: []ApplicationReport{
+		{File: "apps/web.yaml", Name: "web", resources: pairResources(old, current)},
+		{File: "apps/api.yaml"},
+	}}
+	require.NoError(t, writer.PresentRun(context.Background(), report))
+
+	data, err := afero.ReadFile(fs, "out/changes.patch")
+	require.NoError(t, err)
+	assert.Equal(t, `diff --git a/web/ConfigMap/web.yaml b/web/ConfigMap/web.yaml
+new file mode 100644
+--- /dev/null
++++ b/web/ConfigMap/web.yaml
+@@ -0,0 +1,5 @@
++apiVersion: v1
++kind: ConfigMap
++metadata:
++    name: web
++    namespace: prod
+diff --git a/web/Service/legacy.yaml b/web/Service/legacy.yaml
+deleted file mode 100644
+--- a/web/Service/legacy.yaml
++++ /dev/null
+@@ -1,5 +0,0 @@
+-apiVersion: v1
+-kind: Service
+-metadata:
+-    name: legacy
+-    namespace: prod
+diff --git a/web/Deployment/web.yaml b/web/Deployment/web.yaml
+--- a/web/Deployment/web.yaml
++++ b/web/Deployment/web.yaml
+@@ -4,4 +4,4 @@
+     name: web
+     namespace: prod
+ spec:
+-    replicas: 2
++    replicas: 3
+`, string(data))
+}
+
+func TestPatchFileNameDisambiguatesDuplicates(t *testing.T) {
+	taken := make(map[string]bool)
+	first := Resource{APIVersion: "v1", Kind: "ConfigMap", Namespace: "a", Name: "settings"}
+	second := Resource{APIVersion: "v1", Kind: "ConfigMap", Namespace: "b", Name: "settings"}
+
+	name, err := patchFileName("web", &first, taken)
+	require.NoError(t, err)
+	assert.Equal(t, "web/ConfigMap/settings.yaml", name)
+
+	name, err = patchFileName("web", &second, taken)
+	require.NoError(t, err)
+	assert.Equal(t, "web/ConfigMap/_ConfigMap_b_settings.yaml", name)
+
+	_, err = patchFileName("web", &second, taken)
+	assert.ErrorContains(t, err, "duplicate resource")
+}
+
+func TestPatchWriterWithoutChanges(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, PatchWriter{Fs: fs, Path: "changes.patch"}.PresentRun(context.Background(), RunReport{}))
+
+	data, err := afero.ReadFile(fs, "changes.patch")
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
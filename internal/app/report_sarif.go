@@ -0,0 +1,216 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+const (
+	sarifVersion   = "2.1.0"
+	sarifSchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	projectURI     = "https://github.com/shini4i/argo-compare"
+)
+
+// Validation rule identifiers shared by the SARIF and JUnit reports.
+const (
+	ruleSchemaViolation = "schema-violation"
+	ruleMissingSchema   = "missing-schema"
+	ruleValidatorError  = "validator-error"
+)
+
+// validationRules describes every rule a validation finding can map to, in
+// the order they are listed in SARIF output.
+var validationRules = []sarifRule{
+	{
+		ID:               ruleSchemaViolation,
+		ShortDescription: sarifMessage{Text: "Rendered resource does not match its schema"},
+		FullDescription:  sarifMessage{Text: "kubeconform rejected a resource rendered from the current branch because it does not conform to the JSON schema of its kind."},
+		HelpURI:          projectURI + "/blob/main/docs/manifest-validation.md",
+	},
+	{
+		ID:               ruleMissingSchema,
+		ShortDescription: sarifMessage{Text: "No schema found for rendered resource"},
+		FullDescription:  sarifMessage{Text: "kubeconform could not find a schema for a rendered resource, typically a custom resource. Add a schema location or skip the kind."},
+		HelpURI:          projectURI + "/blob/main/docs/manifest-validation.md",
+	},
+	{
+		ID:               ruleValidatorError,
+		ShortDescription: sarifMessage{Text: "Manifest validator failed to run"},
+		FullDescription:  sarifMessage{Text: "The manifest validator could not be executed, so the rendered manifests were not validated."},
+		HelpURI:          projectURI + "/blob/main/docs/manifest-validation.md",
+	},
+}
+
+// validationRule classifies a validation error. kubeconform reports resources
+// without a known schema with a "could not find schema" message.
+func validationRule(validationErr ports.ValidationError) string {
+	if strings.Contains(validationErr.Message, "could not find schema") {
+		return ruleMissingSchema
+	}
+	return ruleSchemaViolation
+}
+
+// sortedValidationTargets returns the validated legs of a report in a stable order.
+func sortedValidationTargets(results map[string]ports.ValidationResult) []string {
+	targets := make([]string, 0, len(results))
+	for target := range results {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// SARIFReportWriter writes manifest validation findings as a SARIF 2.1.0 log
+// so code scanning and security widgets can display them. Findings are
+// located in the Application file they were rendered from, since rendered
+// manifests do not exist in the repository.
+type SARIFReportWriter struct {
+	Fs   afero.Fs
+	Path string
+}
+
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+		FullDescription  sarifMessage `json:"fullDescription"`
+		HelpURI          string       `json:"helpUri"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifResult struct {
+		RuleID     string            `json:"ruleId"`
+		RuleIndex  int               `json:"ruleIndex"`
+		Level      string            `json:"level"`
+		Message    sarifMessage      `json:"message"`
+		Locations  []sarifLocation   `json:"locations"`
+		Properties map[string]string `json:"properties,omitempty"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+
+	sarifLogicalLocation struct {
+		Name               string `json:"name"`
+		FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+		Kind               string `json:"kind"`
+	}
+)
+
+// PresentRun implements RunPresenter.
+func (w SARIFReportWriter) PresentRun(_ context.Context, report RunReport) error {
+	log := sarifLog{
+		Schema:  sarifSchemaURI,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "argo-compare",
+				Version:        report.Version,
+				InformationURI: projectURI,
+				Rules:          validationRules,
+			}},
+			Results: sarifResults(report),
+		}},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode SARIF report: %w", err)
+	}
+	return writeReportFile(w.Fs, w.Path, append(data, '\n'))
+}
+
+// sarifResults converts every validation finding of the run into a result.
+func sarifResults(report RunReport) []sarifResult {
+	results := []sarifResult{}
+	for _, application := range report.Applications {
+		for _, target := range sortedValidationTargets(application.Validation) {
+			validation := application.Validation[target]
+			if validation.InvocationError != "" {
+				results = append(results, newSARIFResult(ruleValidatorError, application.File,
+					fmt.Sprintf("Manifest validation of %s could not run: %s", application.File, validation.InvocationError), nil))
+			}
+			for _, validationErr := range validation.Errors {
+				resource := validationErr.Kind + "/" + validationErr.Name
+				message := fmt.Sprintf("%s rendered into %s by %s: %s", resource, validationErr.Filename, application.File, validationErr.Message)
+				result := newSARIFResult(validationRule(validationErr), application.File, message, []sarifLogicalLocation{{
+					Name:               resource,
+					FullyQualifiedName: validationErr.Filename + "#" + resource,
+					Kind:               "resource",
+				}})
+				result.Properties = map[string]string{"renderedManifest": validationErr.Filename, "target": target}
+				results = append(results, result)
+			}
+		}
+	}
+	return results
+}
+
+func newSARIFResult(ruleID, file, message string, logical []sarifLogicalLocation) sarifResult {
+	index := 0
+	for i, rule := range validationRules {
+		if rule.ID == ruleID {
+			index = i
+		}
+	}
+	return sarifResult{
+		RuleID:    ruleID,
+		RuleIndex: index,
+		Level:     "error",
+		Message:   sarifMessage{Text: message},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: strings.TrimPrefix(file, "./")},
+				Region:           sarifRegion{StartLine: 1},
+			},
+			LogicalLocations: logical,
+		}},
+	}
+}
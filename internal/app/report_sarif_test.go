@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+// validationRunReport returns a run with one Application whose Deployment
+// failed validation, a ConfigMap that passed and a CRD without a schema.
+func validationRunReport(t *testing.T) RunReport {
+	t.Helper()
+
+	deployment := riskChange(t, "", "apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: web, namespace: prod}\n")
+	deployment.New.File = "/web/templates/deployment.yaml"
+	configMap := riskChange(t, "", "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: settings, namespace: prod}\n")
+	configMap.New.File = "/web/templates/configmap.yaml"
+	removed := riskChange(t, "apiVersion: v1\nkind: Service\nmetadata: {name: old}\n", "")
+
+	return RunReport{
+		Version:    "1.2.3",
+		DurationMs: 1500,
+		Applications: []ApplicationReport{{
+			File:       "apps/web.yaml",
+			DurationMs: 1250,
+			Validation: map[string]ports.ValidationResult{
+				TargetTypeSource: {
+					Target: TargetTypeSource, ResourceCount: 3, ErrorCount: 2,
+					Errors: []ports.ValidationError{
+						{Filename: "web/templates/deployment.yaml", Kind: "Deployment", Name: "web", Message: "spec.replicas: expected integer"},
+						{Filename: "web/templates/crd.yaml", Kind: "Widget", Name: "demo", Message: "could not find schema for Widget"},
+					},
+				},
+			},
+			resources: []ResourceChange{deployment, configMap, removed},
+		}},
+	}
+}
+
+func TestSARIFReportWriter(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writer := SARIFReportWriter{Fs: fs, Path: "reports/validation.sarif"}
+	require.NoError(t, writer.PresentRun(context.Background(), validationRunReport(t)))
+
+	data, err := afero.ReadFile(fs, "reports/validation.sarif")
+	require.NoError(t, err)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(data, &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Equal(t, sarifSchemaURI, log.Schema)
+	require.Len(t, log.Runs, 1)
+
+	driver := log.Runs[0].Tool.Driver
+	assert.Equal(t, "argo-compare", driver.Name)
+	assert.Equal(t, "1.2.3", driver.Version)
+	require.Len(t, driver.Rules, 3)
+
+	results := log.Runs[0].Results
+	require.Len(t, results, 2)
+	assert.Equal(t, sarifResult{
+		RuleID:    ruleSchemaViolation,
+		RuleIndex: 0,
+		Level:     "error",
+		Message:   sarifMessage{Text: "Deployment/web rendered into web/templates/deployment.yaml by apps/web.yaml: spec.replicas: expected integer"},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "apps/web.yaml"},
+				Region:           sarifRegion{StartLine: 1},
+			},
+			LogicalLocations: []sarifLogicalLocation{{
+				Name:               "Deployment/web",
+				FullyQualifiedName: "web/templates/deployment.yaml#Deployment/web",
+				Kind:               "resource",
+			}},
+		}},
+		Properties: map[string]string{"renderedManifest": "web/templates/deployment.yaml", "target": "src"},
+	}, results[0])
+	assert.Equal(t, ruleMissingSchema, results[1].RuleID)
+	assert.Equal(t, 1, results[1].RuleIndex)
+}
+
+func TestSARIFReportWriterReportsValidatorFailures(t *testing.T) {
+	report := RunReport{Applications: []ApplicationReport{
+		{File: "./apps/web.yaml", Validation: map[string]ports.ValidationResult{
+			TargetTypeSource: {Target: TargetTypeSource, InvocationError: "kubeconform: executable file not found"},
+		}},
+		{File: "apps/unvalidated.yaml"},
+	}}
+
+	results := sarifResults(report)
+	require.Len(t, results, 1)
+	assert.Equal(t, ruleValidatorError, results[0].RuleID)
+	assert.Equal(t, 2, results[0].RuleIndex)
+	assert.Equal(t, "apps/web.yaml", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Contains(t, results[0].Message.Text, "executable file not found")
+
+	assert.Empty(t, sarifResults(RunReport{}))
+	assert.NotNil(t, sarifResults(RunReport{}), "SARIF requires a results array")
+}
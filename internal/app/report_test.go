@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shini4i/argo-compare/internal/models"
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+// fixClock makes report timestamps advance by step on every reading.
+func fixClock(t *testing.T, start time.Time, step time.Duration) {
+	t.Helper()
+	current := start
+	now = func() time.Time {
+		reading := current
+		current = current.Add(step)
+		return reading
+	}
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestRecordReportIsDisabledWithoutRunPresenters(t *testing.T) {
+	a := &App{fs: afero.NewMemMapFs()}
+	a.recordReport(ApplicationReport{File: "apps/web.yaml"}, ComparisonResult{}, "/tmp/run", models.Application{}, models.Application{}, time.Now())
+	assert.Empty(t, a.reports)
+	assert.NoError(t, a.presentRun(context.Background(), time.Now(), nil))
+}
+
+func TestPresentRunWritesJSONReport(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fixClock(t, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), 250*time.Millisecond)
+
+	cfg := Config{TargetBranch: "main", Version: "1.2.3", MaskingRulesFile: "rules.yaml", ReportJSON: "out/report.json"}
+	a := &App{cfg: cfg, fs: fs, runPresenters: selectRunPresenters(cfg, fs)}
+	require.NoError(t, afero.WriteFile(fs, "/tmp/run/charts/src/web/Chart.yaml", []byte("name: web\nversion: 2.0.0\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/tmp/run/charts/dst/web/Chart.yaml", []byte("version: [broken"), 0o644))
+
+	application := models.Application{}
+	application.Metadata.Name = "web"
+	application.Spec.Source = &models.Source{RepoURL: "https://charts.example.com", Chart: "web", TargetRevision: "2.0.0"}
+	targetApp := models.Application{}
+	targetApp.Spec.Source = &models.Source{RepoURL: "https://charts.example.com", Chart: "web", TargetRevision: "1.0.0"}
+
+	oldCM := riskChange(t, "", "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: settings, namespace: prod}\n").New
+	oldCM.File = "/web/configmap.yaml"
+	newDeployment := riskChange(t, "", "apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: web, namespace: prod}\n").New
+	newDeployment.File = "/web/deployment.yaml"
+	result := ComparisonResult{
+		Added:             []DiffOutput{{File: File{Name: "/web/deployment.yaml"}, Diff: "+kind: Deployment"}},
+		Removed:           []DiffOutput{{File: File{Name: "/web/configmap.yaml"}, Diff: "-kind: ConfigMap", Masked: true}},
+		Resources:         []ResourceChange{{Old: oldCM}, {New: newDeployment}},
+		ValidationResults: map[string]ports.ValidationResult{"src": {Target: "src", Valid: true, ResourceCount: 1}},
+		Findings:          []RiskFinding{{Severity: RiskHigh, Rule: "scale-to-zero", Resource: "Deployment/web", Message: "scaled"}},
+	}
+
+	started := now()
+	a.recordReport(ApplicationReport{File: "apps/web.yaml"}, result, "/tmp/run", application, targetApp, now())
+	require.NoError(t, a.presentRun(context.Background(), started, []string{"apps/broken.yaml"}))
+
+	data, err := afero.ReadFile(fs, "out/report.json")
+	require.NoError(t, err)
+
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, "1.2.3", report["version"])
+	assert.Equal(t, "main", report["targetBranch"])
+	assert.Equal(t, "2026-10-01T12:00:00Z", report["startedAt"])
+	assert.EqualValues(t, 750, report["durationMs"])
+	assert.Equal(t, map[string]any{"keyConfigured": false, "rulesFile": "rules.yaml"}, report["masking"])
+	assert.Equal(t, []any{"apps/broken.yaml"}, report["invalidFiles"])
+
+	applications := report["applications"].([]any)
+	require.Len(t, applications, 1)
+	entry := applications[0].(map[string]any)
+	assert.Equal(t, "apps/web.yaml", entry["file"])
+	assert.Equal(t, "web", entry["name"])
+	assert.NotContains(t, entry, "anchor")
+	assert.EqualValues(t, 250, entry["durationMs"])
+	assert.Equal(t, true, entry["differences"])
+	assert.Equal(t, []any{
+		map[string]any{"leg": "src", "name": "web", "repoURL": "https://charts.example.com", "targetRevision": "2.0.0", "version": "2.0.0"},
+		map[string]any{"leg": "dst", "name": "web", "repoURL": "https://charts.example.com", "targetRevision": "1.0.0"},
+	}, entry["charts"])
+	assert.Equal(t, []any{map[string]any{
+		"file": "/web/deployment.yaml", "masked": false, "diff": "+kind: Deployment",
+		"resources": []any{map[string]any{"apiVersion": "apps/v1", "kind": "Deployment", "namespace": "prod", "name": "web"}},
+	}}, entry["added"])
+	assert.Equal(t, []any{map[string]any{
+		"file": "/web/configmap.yaml", "masked": true, "diff": "-kind: ConfigMap",
+		"resources": []any{map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "namespace": "prod", "name": "settings"}},
+	}}, entry["removed"])
+	assert.Equal(t, []any{}, entry["changed"])
+	assert.Equal(t, map[string]any{"src": map[string]any{"target": "src", "valid": true, "resourceCount": float64(1), "errorCount": float64(0)}}, entry["validation"])
+	assert.Equal(t, []any{map[string]any{
+		"severity": "high", "rule": "scale-to-zero", "resource": "Deployment/web", "message": "scaled",
+	}}, entry["riskFindings"])
+}
+
+func TestPresentRunWithoutApplications(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cfg := Config{ReportJSON: "report.json"}
+	a := &App{cfg: cfg, fs: fs, runPresenters: selectRunPresenters(cfg, fs)}
+
+	require.NoError(t, a.presentRun(context.Background(), time.Now(), nil))
+
+	data, err := afero.ReadFile(fs, "report.json")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"applications": []`)
+	assert.NotContains(t, string(data), "invalidFiles")
+}
+
+// runPresenterFunc adapts a function to RunPresenter.
+type runPresenterFunc func(ctx context.Context, report RunReport) error
+
+func (f runPresenterFunc) PresentRun(ctx context.Context, report RunReport) error {
+	return f(ctx, report)
+}
+
+func TestPresentRunRunsEveryPresenter(t *testing.T) {
+	var called []string
+	presenter := func(name string, err error) RunPresenter {
+		return runPresenterFunc(func(context.Context, RunReport) error {
+			called = append(called, name)
+			return err
+		})
+	}
+	labelsErr := errors.New("update merge request labels: 403 Forbidden")
+	webhookErr := errors.New("send webhook: 502 Bad Gateway")
+
+	a := &App{runPresenters: []RunPresenter{
+		presenter("labels", labelsErr),
+		presenter("json", nil),
+		presenter("webhook", webhookErr),
+	}}
+	err := a.presentRun(context.Background(), time.Now(), nil)
+
+	assert.Equal(t, []string{"labels", "json", "webhook"}, called)
+	require.ErrorIs(t, err, labelsErr)
+	require.ErrorIs(t, err, webhookErr)
+}
+
+func TestRecordReportForAnchorFlow(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cfg := Config{ReportJSON: "report.json"}
+	a := &App{cfg: cfg, fs: fs, runPresenters: selectRunPresenters(cfg, fs)}
+
+	application := models.Application{}
+	application.Spec.Source = &models.Source{RepoURL: "https://git.example.com/charts.git", Path: "charts/web/", TargetRevision: "HEAD"}
+	anchorReport := &AnchorReport{Dir: "charts/web", Path: "apps/web.yaml", ChangedFiles: []string{"charts/web/values.yaml"}}
+
+	a.recordReport(ApplicationReport{File: "apps/web.yaml", Anchor: anchorReport}, ComparisonResult{}, "/tmp/run", application, application, time.Now())
+
+	require.Len(t, a.reports, 1)
+	assert.Same(t, anchorReport, a.reports[0].Anchor)
+	assert.False(t, a.reports[0].Differences)
+	assert.Equal(t, []ChartReport{
+		{Leg: TargetTypeSource, Name: "web", RepoURL: "https://git.example.com/charts.git", Path: "charts/web/", TargetRevision: "HEAD"},
+		{Leg: TargetTypeDestination, Name: "web", RepoURL: "https://git.example.com/charts.git", Path: "charts/web/", TargetRevision: "HEAD"},
+	}, a.reports[0].Charts)
+}
+
+func TestJSONReportWriterSurfacesWriteErrors(t *testing.T) {
+	writer := JSONReportWriter{Fs: afero.NewReadOnlyFs(afero.NewMemMapFs()), Path: "out/report.json"}
+	err := writer.PresentRun(context.Background(), RunReport{})
+	assert.ErrorContains(t, err, "out/report.json")
+}
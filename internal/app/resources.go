@@ -0,0 +1,161 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Resource is a single Kubernetes object parsed from a rendered manifest.
+// Object holds the decoded document after sensitive-data masking, so it is
+// safe to surface in any presenter.
+type Resource struct {
+	APIVersion string
+	Kind       string
+	Name       string
+	Namespace  string
+	File       string // Manifest path relative to the leg's templates directory.
+	Object     map[string]any
+}
+
+// Key identifies a resource independently of the file it was rendered into:
+// API group, kind, namespace and name. The API version is deliberately left
+// out so that a version bump shows up as a change rather than a remove/add pair.
+func (r Resource) Key() string {
+	return fmt.Sprintf("%s/%s/%s/%s", apiGroup(r.APIVersion), r.Kind, r.Namespace, r.Name)
+}
+
+// DisplayName renders the resource as Kind/name for presenter output.
+func (r Resource) DisplayName() string {
+	return r.Kind + "/" + r.Name
+}
+
+// ResourceChange pairs the state of one resource on both legs of a
+// comparison. Old is nil for resources added by the change, New is nil for
+// resources it removes.
+type ResourceChange struct {
+	Old *Resource // Target branch (destination) state.
+	New *Resource // Current branch (source) state.
+}
+
+// Current returns whichever side of the change exists, preferring the new one.
+func (c ResourceChange) Current() *Resource {
+	if c.New != nil {
+		return c.New
+	}
+	return c.Old
+}
+
+// IsChanged reports whether the resource was added, removed or modified.
+func (c ResourceChange) IsChanged() bool {
+	if c.Old == nil || c.New == nil {
+		return c.Old != c.New
+	}
+	return !reflect.DeepEqual(c.Old.Object, c.New.Object)
+}
+
+// apiGroup strips the version from an apiVersion ("apps/v1" → "apps", "v1" → "").
+func apiGroup(apiVersion string) string {
+	if idx := strings.LastIndex(apiVersion, "/"); idx >= 0 {
+		return apiVersion[:idx]
+	}
+	return ""
+}
+
+// parseResources decodes every Kubernetes object in a multi-document manifest.
+// Empty documents and documents without a kind are skipped. Decoding stops at
+// the first malformed document: the textual diff still covers the file, so a
+// template that renders invalid YAML should not abort the whole comparison.
+func parseResources(file string, content []byte) []Resource {
+	var resources []Resource
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var object map[string]any
+		if err := decoder.Decode(&object); err != nil {
+			break
+		}
+
+		kind, _ := object["kind"].(string)
+		if kind == "" {
+			continue
+		}
+		apiVersion, _ := object["apiVersion"].(string)
+		metadata, _ := object["metadata"].(map[string]any)
+		name, _ := metadata["name"].(string)
+		namespace, _ := metadata["namespace"].(string)
+
+		resources = append(resources, Resource{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Name:       name,
+			Namespace:  namespace,
+			File:       file,
+			Object:     object,
+		})
+	}
+
+	return resources
+}
+
+// pairResources matches resources from both legs by Key and returns one
+// ResourceChange per distinct resource, sorted by key. When a key occurs more
+// than once on a leg (e.g. a chart renders duplicates), the last one wins.
+func pairResources(oldResources, newResources []Resource) []ResourceChange {
+	byKey := make(map[string]*ResourceChange)
+	var keys []string
+
+	entry := func(key string) *ResourceChange {
+		change, ok := byKey[key]
+		if !ok {
+			change = &ResourceChange{}
+			byKey[key] = change
+			keys = append(keys, key)
+		}
+		return change
+	}
+
+	for i := range oldResources {
+		entry(oldResources[i].Key()).Old = &oldResources[i]
+	}
+	for i := range newResources {
+		entry(newResources[i].Key()).New = &newResources[i]
+	}
+
+	sort.Strings(keys)
+	changes := make([]ResourceChange, 0, len(keys))
+	for _, key := range keys {
+		changes = append(changes, *byKey[key])
+	}
+	return changes
+}
+
+// nestedMap walks obj along path and returns the mapping found there, if any.
+func nestedMap(obj map[string]any, path ...string) (map[string]any, bool) {
+	current := obj
+	for _, key := range path {
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	return current, true
+}
+
+// nestedSlice returns the list at path within obj, if any.
+func nestedSlice(obj map[string]any, path ...string) []any {
+	if len(path) == 0 {
+		return nil
+	}
+	parent, ok := nestedMap(obj, path[:len(path)-1]...)
+	if !ok {
+		return nil
+	}
+	items, _ := parent[path[len(path)-1]].([]any)
+	return items
+}
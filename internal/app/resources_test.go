@@ -0,0 +1,76 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResourcesSkipsEmptyAndKindlessDocuments(t *testing.T) {
+	content := []byte(`---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+---
+# only a comment
+---
+foo: bar
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+
+	resources := parseResources("/chart/templates/web.yaml", content)
+	require.Len(t, resources, 2)
+
+	assert.Equal(t, "Deployment", resources[0].Kind)
+	assert.Equal(t, "web", resources[0].Name)
+	assert.Equal(t, "prod", resources[0].Namespace)
+	assert.Equal(t, "/chart/templates/web.yaml", resources[0].File)
+	assert.Equal(t, "apps/Deployment/prod/web", resources[0].Key())
+	assert.Equal(t, "/Service//web", resources[1].Key())
+	assert.Equal(t, "Service/web", resources[1].DisplayName())
+}
+
+func TestParseResourcesStopsAtMalformedDocument(t *testing.T) {
+	content := []byte("kind: ConfigMap\nmetadata:\n  name: ok\n---\nkind: [unclosed\n---\nkind: Secret\n")
+
+	resources := parseResources("bad.yaml", content)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "ok", resources[0].Name)
+}
+
+func TestPairResourcesMatchesAcrossLegsIgnoringVersion(t *testing.T) {
+	oldResources := []Resource{
+		{APIVersion: "autoscaling/v1", Kind: "HorizontalPodAutoscaler", Name: "web", Object: map[string]any{"v": 1}},
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "gone"},
+		{APIVersion: "v1", Kind: "Service", Name: "same", Object: map[string]any{"a": "b"}},
+	}
+	newResources := []Resource{
+		{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler", Name: "web", Object: map[string]any{"v": 2}},
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "fresh"},
+		{APIVersion: "v1", Kind: "Service", Name: "same", Object: map[string]any{"a": "b"}},
+	}
+
+	changes := pairResources(oldResources, newResources)
+	require.Len(t, changes, 4)
+
+	byName := make(map[string]ResourceChange)
+	for _, change := range changes {
+		byName[change.Current().Name] = change
+	}
+
+	assert.Nil(t, byName["fresh"].Old)
+	assert.True(t, byName["fresh"].IsChanged())
+	assert.Nil(t, byName["gone"].New)
+	assert.True(t, byName["gone"].IsChanged())
+	assert.Equal(t, "autoscaling/v2", byName["web"].New.APIVersion)
+	assert.Equal(t, "autoscaling/v1", byName["web"].Old.APIVersion)
+	assert.True(t, byName["web"].IsChanged())
+	assert.False(t, byName["same"].IsChanged())
+}
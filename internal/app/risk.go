@@ -0,0 +1,484 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrRiskThresholdExceeded indicates that at least one risk finding met the
+// severity configured via WithFailOnRisk. Like ErrManifestValidationFailed it
+// is returned at the end of Run, after every diff and comment has been emitted.
+var ErrRiskThresholdExceeded = errors.New("risk threshold exceeded")
+
+// RiskSeverity ranks risk findings. The zero value means "no severity" and is
+// used to disable the --fail-on-risk gate.
+type RiskSeverity int
+
+// Risk severities in ascending order.
+const (
+	RiskLow RiskSeverity = iota + 1
+	RiskMedium
+	RiskHigh
+	RiskCritical
+)
+
+var riskSeverityNames = map[RiskSeverity]string{
+	RiskLow:      "low",
+	RiskMedium:   "medium",
+	RiskHigh:     "high",
+	RiskCritical: "critical",
+}
+
+// String returns the lower-case severity name.
+func (s RiskSeverity) String() string {
+	if name, ok := riskSeverityNames[s]; ok {
+		return name
+	}
+	return "none"
+}
+
+// MarshalText encodes the severity by name in machine-readable reports.
+func (s RiskSeverity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseRiskSeverity converts a severity name (case-insensitive) into a
+// RiskSeverity. An empty string yields the zero value.
+func ParseRiskSeverity(name string) (RiskSeverity, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return 0, nil
+	}
+	for severity, candidate := range riskSeverityNames {
+		if candidate == name {
+			return severity, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown risk severity %q (expected low, medium, high or critical)", name)
+}
+
+// RiskFinding is a single risky change detected in a compared resource.
+type RiskFinding struct {
+	Severity  RiskSeverity `json:"severity"`
+	Rule      string       `json:"rule"`     // Stable identifier of the rule that produced the finding.
+	Resource  string       `json:"resource"` // Kind/name of the affected resource.
+	Namespace string       `json:"namespace,omitempty"`
+	Message   string       `json:"message"`
+}
+
+// riskRule inspects one changed resource and reports any findings.
+type riskRule func(change ResourceChange) []RiskFinding
+
+// riskRules is the built-in rule set, evaluated against every changed resource.
+var riskRules = []riskRule{
+	scaleToZeroRule,
+	deletionRule,
+	persistentVolumeRule,
+	immutableFieldRule,
+	rbacWideningRule,
+	privilegedPodRule,
+}
+
+// assessRisks runs every risk rule over the changed resources and returns the
+// findings ordered by descending severity, then by resource.
+func assessRisks(changes []ResourceChange) []RiskFinding {
+	var findings []RiskFinding
+	for _, change := range changes {
+		if !change.IsChanged() {
+			continue
+		}
+		for _, rule := range riskRules {
+			findings = append(findings, rule(change)...)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.Rule < b.Rule
+	})
+	return findings
+}
+
+// highestRisk returns the most severe finding's severity, or zero when there are none.
+func highestRisk(findings []RiskFinding) RiskSeverity {
+	var highest RiskSeverity
+	for _, finding := range findings {
+		if finding.Severity > highest {
+			highest = finding.Severity
+		}
+	}
+	return highest
+}
+
+// newFinding builds a finding for the current side of change.
+func newFinding(change ResourceChange, severity RiskSeverity, rule, format string, args ...any) RiskFinding {
+	current := change.Current()
+	return RiskFinding{
+		Severity:  severity,
+		Rule:      rule,
+		Resource:  current.DisplayName(),
+		Namespace: current.Namespace,
+		Message:   fmt.Sprintf(format, args...),
+	}
+}
+
+// scalableKinds lists workloads whose spec.replicas can take an application offline.
+var scalableKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"ReplicaSet":  true,
+	"Rollout":     true,
+}
+
+// scaleToZeroRule flags workloads scaled down to zero replicas. An omitted
+// replicas field defaults to 1 in Kubernetes.
+func scaleToZeroRule(change ResourceChange) []RiskFinding {
+	if change.Old == nil || change.New == nil || !scalableKinds[change.New.Kind] {
+		return nil
+	}
+
+	newReplicas, ok := intField(change.New.Object, "spec", "replicas")
+	if !ok || newReplicas != 0 {
+		return nil
+	}
+	oldReplicas, ok := intField(change.Old.Object, "spec", "replicas")
+	if !ok {
+		oldReplicas = 1
+	}
+	if oldReplicas == 0 {
+		return nil
+	}
+	return []RiskFinding{newFinding(change, RiskHigh, "scale-to-zero", "replicas scaled from %d to 0", oldReplicas)}
+}
+
+// deletionRule flags deletion of resources whose removal cascades: deleting a
+// Namespace removes everything in it, deleting a CRD removes every custom resource of that type.
+func deletionRule(change ResourceChange) []RiskFinding {
+	if change.New != nil {
+		return nil
+	}
+	switch change.Old.Kind {
+	case "Namespace":
+		return []RiskFinding{newFinding(change, RiskCritical, "namespace-deletion", "Namespace is deleted, together with every resource it contains")}
+	case "CustomResourceDefinition":
+		return []RiskFinding{newFinding(change, RiskCritical, "crd-deletion", "CRD is deleted, together with every custom resource of this type")}
+	default:
+		return nil
+	}
+}
+
+// persistentVolumeRule flags changes that endanger persistent data: deleting
+// or modifying a PVC, and changing a StatefulSet's volumeClaimTemplates.
+func persistentVolumeRule(change ResourceChange) []RiskFinding {
+	current := change.Current()
+	switch current.Kind {
+	case "PersistentVolumeClaim":
+		if change.New == nil {
+			return []RiskFinding{newFinding(change, RiskCritical, "pvc-change", "PersistentVolumeClaim is deleted; the bound volume may be released and its data lost")}
+		}
+		if change.Old != nil && !reflect.DeepEqual(change.Old.Object["spec"], change.New.Object["spec"]) {
+			return []RiskFinding{newFinding(change, RiskHigh, "pvc-change", "PersistentVolumeClaim spec changes; most fields are immutable once the claim is bound")}
+		}
+	case "StatefulSet":
+		if change.Old == nil || change.New == nil {
+			return nil
+		}
+		oldTemplates := nestedSlice(change.Old.Object, "spec", "volumeClaimTemplates")
+		newTemplates := nestedSlice(change.New.Object, "spec", "volumeClaimTemplates")
+		if !reflect.DeepEqual(oldTemplates, newTemplates) {
+			return []RiskFinding{newFinding(change, RiskHigh, "pvc-change", "volumeClaimTemplates change; they are immutable and the StatefulSet must be recreated")}
+		}
+	}
+	return nil
+}
+
+// immutableFields lists, per kind, fields the API server refuses to update.
+// A change to one of them makes the sync fail unless the resource is replaced.
+var immutableFields = map[string][][]string{
+	"Service":     {{"spec", "clusterIP"}},
+	"Job":         {{"spec", "selector"}, {"spec", "template"}},
+	"Deployment":  {{"spec", "selector"}},
+	"StatefulSet": {{"spec", "selector"}, {"spec", "serviceName"}},
+	"DaemonSet":   {{"spec", "selector"}},
+	"ReplicaSet":  {{"spec", "selector"}},
+}
+
+// immutableFieldRule flags changes to immutable fields present on both sides.
+func immutableFieldRule(change ResourceChange) []RiskFinding {
+	if change.Old == nil || change.New == nil {
+		return nil
+	}
+
+	var findings []RiskFinding
+	for _, path := range immutableFields[change.New.Kind] {
+		oldValue, oldOK := nestedValue(change.Old.Object, path...)
+		newValue, newOK := nestedValue(change.New.Object, path...)
+		if !oldOK || !newOK || reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		findings = append(findings, newFinding(change, RiskHigh, "immutable-field",
+			"immutable field %s changes; the sync will fail unless the resource is replaced", strings.Join(path, ".")))
+	}
+	return findings
+}
+
+// escalatingVerbs grant the ability to obtain further privileges.
+var escalatingVerbs = map[string]bool{"*": true, "escalate": true, "bind": true, "impersonate": true}
+
+// rbacWideningRule flags Roles and ClusterRoles that gain permissions, and
+// bindings that gain subjects or start granting cluster-admin.
+func rbacWideningRule(change ResourceChange) []RiskFinding {
+	if change.New == nil {
+		return nil
+	}
+
+	switch change.New.Kind {
+	case "Role", "ClusterRole":
+		return roleWidening(change)
+	case "RoleBinding", "ClusterRoleBinding":
+		return bindingWidening(change)
+	default:
+		return nil
+	}
+}
+
+// roleWidening compares the permissions granted on both sides. Newly granted
+// wildcard or escalating permissions are high risk; other additions to an
+// existing role are medium. New roles are only reported for wildcard grants.
+func roleWidening(change ResourceChange) []RiskFinding {
+	var oldPermissions map[string]bool
+	if change.Old != nil {
+		oldPermissions = rolePermissions(change.Old.Object)
+	}
+
+	var wildcard, added []string
+	for permission := range rolePermissions(change.New.Object) {
+		if oldPermissions[permission] {
+			continue
+		}
+		if isWildcardPermission(permission) {
+			wildcard = append(wildcard, permission)
+		} else if change.Old != nil {
+			added = append(added, permission)
+		}
+	}
+
+	var findings []RiskFinding
+	if len(wildcard) > 0 {
+		sort.Strings(wildcard)
+		findings = append(findings, newFinding(change, RiskHigh, "rbac-widening",
+			"grants wildcard or escalating permissions: %s", summarizeList(wildcard)))
+	}
+	if len(added) > 0 {
+		sort.Strings(added)
+		findings = append(findings, newFinding(change, RiskMedium, "rbac-widening",
+			"grants additional permissions: %s", summarizeList(added)))
+	}
+	return findings
+}
+
+// rolePermissions flattens a role's rules into "verb apiGroup/resource" strings.
+func rolePermissions(object map[string]any) map[string]bool {
+	permissions := make(map[string]bool)
+	rules, _ := object["rules"].([]any)
+	for _, item := range rules {
+		rule, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		groups := stringList(rule["apiGroups"])
+		if len(groups) == 0 {
+			groups = []string{""}
+		}
+		resources := append(stringList(rule["resources"]), stringList(rule["nonResourceURLs"])...)
+		for _, verb := range stringList(rule["verbs"]) {
+			for _, group := range groups {
+				for _, resource := range resources {
+					permissions[fmt.Sprintf("%s %s/%s", verb, group, resource)] = true
+				}
+			}
+		}
+	}
+	return permissions
+}
+
+// isWildcardPermission reports whether a flattened permission uses a wildcard
+// or an escalating verb.
+func isWildcardPermission(permission string) bool {
+	verb, target, _ := strings.Cut(permission, " ")
+	return escalatingVerbs[verb] || strings.Contains(target, "*")
+}
+
+// bindingWidening flags bindings that newly reference cluster-admin or that
+// grant their role to additional subjects.
+func bindingWidening(change ResourceChange) []RiskFinding {
+	newRole, _ := nestedValue(change.New.Object, "roleRef", "name")
+	var oldRole any
+	if change.Old != nil {
+		oldRole, _ = nestedValue(change.Old.Object, "roleRef", "name")
+	}
+	if newRole == "cluster-admin" && oldRole != "cluster-admin" {
+		return []RiskFinding{newFinding(change, RiskHigh, "rbac-widening", "binds the cluster-admin ClusterRole")}
+	}
+	if change.Old == nil {
+		return nil
+	}
+
+	oldSubjects := make(map[string]bool)
+	for _, subject := range bindingSubjects(change.Old.Object) {
+		oldSubjects[subject] = true
+	}
+	var added []string
+	for _, subject := range bindingSubjects(change.New.Object) {
+		if !oldSubjects[subject] {
+			added = append(added, subject)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	return []RiskFinding{newFinding(change, RiskMedium, "rbac-widening",
+		"grants %v to additional subjects: %s", newRole, summarizeList(added))}
+}
+
+// bindingSubjects renders a binding's subjects as Kind/namespace/name strings.
+func bindingSubjects(object map[string]any) []string {
+	var subjects []string
+	items, _ := object["subjects"].([]any)
+	for _, item := range items {
+		subject, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		kind, _ := subject["kind"].(string)
+		namespace, _ := subject["namespace"].(string)
+		name, _ := subject["name"].(string)
+		subjects = append(subjects, strings.Join([]string{kind, namespace, name}, "/"))
+	}
+	return subjects
+}
+
+// privilegedPodRule flags workloads that newly share host namespaces or run
+// privileged containers.
+func privilegedPodRule(change ResourceChange) []RiskFinding {
+	if change.New == nil {
+		return nil
+	}
+	specPath, ok := podSpecPaths[change.New.Kind]
+	if !ok {
+		return nil
+	}
+
+	newSpec, _ := nestedMap(change.New.Object, specPath...)
+	var oldSpec map[string]any
+	if change.Old != nil {
+		oldSpec, _ = nestedMap(change.Old.Object, specPath...)
+	}
+
+	var findings []RiskFinding
+	for _, field := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		if newSpec[field] == true && oldSpec[field] != true {
+			findings = append(findings, newFinding(change, RiskHigh, "privileged-pod", "%s enabled", field))
+		}
+	}
+
+	oldPrivileged := privilegedContainers(oldSpec)
+	var containers []string
+	for name := range privilegedContainers(newSpec) {
+		if !oldPrivileged[name] {
+			containers = append(containers, name)
+		}
+	}
+	if len(containers) > 0 {
+		sort.Strings(containers)
+		findings = append(findings, newFinding(change, RiskHigh, "privileged-pod",
+			"privileged mode enabled for container(s) %s", strings.Join(containers, ", ")))
+	}
+	return findings
+}
+
+// privilegedContainers returns the names of containers and init containers
+// running with securityContext.privileged.
+func privilegedContainers(spec map[string]any) map[string]bool {
+	privileged := make(map[string]bool)
+	for _, field := range []string{"initContainers", "containers"} {
+		items, _ := spec[field].([]any)
+		for _, item := range items {
+			container, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			if value, _ := nestedValue(container, "securityContext", "privileged"); value == true {
+				name, _ := container["name"].(string)
+				privileged[name] = true
+			}
+		}
+	}
+	return privileged
+}
+
+// nestedValue returns the value at path within obj.
+func nestedValue(obj map[string]any, path ...string) (any, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+	parent, ok := nestedMap(obj, path[:len(path)-1]...)
+	if !ok {
+		return nil, false
+	}
+	value, ok := parent[path[len(path)-1]]
+	return value, ok
+}
+
+// intField returns the integer at path within obj.
+func intField(obj map[string]any, path ...string) (int, bool) {
+	value, ok := nestedValue(obj, path...)
+	if !ok {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+// stringList converts a decoded YAML list into its string items.
+func stringList(value any) []string {
+	items, _ := value.([]any)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// summarizeList joins items, truncating long lists so findings stay one line.
+func summarizeList(items []string) string {
+	const maxItems = 5
+	if len(items) <= maxItems {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:maxItems], ", "), len(items)-maxItems)
+}
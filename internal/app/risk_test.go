@@ -0,0 +1,191 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// riskChange builds a ResourceChange from optional old/new manifests.
+func riskChange(t *testing.T, oldManifest, newManifest string) ResourceChange {
+	t.Helper()
+	var change ResourceChange
+	if oldManifest != "" {
+		change.Old = workloadResource(t, oldManifest)
+	}
+	if newManifest != "" {
+		change.New = workloadResource(t, newManifest)
+	}
+	return change
+}
+
+func TestParseRiskSeverity(t *testing.T) {
+	for name, want := range map[string]RiskSeverity{
+		"":         0,
+		"low":      RiskLow,
+		"Medium":   RiskMedium,
+		" HIGH ":   RiskHigh,
+		"critical": RiskCritical,
+	} {
+		got, err := ParseRiskSeverity(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, err := ParseRiskSeverity("severe")
+	assert.ErrorContains(t, err, `unknown risk severity "severe"`)
+	assert.Equal(t, "none", RiskSeverity(0).String())
+}
+
+func TestScaleToZeroRule(t *testing.T) {
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: web}\nspec: {%s}\n"
+
+	findings := assessRisks([]ResourceChange{
+		riskChange(t, sprintf(deployment, "replicas: 3"), sprintf(deployment, "replicas: 0")),
+	})
+	require.Len(t, findings, 1)
+	assert.Equal(t, RiskFinding{Severity: RiskHigh, Rule: "scale-to-zero", Resource: "Deployment/web", Message: "replicas scaled from 3 to 0"}, findings[0])
+
+	findings = assessRisks([]ResourceChange{riskChange(t, sprintf(deployment, ""), sprintf(deployment, "replicas: 0"))})
+	require.Len(t, findings, 1, "omitted replicas default to 1")
+	assert.Equal(t, "replicas scaled from 1 to 0", findings[0].Message)
+
+	assert.Empty(t, assessRisks([]ResourceChange{riskChange(t, sprintf(deployment, "replicas: 0"), sprintf(deployment, "replicas: 0, paused: true"))}))
+	assert.Empty(t, assessRisks([]ResourceChange{riskChange(t, sprintf(deployment, "replicas: 2"), sprintf(deployment, "replicas: 1"))}))
+}
+
+func TestDeletionRules(t *testing.T) {
+	findings := assessRisks([]ResourceChange{
+		riskChange(t, "apiVersion: v1\nkind: Namespace\nmetadata: {name: prod}\n", ""),
+		riskChange(t, "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata: {name: widgets.example.com}\n", ""),
+		riskChange(t, "apiVersion: v1\nkind: PersistentVolumeClaim\nmetadata: {name: data, namespace: db}\nspec: {}\n", ""),
+		riskChange(t, "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: cfg}\n", ""),
+	})
+
+	require.Len(t, findings, 3)
+	for _, finding := range findings {
+		assert.Equal(t, RiskCritical, finding.Severity)
+	}
+	assert.Equal(t, []string{"crd-deletion", "namespace-deletion", "pvc-change"},
+		[]string{findings[0].Rule, findings[1].Rule, findings[2].Rule})
+}
+
+func TestPersistentVolumeRule(t *testing.T) {
+	pvc := "apiVersion: v1\nkind: PersistentVolumeClaim\nmetadata: {name: data}\nspec: {resources: {requests: {storage: %s}}}\n"
+	statefulSet := "apiVersion: apps/v1\nkind: StatefulSet\nmetadata: {name: db}\nspec:\n  volumeClaimTemplates:\n    - metadata: {name: data}\n      spec: {storageClassName: %s}\n"
+
+	findings := assessRisks([]ResourceChange{
+		riskChange(t, sprintf(pvc, "1Gi"), sprintf(pvc, "2Gi")),
+		riskChange(t, sprintf(statefulSet, "standard"), sprintf(statefulSet, "fast")),
+	})
+
+	require.Len(t, findings, 2)
+	assert.Equal(t, "PersistentVolumeClaim/data", findings[0].Resource)
+	assert.Equal(t, "StatefulSet/db", findings[1].Resource)
+	assert.Contains(t, findings[1].Message, "volumeClaimTemplates")
+}
+
+func TestImmutableFieldRule(t *testing.T) {
+	service := "apiVersion: v1\nkind: Service\nmetadata: {name: web}\nspec: {%s}\n"
+	job := "apiVersion: batch/v1\nkind: Job\nmetadata: {name: migrate}\nspec: {selector: {matchLabels: {app: %s}}}\n"
+
+	findings := assessRisks([]ResourceChange{
+		riskChange(t, sprintf(service, "clusterIP: 10.0.0.1"), sprintf(service, "clusterIP: None")),
+		riskChange(t, sprintf(service, ""), sprintf(service, "clusterIP: 10.0.0.2")),
+		riskChange(t, sprintf(job, "a"), sprintf(job, "b")),
+	})
+
+	require.Len(t, findings, 2)
+	assert.Equal(t, "Job/migrate", findings[0].Resource)
+	assert.Contains(t, findings[0].Message, "spec.selector")
+	assert.Equal(t, "Service/web", findings[1].Resource)
+	assert.Contains(t, findings[1].Message, "spec.clusterIP")
+}
+
+func TestRBACWideningRule(t *testing.T) {
+	role := "apiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata: {name: reader}\nrules:\n%s"
+	readPods := "  - {apiGroups: [\"\"], resources: [pods], verbs: [get, list]}\n"
+	readSecrets := "  - {apiGroups: [\"\"], resources: [secrets], verbs: [get]}\n"
+	everything := "  - {apiGroups: [\"*\"], resources: [\"*\"], verbs: [\"*\"]}\n"
+
+	findings := assessRisks([]ResourceChange{riskChange(t, sprintf(role, readPods), sprintf(role, readPods+readSecrets))})
+	require.Len(t, findings, 1)
+	assert.Equal(t, RiskMedium, findings[0].Severity)
+	assert.Equal(t, "grants additional permissions: get /secrets", findings[0].Message)
+
+	findings = assessRisks([]ResourceChange{riskChange(t, sprintf(role, readPods), sprintf(role, readPods+everything))})
+	require.Len(t, findings, 1)
+	assert.Equal(t, RiskHigh, findings[0].Severity)
+
+	assert.Empty(t, assessRisks([]ResourceChange{riskChange(t, "", sprintf(role, readPods))}), "new narrow roles are not findings")
+	assert.Len(t, assessRisks([]ResourceChange{riskChange(t, "", sprintf(role, everything))}), 1, "new wildcard roles are")
+	assert.Empty(t, assessRisks([]ResourceChange{riskChange(t, sprintf(role, readPods+readSecrets), sprintf(role, readPods))}), "narrowing is fine")
+
+	binding := "apiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRoleBinding\nmetadata: {name: ops}\nroleRef: {kind: ClusterRole, name: %s}\nsubjects:\n%s"
+	alice := "  - {kind: User, name: alice}\n"
+	bob := "  - {kind: User, name: bob}\n"
+
+	findings = assessRisks([]ResourceChange{riskChange(t, sprintf(binding, "view", alice), sprintf(binding, "cluster-admin", alice))})
+	require.Len(t, findings, 1)
+	assert.Equal(t, RiskHigh, findings[0].Severity)
+	assert.Contains(t, findings[0].Message, "cluster-admin")
+
+	findings = assessRisks([]ResourceChange{riskChange(t, sprintf(binding, "view", alice), sprintf(binding, "view", alice+bob))})
+	require.Len(t, findings, 1)
+	assert.Equal(t, RiskMedium, findings[0].Severity)
+	assert.Equal(t, "grants view to additional subjects: User//bob", findings[0].Message)
+}
+
+func TestPrivilegedPodRule(t *testing.T) {
+	daemonSet := "apiVersion: apps/v1\nkind: DaemonSet\nmetadata: {name: agent}\nspec:\n  template:\n    spec:\n      %s\n      containers:\n        - name: agent\n          securityContext: {privileged: %s}\n"
+
+	findings := assessRisks([]ResourceChange{
+		riskChange(t, sprintf(daemonSet, "hostNetwork: false", "false"), sprintf(daemonSet, "hostNetwork: true", "true")),
+	})
+	require.Len(t, findings, 2)
+	assert.Equal(t, "hostNetwork enabled", findings[0].Message)
+	assert.Equal(t, "privileged mode enabled for container(s) agent", findings[1].Message)
+
+	assert.Empty(t, assessRisks([]ResourceChange{
+		riskChange(t, sprintf(daemonSet, "hostNetwork: true", "true"), sprintf(daemonSet, "hostNetwork: true\n      dnsPolicy: Default", "true")),
+	}), "already privileged workloads are not re-reported")
+}
+
+func TestAssessRisksSkipsUnchangedResources(t *testing.T) {
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: web}\nspec: {replicas: 0}\n"
+	change := riskChange(t, deployment, deployment)
+	change.Old.Object["spec"].(map[string]any)["replicas"] = 0
+
+	assert.Empty(t, assessRisks([]ResourceChange{change}))
+}
+
+func TestHighestRisk(t *testing.T) {
+	assert.Equal(t, RiskSeverity(0), highestRisk(nil))
+	assert.Equal(t, RiskHigh, highestRisk([]RiskFinding{{Severity: RiskLow}, {Severity: RiskHigh}, {Severity: RiskMedium}}))
+}
+
+func sprintf(format string, args ...any) string {
+	return fmt.Sprintf(format, args...)
+}
+
+func TestOutcomeOfFailOnRisk(t *testing.T) {
+	result := ComparisonResult{Findings: []RiskFinding{{Severity: RiskMedium}}}
+
+	assert.False(t, (&App{cfg: Config{}}).outcomeOf(result).riskExceeded, "no threshold configured")
+	assert.True(t, (&App{cfg: Config{FailOnRisk: RiskMedium}}).outcomeOf(result).riskExceeded)
+	assert.False(t, (&App{cfg: Config{FailOnRisk: RiskHigh}}).outcomeOf(result).riskExceeded)
+}
+
+func TestComparisonOutcomeErr(t *testing.T) {
+	assert.NoError(t, comparisonOutcome{}.err())
+	assert.Equal(t, ErrRiskThresholdExceeded, comparisonOutcome{riskExceeded: true}.err())
+
+	var outcome comparisonOutcome
+	outcome.merge(comparisonOutcome{validationFailed: true})
+	outcome.merge(comparisonOutcome{riskExceeded: true})
+	err := outcome.err()
+	assert.ErrorIs(t, err, ErrManifestValidationFailed)
+	assert.ErrorIs(t, err, ErrRiskThresholdExceeded)
+}
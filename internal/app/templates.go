@@ -0,0 +1,211 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/afero"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+)
+
+// commentSplitMarker separates comments in the output of a comment template.
+// Templates emit it with the commentBreak function.
+const commentSplitMarker = "<!-- argo-compare:comment-break -->"
+
+// ReportTemplate renders the run report through a user-supplied text/template.
+type ReportTemplate struct {
+	Template string // Template file, read relative to the repository root.
+	Output   string // File the rendered report is written to.
+}
+
+// CommentTemplateData is the data a comment template is executed with. It
+// embeds the comparison result, so templates can refer to .Changed,
+// .Findings, .ValidationResults and so on directly.
+type CommentTemplateData struct {
+	ComparisonResult
+	Application string // Application manifest the result belongs to.
+	ShowAdded   bool   // Whether added manifests should be shown in full.
+	ShowRemoved bool   // Whether removed manifests should be shown in full.
+}
+
+// DiffStat counts the lines a unified diff adds and removes.
+type DiffStat struct {
+	Added   int
+	Removed int
+}
+
+// templateFuncs are available to comment and report templates in addition to
+// the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"stripDiffHeaders": stripDiffHeaders,
+	"diffStat":         diffStat,
+	"chunk":            chunkText,
+	"truncate":         truncateText,
+	"fence":            fenceCode,
+	"escapeMarkdown":   escapeInlineMarkdown,
+	"trimPrefix":       func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"plural": func(n int, singular, plural string) string {
+		if n == 1 {
+			return singular
+		}
+		return plural
+	},
+	"isCRD": func(file, diff string) bool {
+		return isCRDManifest(DiffOutput{File: File{Name: file}, Diff: diff})
+	},
+	"commentBreak": func() string { return commentSplitMarker },
+
+	// The sections of the built-in comment, for templates that only want to
+	// rearrange or frame them.
+	"riskSummary":        buildRiskSummary,
+	"imageSummary":       buildImageSummary,
+	"validationSummary":  func(results map[string]ports.ValidationResult) string { return buildValidationSummary(results) },
+	"policySummary":      buildPolicySummary,
+	"deprecationSummary": buildDeprecationSummary,
+}
+
+// diffStat counts added and removed lines, ignoring file headers.
+func diffStat(diff string) DiffStat {
+	var stat DiffStat
+	for _, line := range strings.Split(stripDiffHeaders(diff), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			stat.Added++
+		case strings.HasPrefix(line, "-"):
+			stat.Removed++
+		}
+	}
+	return stat
+}
+
+// chunkText splits text into pieces of at most limit bytes, cutting at line
+// boundaries where possible.
+func chunkText(limit int, text string) []string {
+	var chunks []string
+	for text != "" {
+		var chunk string
+		chunk, text = splitDiffContent(text, limit)
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// truncateText shortens text to at most limit bytes at a line boundary and
+// marks the cut.
+func truncateText(limit int, text string) string {
+	chunk, rest := splitDiffContent(text, limit)
+	if rest == "" {
+		return chunk
+	}
+	return strings.TrimRight(chunk, "\n") + "\n… (truncated)"
+}
+
+// fenceCode wraps text in a Markdown code block whose fence is longer than
+// any backtick run inside it.
+func fenceCode(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence + "\n"
+}
+
+// loadTemplate reads and parses a user-supplied template file.
+func loadTemplate(reader ports.FileReader, path string) (*template.Template, error) {
+	data, err := reader.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read template %s: %w", path, err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("template %s not found", path)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// loadCommentTemplate loads cfg.CommentTemplate. It returns nil when the
+// built-in comment layout is used.
+func loadCommentTemplate(cfg Config, reader ports.FileReader) (*template.Template, error) {
+	if cfg.CommentTemplate == "" {
+		return nil, nil
+	}
+	return loadTemplate(reader, cfg.CommentTemplate)
+}
+
+// loadReportTemplates builds a run presenter for every configured report template.
+func loadReportTemplates(cfg Config, fs afero.Fs, reader ports.FileReader) ([]RunPresenter, error) {
+	var presenters []RunPresenter
+	for _, report := range cfg.ReportTemplates {
+		tmpl, err := loadTemplate(reader, report.Template)
+		if err != nil {
+			return nil, err
+		}
+		presenters = append(presenters, TemplateReportWriter{Fs: fs, Template: tmpl, Path: report.Output})
+	}
+	return presenters, nil
+}
+
+// commentBodies renders the comments for one Application, through tmpl when a
+// comment template is configured and with the built-in layout otherwise. No
+// comment exceeds limit bytes.
+func commentBodies(tmpl *template.Template, result ComparisonResult, showAdded, showRemoved bool, applicationPath string, limit int) ([]string, error) {
+	if tmpl == nil {
+		return buildCommentBodies(result, showAdded, showRemoved, applicationPath, limit), nil
+	}
+	return renderCommentTemplate(tmpl, CommentTemplateData{
+		ComparisonResult: result,
+		Application:      applicationPath,
+		ShowAdded:        showAdded,
+		ShowRemoved:      showRemoved,
+	}, limit)
+}
+
+// renderCommentTemplate executes a comment template and splits its output
+// into comments at every commentBreak. Comments that still exceed limit are
+// split at line boundaries.
+func renderCommentTemplate(tmpl *template.Template, data CommentTemplateData, limit int) ([]string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render comment template: %w", err)
+	}
+
+	var bodies []string
+	for _, part := range strings.Split(buf.String(), commentSplitMarker) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		for _, body := range chunkText(limit-commentPartReserve, strings.Trim(part, "\n")) {
+			bodies = append(bodies, ensureTrailingNewline(body))
+		}
+	}
+	if len(bodies) == 0 {
+		return nil, errors.New("render comment template: the template produced an empty comment")
+	}
+	return bodies, nil
+}
+
+// TemplateReportWriter renders the run report through a user-supplied
+// text/template and writes the result to Path.
+type TemplateReportWriter struct {
+	Fs       afero.Fs
+	Template *template.Template
+	Path     string
+}
+
+// PresentRun implements RunPresenter.
+func (w TemplateReportWriter) PresentRun(_ context.Context, report RunReport) error {
+	var buf bytes.Buffer
+	if err := w.Template.Execute(&buf, report); err != nil {
+		return fmt.Errorf("render report template %s: %w", w.Template.Name(), err)
+	}
+	return writeReportFile(w.Fs, w.Path, buf.Bytes())
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const commentTemplate = `### {{.Application}}{{if .IsEmpty}} — no changes{{end}}
+{{range .Changed}}{{$stat := diffStat .Diff}}
+- {{trimPrefix "/" .File.Name}} (+{{$stat.Added}}/-{{$stat.Removed}}){{if isCRD .File.Name .Diff}} CRD{{end}}
+{{end}}{{with .Findings}}{{len .}} risky {{plural (len .) "change" "changes"}}{{end}}
+{{commentBreak}}
+{{range .Changed}}{{fence "diff" (stripDiffHeaders .Diff)}}{{end}}`
+
+func parseTestTemplate(t *testing.T, text string) *App {
+	t.Helper()
+	cfg, err := NewConfig("main", WithCacheDir("/tmp/cache"), WithMaskingKey("test-key"), WithCommentTemplate("comment.tmpl"))
+	require.NoError(t, err)
+	a, err := New(cfg, Dependencies{
+		FS:         afero.NewMemMapFs(),
+		FileReader: mapFileReader{files: map[string][]byte{"comment.tmpl": []byte(text)}},
+		Logger:     setupTestLogger(t, "app-templates"),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, a.commentTemplate)
+	return a
+}
+
+func TestCommentStrategyWithTemplate(t *testing.T) {
+	a := parseTestTemplate(t, commentTemplate)
+	poster := &stubPoster{}
+	strategy := CommentStrategy{
+		Log:             setupSilentLogger("comment-template", t),
+		Poster:          poster,
+		Template:        a.commentTemplate,
+		ApplicationPath: "apps/web.yaml",
+	}
+
+	result := ComparisonResult{
+		Changed: []DiffOutput{
+			{File: File{Name: "/web/deployment.yaml"}, Diff: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-replicas: 2\n+replicas: 3\n+image: web:2\n"},
+			{File: File{Name: "/web/crds/widget.yaml"}, Diff: "@@ -1 +1 @@\n-a\n+b\n"},
+		},
+		Findings: []RiskFinding{{Severity: RiskHigh, Rule: "scale-to-zero"}},
+	}
+	require.NoError(t, strategy.Present(context.Background(), result))
+
+	require.Len(t, poster.bodies, 2)
+	assert.Equal(t, "### apps/web.yaml\n\n- web/deployment.yaml (+2/-1)\n\n- web/crds/widget.yaml (+1/-1) CRD\n1 risky change\n\n_Part 1 of 2_\n", poster.bodies[0])
+	assert.True(t, strings.HasPrefix(poster.bodies[1], "```diff\n@@ -1,2 +1,2 @@\n-replicas: 2\n"))
+}
+
+func TestRenderCommentTemplateSplitsOversizedComments(t *testing.T) {
+	a := parseTestTemplate(t, `{{range .Changed}}{{.Diff}}{{end}}`)
+	line := strings.Repeat("x", 999) + "\n"
+	result := ComparisonResult{Changed: []DiffOutput{{Diff: strings.Repeat(line, 1500)}}}
+
+	bodies, err := commentBodies(a.commentTemplate, result, false, false, "apps/web.yaml", defaultCommentLengthLimit)
+	require.NoError(t, err)
+	require.Len(t, bodies, 2)
+	for _, body := range bodies {
+		assert.LessOrEqual(t, len(body), defaultCommentLengthLimit)
+	}
+}
+
+func TestRenderCommentTemplateErrors(t *testing.T) {
+	a := parseTestTemplate(t, `{{if .IsEmpty}}{{commentBreak}}{{end}}`)
+	_, err := commentBodies(a.commentTemplate, ComparisonResult{}, false, false, "apps/web.yaml", defaultCommentLengthLimit)
+	assert.EqualError(t, err, "render comment template: the template produced an empty comment")
+
+	a = parseTestTemplate(t, `{{.Unknown}}`)
+	_, err = commentBodies(a.commentTemplate, ComparisonResult{}, false, false, "apps/web.yaml", defaultCommentLengthLimit)
+	assert.ErrorContains(t, err, "render comment template")
+}
+
+func TestCommentBodiesWithoutTemplateUsesBuiltInLayout(t *testing.T) {
+	bodies, err := commentBodies(nil, ComparisonResult{}, false, false, "apps/web.yaml", defaultCommentLengthLimit)
+	require.NoError(t, err)
+	assert.Equal(t, buildCommentBodies(ComparisonResult{}, false, false, "apps/web.yaml", defaultCommentLengthLimit), bodies)
+}
+
+func TestNewRejectsUnusableTemplates(t *testing.T) {
+	newApp := func(files map[string][]byte, opts ...ConfigOption) error {
+		cfg, err := NewConfig("main", append([]ConfigOption{WithCacheDir("/tmp/cache"), WithMaskingKey("test-key")}, opts...)...)
+		require.NoError(t, err)
+		_, err = New(cfg, Dependencies{
+			FS:         afero.NewMemMapFs(),
+			FileReader: mapFileReader{files: files},
+			Logger:     setupTestLogger(t, "app-templates-invalid"),
+		})
+		return err
+	}
+
+	assert.EqualError(t, newApp(nil, WithCommentTemplate("comment.tmpl")), "template comment.tmpl not found")
+	assert.ErrorContains(t, newApp(map[string][]byte{"report.tmpl": []byte("{{range}}")}, WithReportTemplate("report.tmpl", "out.md")),
+		"parse template report.tmpl")
+}
+
+func TestTemplateReportWriter(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cfg, err := NewConfig("main", WithCacheDir("/tmp/cache"), WithMaskingKey("test-key"), WithReportTemplate("summary.tmpl", "out/summary.md"))
+	require.NoError(t, err)
+	a, err := New(cfg, Dependencies{
+		FS: fs,
+		FileReader: mapFileReader{files: map[string][]byte{"summary.tmpl": []byte(
+			`{{.TargetBranch}}:{{range .Applications}} {{.File}}={{len .Changed}}{{end}}`)}},
+		Logger: setupTestLogger(t, "app-report-template"),
+	})
+	require.NoError(t, err)
+	require.Len(t, a.runPresenters, 1)
+
+	report := RunReport{TargetBranch: "main", Applications: []ApplicationReport{
+		{File: "apps/web.yaml", Changed: []ManifestReport{{File: "/web/cm.yaml"}}},
+		{File: "apps/api.yaml"},
+	}}
+	require.NoError(t, a.runPresenters[0].PresentRun(context.Background(), report))
+
+	data, err := afero.ReadFile(fs, "out/summary.md")
+	require.NoError(t, err)
+	assert.Equal(t, "main: apps/web.yaml=1 apps/api.yaml=0", string(data))
+}
+
+func TestTemplateHelpers(t *testing.T) {
+	assert.Equal(t, DiffStat{Added: 1, Removed: 2}, diffStat("--- a/x\n+++ b/x\n@@ -1,2 +1 @@\n-a\n-b\n+c\n"))
+	assert.Equal(t, []string{"ab", "cd", "e"}, chunkText(3, "ab\ncd\ne"))
+	assert.Nil(t, chunkText(3, ""))
+	assert.Equal(t, "ab\n… (truncated)", truncateText(4, "ab\ncd\n"))
+	assert.Equal(t, "ab\n", truncateText(10, "ab\n"))
+	assert.Equal(t, "```yaml\na: 1\n```\n", fenceCode("yaml", "a: 1\n"))
+	assert.Equal(t, "````\n```nested```\n````\n", fenceCode("", "```nested```"))
+}
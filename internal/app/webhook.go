@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// WebhookSender delivers a JSON payload to a webhook endpoint.
+type WebhookSender interface {
+	Send(ctx context.Context, payload []byte) error
+}
+
+// WebhookNotifier is a RunPresenter that POSTs the run report, in the same
+// layout as the JSON report, to a webhook.
+type WebhookNotifier struct {
+	Sender WebhookSender
+}
+
+// PresentRun implements RunPresenter.
+func (n WebhookNotifier) PresentRun(ctx context.Context, report RunReport) error {
+	if n.Sender == nil {
+		return errors.New("webhook notifier requires a sender implementation")
+	}
+
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+	if err := n.Sender.Send(ctx, payload); err != nil {
+		return fmt.Errorf("send webhook: %w", err)
+	}
+	return nil
+}
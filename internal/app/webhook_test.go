@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSender records the payloads it is asked to deliver.
+type recordingSender struct {
+	payloads [][]byte
+	err      error
+}
+
+func (s *recordingSender) Send(_ context.Context, payload []byte) error {
+	s.payloads = append(s.payloads, payload)
+	return s.err
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	sender := &recordingSender{}
+	notifier := WebhookNotifier{Sender: sender}
+	report := RunReport{
+		Version:      "1.2.3",
+		TargetBranch: "main",
+		Applications: []ApplicationReport{{File: "apps/web.yaml", Name: "web", Differences: true}},
+	}
+	require.NoError(t, notifier.PresentRun(context.Background(), report))
+
+	require.Len(t, sender.payloads, 1)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(sender.payloads[0], &decoded))
+	assert.Equal(t, "1.2.3", decoded["version"])
+	assert.Equal(t, "main", decoded["targetBranch"])
+	applications, ok := decoded["applications"].([]any)
+	require.True(t, ok)
+	require.Len(t, applications, 1)
+	assert.Equal(t, "apps/web.yaml", applications[0].(map[string]any)["file"])
+}
+
+func TestWebhookNotifierErrors(t *testing.T) {
+	err := WebhookNotifier{}.PresentRun(context.Background(), RunReport{})
+	require.ErrorContains(t, err, "requires a sender")
+
+	sender := &recordingSender{err: errors.New("webhook: unexpected status 401 Unauthorized")}
+	err = WebhookNotifier{Sender: sender}.PresentRun(context.Background(), RunReport{})
+	require.ErrorContains(t, err, "send webhook: webhook: unexpected status 401")
+}
+
+func TestNewWithWebhook(t *testing.T) {
+	cfg, err := NewConfig("main", WithCacheDir("/tmp/cache"), WithWebhook(WebhookConfig{URL: "https://hooks.example.com/argo-compare"}))
+	require.NoError(t, err)
+
+	deps := Dependencies{FS: afero.NewMemMapFs(), Logger: setupSilentLogger("app-webhook", t)}
+	appInstance, err := New(cfg, deps)
+	require.NoError(t, err)
+	require.Len(t, appInstance.runPresenters, 1)
+	assert.True(t, appInstance.reporting())
+
+	sender := &recordingSender{}
+	deps.WebhookSender = sender
+	appInstance, err = New(cfg, deps)
+	require.NoError(t, err)
+	require.Len(t, appInstance.runPresenters, 1)
+	assert.Equal(t, WebhookNotifier{Sender: sender}, appInstance.runPresenters[0])
+}
@@ -0,0 +1,182 @@
+// Package azuredevops implements the comment.Poster interface for posting diff
+// comments as Azure DevOps pull request threads via the Azure DevOps REST API.
+package azuredevops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/comment"
+	"github.com/shini4i/argo-compare/internal/helpers"
+)
+
+const (
+	apiVersion = "7.1"
+	// commentLengthLimit is the 150,000-character limit Azure DevOps enforces
+	// on pull request comments.
+	commentLengthLimit = 150000
+	// threadStatusActive opens the thread as active, so reviewers resolve it.
+	threadStatusActive    = 1
+	commentTypeText       = 1
+	maxErrorResponseBytes = 4096
+	defaultClientTimeout  = 15 * time.Second
+)
+
+// Config describes settings required to post threads to an Azure DevOps pull request.
+type Config struct {
+	// OrganizationURL is the collection URI, e.g. https://dev.azure.com/acme
+	// or https://tfs.example.com/DefaultCollection for Azure DevOps Server.
+	OrganizationURL string
+	Project         string
+	Repository      string // Repository name or ID.
+	PullRequest     int
+	Token           string
+	// PersonalAccessToken sends Token with Basic authentication, as personal
+	// access tokens require. Otherwise Token is sent as a bearer token, which
+	// suits the pipeline's System.AccessToken.
+	PersonalAccessToken bool
+	HTTPClient          *http.Client
+	Timeout             time.Duration
+}
+
+type Poster struct {
+	client   *http.Client
+	endpoint string
+	token    string
+	pat      bool
+}
+
+// Ensure Poster implements comment.Poster and reports Azure DevOps' size limit.
+var (
+	_ comment.Poster      = (*Poster)(nil)
+	_ comment.BodyLimiter = (*Poster)(nil)
+)
+
+// NewPoster creates a Poster configured to open threads on an Azure DevOps pull request using cfg.
+// It validates that cfg.OrganizationURL, cfg.Project, cfg.Repository, cfg.PullRequest and cfg.Token are provided and
+// parses cfg.OrganizationURL; returns an error if validation or URL parsing fails. If cfg.HTTPClient is nil, a default
+// http.Client is created using cfg.Timeout (or the package default).
+func NewPoster(cfg Config) (*Poster, error) {
+	if cfg.OrganizationURL == "" {
+		return nil, fmt.Errorf("azuredevops: organization URL is required")
+	}
+	if cfg.Project == "" {
+		return nil, fmt.Errorf("azuredevops: project is required")
+	}
+	if cfg.Repository == "" {
+		return nil, fmt.Errorf("azuredevops: repository is required")
+	}
+	if cfg.PullRequest <= 0 {
+		return nil, fmt.Errorf("azuredevops: pull request ID is required")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("azuredevops: token is required")
+	}
+
+	endpoint, err := url.Parse(cfg.OrganizationURL)
+	if err != nil {
+		return nil, fmt.Errorf("azuredevops: parse organization URL: %w", err)
+	}
+	endpoint.Path = path.Join(endpoint.Path, cfg.Project, "_apis", "git", "repositories", cfg.Repository,
+		"pullRequests", strconv.Itoa(cfg.PullRequest), "threads")
+	endpoint.RawQuery = url.Values{"api-version": {apiVersion}}.Encode()
+
+	client := cfg.HTTPClient
+	if client == nil {
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = defaultClientTimeout
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+
+	return &Poster{
+		client:   client,
+		endpoint: endpoint.String(),
+		token:    cfg.Token,
+		pat:      cfg.PersonalAccessToken,
+	}, nil
+}
+
+// MaxBodyLength implements comment.BodyLimiter.
+func (p *Poster) MaxBodyLength() int {
+	return commentLengthLimit
+}
+
+// Post opens a new active thread on the pull request with body as its only
+// comment. Network errors and 5xx server errors are retried with exponential
+// backoff.
+func (p *Poster) Post(ctx context.Context, body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("azuredevops: comment body is empty")
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"comments": []map[string]any{{
+			"parentCommentId": 0,
+			"content":         body,
+			"commentType":     commentTypeText,
+		}},
+		"status": threadStatusActive,
+	})
+	if err != nil {
+		return fmt.Errorf("azuredevops: marshal payload: %w", err)
+	}
+
+	retryCfg := helpers.DefaultRetryConfig()
+	return helpers.WithRetry(ctx, retryCfg, func() error {
+		return p.doRequest(ctx, payload)
+	})
+}
+
+// doRequest performs the HTTP request and returns an error only for retryable failures.
+// Client errors (4xx) are wrapped in a permanentError to prevent retry.
+func (p *Poster) doRequest(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("azuredevops: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if p.pat {
+		req.SetBasicAuth("", p.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req) // #nosec G107 G704 -- endpoint is built from operator-configured OrganizationURL, not user input
+	if err != nil {
+		// Network errors are retryable
+		return fmt.Errorf("azuredevops: perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		// Success - drain response body for connection reuse
+		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // best-effort drain for keep-alive; a failure here is harmless
+		return nil
+	}
+
+	//nolint:errcheck // best-effort read of the error body; a partial or empty read still yields a usable message
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
+	apiErr := fmt.Errorf("azuredevops: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+
+	// 3xx and 4xx responses indicate a configuration issue (an expired token
+	// redirects to the sign-in page, missing permissions, unknown pull request)
+	// and should not be retried
+	if resp.StatusCode < http.StatusInternalServerError {
+		return helpers.WrapPermanent(apiErr)
+	}
+
+	// 5xx: Server errors are transient and should be retried
+	return apiErr
+}
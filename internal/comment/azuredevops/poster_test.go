@@ -0,0 +1,165 @@
+package azuredevops
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestPosterPost(t *testing.T) {
+	var received struct {
+		Method string
+		URL    string
+		Auth   string
+		Body   map[string]any
+	}
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			received.Method = req.Method
+			received.URL = req.URL.String()
+			received.Auth = req.Header.Get("Authorization")
+
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(data, &received.Body))
+
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{
+		OrganizationURL: "https://dev.azure.com/acme/",
+		Project:         "Platform Team",
+		Repository:      "gitops",
+		PullRequest:     77,
+		Token:           "system-access-token",
+		HTTPClient:      client,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, poster.Post(context.Background(), "hello world"))
+
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "https://dev.azure.com/acme/Platform%20Team/_apis/git/repositories/gitops/pullRequests/77/threads?api-version=7.1", received.URL)
+	assert.Equal(t, "Bearer system-access-token", received.Auth)
+	assert.Equal(t, map[string]any{
+		"comments": []any{map[string]any{"parentCommentId": float64(0), "content": "hello world", "commentType": float64(1)}},
+		"status":   float64(1),
+	}, received.Body)
+}
+
+func TestPosterPostPersonalAccessToken(t *testing.T) {
+	var auth string
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			auth = req.Header.Get("Authorization")
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{
+		OrganizationURL:     "https://dev.azure.com/acme",
+		Project:             "platform",
+		Repository:          "gitops",
+		PullRequest:         1,
+		Token:               "pat",
+		PersonalAccessToken: true,
+		HTTPClient:          client,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, poster.Post(context.Background(), "body"))
+	assert.Equal(t, "Basic OnBhdA==", auth)
+}
+
+func TestPosterMaxBodyLength(t *testing.T) {
+	poster, err := NewPoster(Config{OrganizationURL: "https://dev.azure.com/acme", Project: "p", Repository: "r", PullRequest: 1, Token: "t"})
+	require.NoError(t, err)
+	assert.Equal(t, 150000, poster.MaxBodyLength())
+}
+
+func TestNewPosterValidatesConfig(t *testing.T) {
+	valid := Config{OrganizationURL: "https://dev.azure.com/acme", Project: "p", Repository: "r", PullRequest: 1, Token: "t"}
+	_, err := NewPoster(valid)
+	require.NoError(t, err)
+
+	for name, mutate := range map[string]func(*Config){
+		"missing organization URL":    func(c *Config) { c.OrganizationURL = "" },
+		"missing project":             func(c *Config) { c.Project = "" },
+		"missing repository":          func(c *Config) { c.Repository = "" },
+		"missing pull request":        func(c *Config) { c.PullRequest = 0 },
+		"missing token":               func(c *Config) { c.Token = "" },
+		"unparsable organization URL": func(c *Config) { c.OrganizationURL = "://bad" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid
+			mutate(&cfg)
+			_, err := NewPoster(cfg)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPosterPost4xxIsPermanentError(t *testing.T) {
+	attempts := 0
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusForbidden)
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{OrganizationURL: "https://dev.azure.com/acme", Project: "p", Repository: "r", PullRequest: 1, Token: "t", HTTPClient: client})
+	require.NoError(t, err)
+
+	err = poster.Post(context.Background(), "body")
+	require.Error(t, err)
+	assert.True(t, helpers.IsPermanent(err))
+	assert.Equal(t, 1, attempts) // Should not retry 4xx
+}
+
+func TestPosterPost5xxIsRetried(t *testing.T) {
+	attempts := 0
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			resp := httptest.NewRecorder()
+			if attempts < 2 {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+			} else {
+				resp.WriteHeader(http.StatusOK)
+			}
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{OrganizationURL: "https://dev.azure.com/acme", Project: "p", Repository: "r", PullRequest: 1, Token: "t", HTTPClient: client})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, poster.Post(ctx, "body"))
+	assert.Equal(t, 2, attempts)
+}
@@ -0,0 +1,192 @@
+// Package bitbucket implements the comment.Poster interface for posting diff
+// comments to Bitbucket Cloud and Bitbucket Data Center pull requests.
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/comment"
+	"github.com/shini4i/argo-compare/internal/helpers"
+)
+
+const (
+	// DefaultCloudBaseURL is the REST API root of Bitbucket Cloud.
+	DefaultCloudBaseURL = "https://api.bitbucket.org/2.0"
+	// commentLengthLimit is the 32,768-character limit Bitbucket enforces on
+	// pull request comments.
+	commentLengthLimit    = 32768
+	dataCenterAPIPrefix   = "/rest/api/1.0"
+	maxErrorResponseBytes = 4096
+	defaultClientTimeout  = 15 * time.Second
+)
+
+// Config describes settings required to post comments to a Bitbucket pull request.
+type Config struct {
+	// BaseURL is the Bitbucket Cloud API root (defaults to DefaultCloudBaseURL)
+	// or, with DataCenter set, the server root, e.g. https://bitbucket.example.com.
+	BaseURL    string
+	DataCenter bool
+	// Workspace is the Bitbucket Cloud workspace, or the project key on
+	// Bitbucket Data Center.
+	Workspace   string
+	Repository  string // Repository slug.
+	PullRequest int
+	// Username selects HTTP Basic authentication with Token as an app password
+	// (Cloud) or password (Data Center). Without it Token is sent as a bearer
+	// token: a repository, project or workspace access token on Cloud, or an
+	// HTTP access token on Data Center.
+	Username   string
+	Token      string
+	HTTPClient *http.Client
+	Timeout    time.Duration
+}
+
+type Poster struct {
+	client     *http.Client
+	endpoint   string
+	dataCenter bool
+	username   string
+	token      string
+}
+
+// Ensure Poster implements comment.Poster and reports Bitbucket's size limit.
+var (
+	_ comment.Poster      = (*Poster)(nil)
+	_ comment.BodyLimiter = (*Poster)(nil)
+)
+
+// NewPoster creates a Poster configured to comment on a Bitbucket pull request using cfg.
+// It validates that cfg.Token, cfg.Workspace, cfg.Repository and cfg.PullRequest are provided, and cfg.BaseURL for
+// Data Center, and parses cfg.BaseURL; returns an error if validation or URL parsing fails. If cfg.HTTPClient is nil,
+// a default http.Client is created using cfg.Timeout (or the package default).
+func NewPoster(cfg Config) (*Poster, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("bitbucket: token is required")
+	}
+	if cfg.Workspace == "" {
+		return nil, fmt.Errorf("bitbucket: workspace or project key is required")
+	}
+	if cfg.Repository == "" {
+		return nil, fmt.Errorf("bitbucket: repository slug is required")
+	}
+	if cfg.PullRequest <= 0 {
+		return nil, fmt.Errorf("bitbucket: pull request ID is required")
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		if cfg.DataCenter {
+			return nil, fmt.Errorf("bitbucket: base URL is required for Bitbucket Data Center")
+		}
+		baseURL = DefaultCloudBaseURL
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("bitbucket: parse base URL: %w", err)
+	}
+
+	pullRequest := strconv.Itoa(cfg.PullRequest)
+	if cfg.DataCenter {
+		base.Path = path.Join(base.Path, dataCenterAPIPrefix, "projects", cfg.Workspace, "repos", cfg.Repository, "pull-requests", pullRequest, "comments")
+	} else {
+		base.Path = path.Join(base.Path, "repositories", cfg.Workspace, cfg.Repository, "pullrequests", pullRequest, "comments")
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = defaultClientTimeout
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+
+	return &Poster{
+		client:     client,
+		endpoint:   base.String(),
+		dataCenter: cfg.DataCenter,
+		username:   cfg.Username,
+		token:      cfg.Token,
+	}, nil
+}
+
+// MaxBodyLength implements comment.BodyLimiter.
+func (p *Poster) MaxBodyLength() int {
+	return commentLengthLimit
+}
+
+// Post sends the supplied comment body to the pull request. The payload shape
+// differs between Bitbucket Cloud and Data Center, both of which render the
+// body as Markdown. Network errors and 5xx server errors are retried with
+// exponential backoff.
+func (p *Poster) Post(ctx context.Context, body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("bitbucket: comment body is empty")
+	}
+
+	var content any = map[string]any{"content": map[string]string{"raw": body}}
+	if p.dataCenter {
+		content = map[string]string{"text": body}
+	}
+	payload, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("bitbucket: marshal payload: %w", err)
+	}
+
+	retryCfg := helpers.DefaultRetryConfig()
+	return helpers.WithRetry(ctx, retryCfg, func() error {
+		return p.doRequest(ctx, payload)
+	})
+}
+
+// doRequest performs the HTTP request and returns an error only for retryable failures.
+// Client errors (4xx) are wrapped in a permanentError to prevent retry.
+func (p *Poster) doRequest(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("bitbucket: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if p.username != "" {
+		req.SetBasicAuth(p.username, p.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req) // #nosec G107 G704 -- endpoint is built from operator-configured BaseURL, not user input
+	if err != nil {
+		// Network errors are retryable
+		return fmt.Errorf("bitbucket: perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		// Success - drain response body for connection reuse
+		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // best-effort drain for keep-alive; a failure here is harmless
+		return nil
+	}
+
+	//nolint:errcheck // best-effort read of the error body; a partial or empty read still yields a usable message
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
+	apiErr := fmt.Errorf("bitbucket: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+
+	// 3xx and 4xx responses indicate a configuration issue (bad credentials,
+	// missing permissions, unknown pull request) and should not be retried
+	if resp.StatusCode < http.StatusInternalServerError {
+		return helpers.WrapPermanent(apiErr)
+	}
+
+	// 5xx: Server errors are transient and should be retried
+	return apiErr
+}
@@ -0,0 +1,156 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type capturedRequest struct {
+	URL  string
+	Auth string
+	Body map[string]any
+}
+
+func capturingClient(t *testing.T, received *capturedRequest) *http.Client {
+	return &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			received.URL = req.URL.String()
+			received.Auth = req.Header.Get("Authorization")
+
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(data, &received.Body))
+
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusCreated)
+			return resp.Result(), nil
+		}),
+	}
+}
+
+func TestPosterPostCloud(t *testing.T) {
+	var received capturedRequest
+	poster, err := NewPoster(Config{
+		Workspace:   "acme",
+		Repository:  "gitops",
+		PullRequest: 5,
+		Username:    "deploy-bot",
+		Token:       "app-password",
+		HTTPClient:  capturingClient(t, &received),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, poster.Post(context.Background(), "hello world"))
+
+	assert.Equal(t, "https://api.bitbucket.org/2.0/repositories/acme/gitops/pullrequests/5/comments", received.URL)
+	assert.Equal(t, "Basic ZGVwbG95LWJvdDphcHAtcGFzc3dvcmQ=", received.Auth)
+	assert.Equal(t, map[string]any{"content": map[string]any{"raw": "hello world"}}, received.Body)
+}
+
+func TestPosterPostDataCenter(t *testing.T) {
+	var received capturedRequest
+	poster, err := NewPoster(Config{
+		BaseURL:     "https://bitbucket.example.com/scm",
+		DataCenter:  true,
+		Workspace:   "OPS",
+		Repository:  "gitops",
+		PullRequest: 9,
+		Token:       "http-access-token",
+		HTTPClient:  capturingClient(t, &received),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, poster.Post(context.Background(), "hello world"))
+
+	assert.Equal(t, "https://bitbucket.example.com/scm/rest/api/1.0/projects/OPS/repos/gitops/pull-requests/9/comments", received.URL)
+	assert.Equal(t, "Bearer http-access-token", received.Auth)
+	assert.Equal(t, map[string]any{"text": "hello world"}, received.Body)
+}
+
+func TestPosterMaxBodyLength(t *testing.T) {
+	poster, err := NewPoster(Config{Workspace: "acme", Repository: "gitops", PullRequest: 1, Token: "token"})
+	require.NoError(t, err)
+	assert.Equal(t, 32768, poster.MaxBodyLength())
+}
+
+func TestNewPosterValidatesConfig(t *testing.T) {
+	valid := Config{Workspace: "acme", Repository: "gitops", PullRequest: 1, Token: "token"}
+	_, err := NewPoster(valid)
+	require.NoError(t, err)
+
+	for name, mutate := range map[string]func(*Config){
+		"missing token":                func(c *Config) { c.Token = "" },
+		"missing workspace":            func(c *Config) { c.Workspace = "" },
+		"missing repository":           func(c *Config) { c.Repository = "" },
+		"missing pull request":         func(c *Config) { c.PullRequest = 0 },
+		"data center without base URL": func(c *Config) { c.DataCenter = true },
+		"unparsable base URL":          func(c *Config) { c.BaseURL = "://bad" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid
+			mutate(&cfg)
+			_, err := NewPoster(cfg)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPosterPost4xxIsPermanentError(t *testing.T) {
+	attempts := 0
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusUnauthorized)
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{Workspace: "acme", Repository: "gitops", PullRequest: 1, Token: "token", HTTPClient: client})
+	require.NoError(t, err)
+
+	err = poster.Post(context.Background(), "body")
+	require.Error(t, err)
+	assert.True(t, helpers.IsPermanent(err))
+	assert.Equal(t, 1, attempts) // Should not retry 4xx
+}
+
+func TestPosterPost5xxIsRetried(t *testing.T) {
+	attempts := 0
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			resp := httptest.NewRecorder()
+			if attempts < 2 {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+			} else {
+				resp.WriteHeader(http.StatusCreated)
+			}
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{Workspace: "acme", Repository: "gitops", PullRequest: 1, Token: "token", HTTPClient: client})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, poster.Post(ctx, "body"))
+	assert.Equal(t, 2, attempts)
+}
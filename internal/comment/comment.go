@@ -9,3 +9,46 @@ type Poster interface {
 	// cancellation and timeout control.
 	Post(ctx context.Context, body string) error
 }
+
+// BodyLimiter is implemented by posters whose upstream system limits the size
+// of a single comment. Results that do not fit are split across comments.
+type BodyLimiter interface {
+	// MaxBodyLength returns the largest comment body, in bytes, the upstream
+	// system accepts. Platforms that count characters report their character
+	// limit: a body within it in bytes is within it in characters as well.
+	MaxBodyLength() int
+}
+
+// Note is a comment previously published on the upstream system.
+type Note struct {
+	ID   int64
+	Body string
+}
+
+// Upserter is implemented by posters that can find, edit and delete the
+// comments they published, so results can be updated in place on later runs
+// instead of being appended.
+type Upserter interface {
+	// Find returns the comments whose body contains marker, oldest first.
+	Find(ctx context.Context, marker string) ([]Note, error)
+	// Update replaces the body of the comment with the given ID.
+	Update(ctx context.Context, id int64, body string) error
+	// Delete removes the comment with the given ID.
+	Delete(ctx context.Context, id int64) error
+}
+
+// Reopener is implemented by upserters whose comments reviewers can resolve,
+// such as discussion threads.
+type Reopener interface {
+	// Reopen unresolves the thread of the comment with the given ID if it has
+	// been resolved.
+	Reopen(ctx context.Context, id int64) error
+}
+
+// FilePoster is implemented by posters that can attach a new comment to a
+// file changed by the pull request instead of to the pull request as a whole.
+type FilePoster interface {
+	// PostOnFile publishes body attached to path, a repository-relative file
+	// of the change under review.
+	PostOnFile(ctx context.Context, body, path string) error
+}
@@ -0,0 +1,289 @@
+// Package gitea implements the comment.Poster interface for posting diff
+// comments to Gitea and Forgejo pull requests via their REST API.
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/comment"
+	"github.com/shini4i/argo-compare/internal/helpers"
+)
+
+const (
+	defaultAPIPrefix      = "/api/v1"
+	maxErrorResponseBytes = 4096
+	defaultClientTimeout  = 15 * time.Second
+)
+
+// Config describes settings required to post comments to a Gitea or Forgejo pull request.
+type Config struct {
+	BaseURL     string // Server root, e.g. https://codeberg.org.
+	Token       string
+	Repository  string // Repository in owner/name form.
+	PullRequest int
+	CABundle    string // PEM file with additional CAs trusted for BaseURL.
+	HTTPClient  *http.Client
+	Timeout     time.Duration
+}
+
+type Poster struct {
+	client      *http.Client
+	baseURL     *url.URL
+	owner       string
+	repo        string
+	pullRequest int
+	token       string
+	userID      int64 // Author of the token, resolved on first Find.
+}
+
+// Ensure Poster implements comment.Poster and can update its comments in place.
+var (
+	_ comment.Poster   = (*Poster)(nil)
+	_ comment.Upserter = (*Poster)(nil)
+)
+
+// NewPoster creates a Poster configured to comment on a Gitea or Forgejo pull request using cfg.
+// It validates that cfg.BaseURL, cfg.Token, cfg.Repository (owner/name) and cfg.PullRequest are provided and parses
+// cfg.BaseURL; returns an error if validation, URL parsing or loading cfg.CABundle fails. If cfg.HTTPClient is nil,
+// a default http.Client is created using cfg.Timeout (or the package default) that trusts the system roots and the
+// certificates in cfg.CABundle.
+func NewPoster(cfg Config) (*Poster, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("gitea: base URL is required")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("gitea: token is required")
+	}
+	owner, repo, ok := strings.Cut(cfg.Repository, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("gitea: repository must be in owner/name form, got %q", cfg.Repository)
+	}
+	if cfg.PullRequest <= 0 {
+		return nil, fmt.Errorf("gitea: pull request number is required")
+	}
+
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("gitea: parse base URL: %w", err)
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client, err = newHTTPClient(cfg.Timeout, cfg.CABundle)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Poster{
+		client:      client,
+		baseURL:     base,
+		owner:       owner,
+		repo:        repo,
+		pullRequest: cfg.PullRequest,
+		token:       cfg.Token,
+	}, nil
+}
+
+// newHTTPClient builds the default client, trusting the certificates in
+// caBundle in addition to the system roots.
+func newHTTPClient(timeout time.Duration, caBundle string) (*http.Client, error) {
+	if timeout <= 0 {
+		timeout = defaultClientTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	if caBundle == "" {
+		return client, nil
+	}
+
+	pem, err := os.ReadFile(caBundle) // #nosec G304 -- path is operator-configured
+	if err != nil {
+		return nil, fmt.Errorf("gitea: read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("gitea: CA bundle %s contains no PEM certificates", caBundle)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	client.Transport = transport
+	return client, nil
+}
+
+// Post sends the supplied comment body to the pull request's conversation.
+// Pull requests share their number space with issues, so the comment is
+// created through the issue comments endpoint. Network errors and 5xx server
+// errors are retried with exponential backoff.
+func (p *Poster) Post(ctx context.Context, body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("gitea: comment body is empty")
+	}
+	return p.send(ctx, http.MethodPost, p.issueEndpoint("comments"), map[string]string{"body": body}, nil)
+}
+
+// Find implements comment.Upserter. The API returns all of the pull
+// request's comments at once, oldest first. Comments written by anyone but
+// the token's user may quote a marker but are not ours to edit, so they are
+// skipped.
+func (p *Poster) Find(ctx context.Context, marker string) ([]comment.Note, error) {
+	userID, err := p.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []issueComment
+	if err := p.send(ctx, http.MethodGet, p.issueEndpoint("comments"), nil, &comments); err != nil {
+		return nil, err
+	}
+
+	var found []comment.Note
+	for _, existing := range comments {
+		if existing.User.ID == userID && strings.Contains(existing.Body, marker) {
+			found = append(found, comment.Note{ID: existing.ID, Body: existing.Body})
+		}
+	}
+	return found, nil
+}
+
+// issueComment is the part of an issue comment Find needs.
+type issueComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+	User user   `json:"user"`
+}
+
+// user is an account as embedded in comments and returned by /user.
+type user struct {
+	ID int64 `json:"id"`
+}
+
+// currentUserID returns the ID of the account the token authenticates as,
+// fetching it on first use.
+func (p *Poster) currentUserID(ctx context.Context) (int64, error) {
+	if p.userID != 0 {
+		return p.userID, nil
+	}
+	endpoint := *p.baseURL
+	endpoint.Path = path.Join(endpoint.Path, defaultAPIPrefix, "user")
+
+	var current user
+	if err := p.send(ctx, http.MethodGet, endpoint, nil, &current); err != nil {
+		return 0, fmt.Errorf("gitea: resolve token user: %w", err)
+	}
+	if current.ID == 0 {
+		return 0, fmt.Errorf("gitea: resolve token user: response has no user ID")
+	}
+	p.userID = current.ID
+	return p.userID, nil
+}
+
+// Update implements comment.Upserter.
+func (p *Poster) Update(ctx context.Context, id int64, body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("gitea: comment body is empty")
+	}
+	return p.send(ctx, http.MethodPatch, p.commentEndpoint(id), map[string]string{"body": body}, nil)
+}
+
+// Delete implements comment.Upserter.
+func (p *Poster) Delete(ctx context.Context, id int64) error {
+	return p.send(ctx, http.MethodDelete, p.commentEndpoint(id), nil, nil)
+}
+
+// issueEndpoint returns the URL of a resource below the pull request's issue.
+func (p *Poster) issueEndpoint(resource string) url.URL {
+	endpoint := *p.baseURL
+	endpoint.Path = path.Join(endpoint.Path, defaultAPIPrefix, "repos", p.owner, p.repo, "issues", strconv.Itoa(p.pullRequest), resource)
+	return endpoint
+}
+
+// commentEndpoint returns the URL of a single issue comment of the repository.
+func (p *Poster) commentEndpoint(id int64) url.URL {
+	endpoint := *p.baseURL
+	endpoint.Path = path.Join(endpoint.Path, defaultAPIPrefix, "repos", p.owner, p.repo, "issues", "comments", strconv.FormatInt(id, 10))
+	return endpoint
+}
+
+// send performs a request with payload encoded as JSON, retrying transient
+// failures, and decodes the response into out when it is non-nil.
+func (p *Poster) send(ctx context.Context, method string, endpoint url.URL, payload, out any) error {
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("gitea: marshal payload: %w", err)
+		}
+	}
+
+	retryCfg := helpers.DefaultRetryConfig()
+	return helpers.WithRetry(ctx, retryCfg, func() error {
+		return p.doRequest(ctx, method, endpoint.String(), data, out)
+	})
+}
+
+// doRequest performs the HTTP request and returns an error only for retryable failures.
+// Client errors (4xx) are wrapped in a permanentError to prevent retry.
+func (p *Poster) doRequest(ctx context.Context, method, endpoint string, payload []byte, out any) error {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("gitea: build request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "token "+p.token)
+
+	resp, err := p.client.Do(req) // #nosec G107 G704 -- endpoint is built from operator-configured BaseURL, not user input
+	if err != nil {
+		// Network errors are retryable
+		return fmt.Errorf("gitea: perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return helpers.WrapPermanent(fmt.Errorf("gitea: decode response: %w", err))
+			}
+			return nil
+		}
+		// Success - drain response body for connection reuse
+		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // best-effort drain for keep-alive; a failure here is harmless
+		return nil
+	}
+
+	//nolint:errcheck // best-effort read of the error body; a partial or empty read still yields a usable message
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
+	apiErr := fmt.Errorf("gitea: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+
+	// 3xx and 4xx responses indicate a configuration issue (bad token, missing
+	// permissions, unknown pull request) and should not be retried
+	if resp.StatusCode < http.StatusInternalServerError {
+		return helpers.WrapPermanent(apiErr)
+	}
+
+	// 5xx: Server errors are transient and should be retried
+	return apiErr
+}
@@ -0,0 +1,220 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/comment"
+	"github.com/shini4i/argo-compare/internal/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestPosterPost(t *testing.T) {
+	var received struct {
+		Method string
+		URL    string
+		Auth   string
+		Body   map[string]string
+	}
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			received.Method = req.Method
+			received.URL = req.URL.String()
+			received.Auth = req.Header.Get("Authorization")
+
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(data, &received.Body))
+
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusCreated)
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{
+		BaseURL:     "https://forgejo.example.com/git",
+		Token:       "token",
+		Repository:  "platform/gitops",
+		PullRequest: 12,
+		HTTPClient:  client,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, poster.Post(context.Background(), "hello world"))
+
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "https://forgejo.example.com/git/api/v1/repos/platform/gitops/issues/12/comments", received.URL)
+	assert.Equal(t, "token token", received.Auth)
+	assert.Equal(t, "hello world", received.Body["body"])
+}
+
+func TestPosterPostTrustsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/repos/platform/gitops/issues/3/comments", r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	cfg := Config{BaseURL: server.URL, Token: "token", Repository: "platform/gitops", PullRequest: 3}
+
+	untrusted, err := NewPoster(cfg)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.Error(t, untrusted.Post(ctx, "body"), "the self-signed certificate is not trusted without the bundle")
+
+	cfg.CABundle = bundle
+	trusted, err := NewPoster(cfg)
+	require.NoError(t, err)
+	require.NoError(t, trusted.Post(context.Background(), "body"))
+}
+
+func TestNewPosterValidatesConfig(t *testing.T) {
+	valid := Config{BaseURL: "https://gitea.example.com", Token: "token", Repository: "octo/deploy", PullRequest: 1}
+	_, err := NewPoster(valid)
+	require.NoError(t, err)
+
+	for name, mutate := range map[string]func(*Config){
+		"missing base URL":          func(c *Config) { c.BaseURL = "" },
+		"missing token":             func(c *Config) { c.Token = "" },
+		"repository not owner/name": func(c *Config) { c.Repository = "deploy" },
+		"missing pull request":      func(c *Config) { c.PullRequest = 0 },
+		"unparsable base URL":       func(c *Config) { c.BaseURL = "://bad" },
+		"missing CA bundle":         func(c *Config) { c.CABundle = filepath.Join(t.TempDir(), "missing.pem") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid
+			mutate(&cfg)
+			_, err := NewPoster(cfg)
+			require.Error(t, err)
+		})
+	}
+
+	bundle := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(bundle, []byte("not a certificate"), 0o600))
+	cfg := valid
+	cfg.CABundle = bundle
+	_, err = NewPoster(cfg)
+	require.ErrorContains(t, err, "no PEM certificates")
+}
+
+func TestPosterPost4xxIsPermanentError(t *testing.T) {
+	attempts := 0
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusUnauthorized)
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{BaseURL: "https://gitea.example.com", Token: "token", Repository: "octo/deploy", PullRequest: 1, HTTPClient: client})
+	require.NoError(t, err)
+
+	err = poster.Post(context.Background(), "body")
+	require.Error(t, err)
+	assert.True(t, helpers.IsPermanent(err))
+	assert.Equal(t, 1, attempts) // Should not retry 4xx
+}
+
+func TestPosterPost5xxIsRetried(t *testing.T) {
+	attempts := 0
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			resp := httptest.NewRecorder()
+			if attempts < 2 {
+				resp.WriteHeader(http.StatusInternalServerError)
+			} else {
+				resp.WriteHeader(http.StatusCreated)
+			}
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{BaseURL: "https://gitea.example.com", Token: "token", Repository: "octo/deploy", PullRequest: 1, HTTPClient: client})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, poster.Post(ctx, "body"))
+	assert.Equal(t, 2, attempts)
+}
+
+func TestPosterFindUpdateDelete(t *testing.T) {
+	type request struct {
+		Method string
+		Path   string
+		Body   map[string]string
+	}
+	var requests []request
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			recorded := request{Method: req.Method, Path: req.URL.Path}
+			if req.Body != nil {
+				data, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				if len(data) > 0 {
+					require.NoError(t, json.Unmarshal(data, &recorded.Body))
+				}
+			}
+			requests = append(requests, recorded)
+
+			resp := httptest.NewRecorder()
+			switch {
+			case req.Method == http.MethodGet && req.URL.Path == "/api/v1/user":
+				_, err := resp.WriteString(`{"id":7,"login":"deploy-bot"}`)
+				require.NoError(t, err)
+			case req.Method == http.MethodGet:
+				_, err := resp.WriteString(`[
+					{"id":1,"body":"LGTM","user":{"id":8}},
+					{"id":2,"body":"diff <!-- marker -->","user":{"id":7}},
+					{"id":3,"body":"<!-- marker --> part 2","user":{"id":7}},
+					{"id":4,"body":"quoting <!-- marker -->","user":{"id":8}}
+				]`)
+				require.NoError(t, err)
+			}
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{BaseURL: "https://gitea.example.com", Token: "token", Repository: "octo/deploy", PullRequest: 42, HTTPClient: client})
+	require.NoError(t, err)
+
+	found, err := poster.Find(context.Background(), "<!-- marker -->")
+	require.NoError(t, err)
+	assert.Equal(t, []comment.Note{{ID: 2, Body: "diff <!-- marker -->"}, {ID: 3, Body: "<!-- marker --> part 2"}}, found, "comments of other users are skipped")
+
+	require.NoError(t, poster.Update(context.Background(), 2, "new body"))
+	require.NoError(t, poster.Delete(context.Background(), 3))
+
+	assert.Equal(t, []request{
+		{Method: http.MethodGet, Path: "/api/v1/user"},
+		{Method: http.MethodGet, Path: "/api/v1/repos/octo/deploy/issues/42/comments"},
+		{Method: http.MethodPatch, Path: "/api/v1/repos/octo/deploy/issues/comments/2", Body: map[string]string{"body": "new body"}},
+		{Method: http.MethodDelete, Path: "/api/v1/repos/octo/deploy/issues/comments/3"},
+	}, requests)
+}
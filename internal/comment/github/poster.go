@@ -0,0 +1,300 @@
+// Package github implements the comment.Poster interface for posting
+// diff comments to GitHub pull requests via the GitHub REST API.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/comment"
+	"github.com/shini4i/argo-compare/internal/helpers"
+)
+
+const (
+	// DefaultBaseURL is the REST API root of github.com. GitHub Enterprise
+	// Server exposes it under https://<host>/api/v3.
+	DefaultBaseURL = "https://api.github.com"
+	// commentLengthLimit is GitHub's limit of 65,536 characters per comment.
+	commentLengthLimit    = 65536
+	apiVersion            = "2022-11-28"
+	commentsPerPage       = 100
+	maxErrorResponseBytes = 4096
+	defaultClientTimeout  = 15 * time.Second
+	// actionsLogin is the author of comments posted with the GITHUB_TOKEN of
+	// a GitHub Actions run, an installation token that cannot read /user.
+	actionsLogin = "github-actions[bot]"
+)
+
+// Config describes settings required to post comments to a GitHub pull request.
+type Config struct {
+	BaseURL     string // REST API root; defaults to DefaultBaseURL.
+	Token       string
+	Repository  string // Repository in owner/name form.
+	PullRequest int
+	HTTPClient  *http.Client
+	Timeout     time.Duration
+}
+
+type Poster struct {
+	client      *http.Client
+	baseURL     *url.URL
+	owner       string
+	repo        string
+	pullRequest int
+	token       string
+	login       string // Author of the token, resolved on first Find.
+}
+
+// Ensure Poster implements comment.Poster, reports GitHub's size limit and
+// can update its comments in place.
+var (
+	_ comment.Poster      = (*Poster)(nil)
+	_ comment.BodyLimiter = (*Poster)(nil)
+	_ comment.Upserter    = (*Poster)(nil)
+)
+
+// NewPoster creates a Poster configured to comment on a GitHub pull request using cfg.
+// It validates that cfg.Token, cfg.Repository (owner/name) and cfg.PullRequest are provided and parses cfg.BaseURL,
+// which defaults to the github.com API; returns an error if validation or URL parsing fails. If cfg.HTTPClient is nil,
+// a default http.Client is created using cfg.Timeout (or the package default).
+func NewPoster(cfg Config) (*Poster, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("github: token is required")
+	}
+	owner, repo, ok := strings.Cut(cfg.Repository, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("github: repository must be in owner/name form, got %q", cfg.Repository)
+	}
+	if cfg.PullRequest <= 0 {
+		return nil, fmt.Errorf("github: pull request number is required")
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("github: parse base URL: %w", err)
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = defaultClientTimeout
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+
+	return &Poster{
+		client:      client,
+		baseURL:     base,
+		owner:       owner,
+		repo:        repo,
+		pullRequest: cfg.PullRequest,
+		token:       cfg.Token,
+	}, nil
+}
+
+// MaxBodyLength implements comment.BodyLimiter.
+func (p *Poster) MaxBodyLength() int {
+	return commentLengthLimit
+}
+
+// Post sends the supplied comment body to the pull request's conversation.
+// Pull requests are issues in GitHub's API, so the comment is
+// created through the issue comments endpoint. Network errors and 5xx server
+// errors are retried with exponential backoff.
+func (p *Poster) Post(ctx context.Context, body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("github: comment body is empty")
+	}
+	return p.send(ctx, http.MethodPost, p.issueEndpoint("comments"), map[string]string{"body": body}, nil)
+}
+
+// Find implements comment.Upserter. It pages through the pull request's
+// comments, which the API returns oldest first, skipping comments written by
+// anyone but the token's user, which may quote a marker but are not ours to
+// edit.
+func (p *Poster) Find(ctx context.Context, marker string) ([]comment.Note, error) {
+	login, err := p.currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []comment.Note
+	for page := 1; ; page++ {
+		endpoint := p.issueEndpoint("comments")
+		endpoint.RawQuery = url.Values{
+			"per_page": {strconv.Itoa(commentsPerPage)},
+			"page":     {strconv.Itoa(page)},
+		}.Encode()
+
+		var comments []issueComment
+		if err := p.send(ctx, http.MethodGet, endpoint, nil, &comments); err != nil {
+			return nil, err
+		}
+		for _, existing := range comments {
+			if existing.User.Login == login && strings.Contains(existing.Body, marker) {
+				found = append(found, comment.Note{ID: existing.ID, Body: existing.Body})
+			}
+		}
+		if len(comments) < commentsPerPage {
+			return found, nil
+		}
+	}
+}
+
+// issueComment is the part of an issue comment Find needs.
+type issueComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+	User user   `json:"user"`
+}
+
+// user is a GitHub account as embedded in comments and returned by /user.
+type user struct {
+	Login string `json:"login"`
+}
+
+// currentLogin returns the login of the account the token authenticates as,
+// fetching it on first use. Installation tokens are refused by /user; those
+// are assumed to be the GITHUB_TOKEN of a GitHub Actions run.
+func (p *Poster) currentLogin(ctx context.Context) (string, error) {
+	if p.login != "" {
+		return p.login, nil
+	}
+	endpoint := *p.baseURL
+	endpoint.Path = path.Join(endpoint.Path, "user")
+
+	var current user
+	err := p.send(ctx, http.MethodGet, endpoint, nil, &current)
+	var statusErr *statusError
+	switch {
+	case errors.As(err, &statusErr) && statusErr.code == http.StatusForbidden:
+		current.Login = actionsLogin
+	case err != nil:
+		return "", fmt.Errorf("github: resolve token user: %w", err)
+	case current.Login == "":
+		return "", fmt.Errorf("github: resolve token user: response has no login")
+	}
+	p.login = current.Login
+	return p.login, nil
+}
+
+// Update implements comment.Upserter.
+func (p *Poster) Update(ctx context.Context, id int64, body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("github: comment body is empty")
+	}
+	return p.send(ctx, http.MethodPatch, p.commentEndpoint(id), map[string]string{"body": body}, nil)
+}
+
+// Delete implements comment.Upserter.
+func (p *Poster) Delete(ctx context.Context, id int64) error {
+	return p.send(ctx, http.MethodDelete, p.commentEndpoint(id), nil, nil)
+}
+
+// issueEndpoint returns the URL of a resource below the pull request's issue.
+func (p *Poster) issueEndpoint(resource string) url.URL {
+	endpoint := *p.baseURL
+	endpoint.Path = path.Join(endpoint.Path, "repos", p.owner, p.repo, "issues", strconv.Itoa(p.pullRequest), resource)
+	return endpoint
+}
+
+// commentEndpoint returns the URL of a single issue comment of the repository.
+func (p *Poster) commentEndpoint(id int64) url.URL {
+	endpoint := *p.baseURL
+	endpoint.Path = path.Join(endpoint.Path, "repos", p.owner, p.repo, "issues", "comments", strconv.FormatInt(id, 10))
+	return endpoint
+}
+
+// send performs a request with payload encoded as JSON, retrying transient
+// failures, and decodes the response into out when it is non-nil.
+func (p *Poster) send(ctx context.Context, method string, endpoint url.URL, payload, out any) error {
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("github: marshal payload: %w", err)
+		}
+	}
+
+	retryCfg := helpers.DefaultRetryConfig()
+	return helpers.WithRetry(ctx, retryCfg, func() error {
+		return p.doRequest(ctx, method, endpoint.String(), data, out)
+	})
+}
+
+// doRequest performs the HTTP request and returns an error only for retryable failures.
+// Client errors (4xx) are wrapped in a permanentError to prevent retry.
+func (p *Poster) doRequest(ctx context.Context, method, endpoint string, payload []byte, out any) error {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("github: build request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.client.Do(req) // #nosec G107 G704 -- endpoint is built from operator-configured BaseURL, not user input
+	if err != nil {
+		// Network errors are retryable
+		return fmt.Errorf("github: perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return helpers.WrapPermanent(fmt.Errorf("github: decode response: %w", err))
+			}
+			return nil
+		}
+		// Success - drain response body for connection reuse
+		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // best-effort drain for keep-alive; a failure here is harmless
+		return nil
+	}
+
+	//nolint:errcheck // best-effort read of the error body; a partial or empty read still yields a usable message
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
+	apiErr := &statusError{code: resp.StatusCode, status: resp.Status, body: strings.TrimSpace(string(respBody))}
+
+	// 3xx and 4xx responses indicate a configuration issue (bad token, missing
+	// permissions, unknown pull request) and should not be retried
+	if resp.StatusCode < http.StatusInternalServerError {
+		return helpers.WrapPermanent(apiErr)
+	}
+
+	// 5xx: Server errors are transient and should be retried
+	return apiErr
+}
+
+// statusError reports an unexpected response status.
+type statusError struct {
+	code   int
+	status string
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("github: unexpected status %s: %s", e.status, e.body)
+}
@@ -0,0 +1,270 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/comment"
+	"github.com/shini4i/argo-compare/internal/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestPosterPost(t *testing.T) {
+	var received struct {
+		Method  string
+		URL     string
+		Body    map[string]string
+		Auth    string
+		Accept  string
+		Version string
+	}
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			received.Method = req.Method
+			received.URL = req.URL.String()
+			received.Auth = req.Header.Get("Authorization")
+			received.Accept = req.Header.Get("Accept")
+			received.Version = req.Header.Get("X-GitHub-Api-Version")
+
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(data, &received.Body))
+
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusCreated)
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{
+		Token:       "token",
+		Repository:  "octo/deploy",
+		PullRequest: 42,
+		HTTPClient:  client,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, poster.Post(context.Background(), "hello world"))
+
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "https://api.github.com/repos/octo/deploy/issues/42/comments", received.URL)
+	assert.Equal(t, "Bearer token", received.Auth)
+	assert.Equal(t, "application/vnd.github+json", received.Accept)
+	assert.Equal(t, apiVersion, received.Version)
+	assert.Equal(t, "hello world", received.Body["body"])
+}
+
+func TestPosterPostEnterpriseBaseURL(t *testing.T) {
+	var receivedURL string
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			receivedURL = req.URL.String()
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusCreated)
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{
+		BaseURL:     "https://github.example.com/api/v3",
+		Token:       "token",
+		Repository:  "platform/gitops",
+		PullRequest: 7,
+		HTTPClient:  client,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, poster.Post(context.Background(), "body"))
+	assert.Equal(t, "https://github.example.com/api/v3/repos/platform/gitops/issues/7/comments", receivedURL)
+}
+
+func TestPosterMaxBodyLength(t *testing.T) {
+	poster, err := NewPoster(Config{Token: "token", Repository: "octo/deploy", PullRequest: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 65536, poster.MaxBodyLength())
+}
+
+func TestNewPosterValidatesConfig(t *testing.T) {
+	_, err := NewPoster(Config{})
+	require.Error(t, err)
+
+	_, err = NewPoster(Config{Token: "token"})
+	require.Error(t, err)
+
+	_, err = NewPoster(Config{Token: "token", Repository: "deploy", PullRequest: 1})
+	require.Error(t, err)
+
+	_, err = NewPoster(Config{Token: "token", Repository: "octo/deploy/extra", PullRequest: 1})
+	require.Error(t, err)
+
+	_, err = NewPoster(Config{Token: "token", Repository: "octo/deploy"})
+	require.Error(t, err)
+
+	_, err = NewPoster(Config{BaseURL: "://bad", Token: "token", Repository: "octo/deploy", PullRequest: 1})
+	require.Error(t, err)
+}
+
+func TestPosterPostRejectsEmptyBody(t *testing.T) {
+	poster, err := NewPoster(Config{Token: "token", Repository: "octo/deploy", PullRequest: 1})
+	require.NoError(t, err)
+	require.Error(t, poster.Post(context.Background(), "  \n"))
+}
+
+func TestPosterPost4xxIsPermanentError(t *testing.T) {
+	attempts := 0
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			resp := httptest.NewRecorder()
+			http.Error(resp, `{"message":"Resource not accessible by integration"}`, http.StatusForbidden)
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{Token: "token", Repository: "octo/deploy", PullRequest: 1, HTTPClient: client})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = poster.Post(ctx, "body")
+	require.Error(t, err)
+	assert.True(t, helpers.IsPermanent(err))
+	assert.Contains(t, err.Error(), "Resource not accessible by integration")
+	assert.Equal(t, 1, attempts) // Should not retry 4xx
+}
+
+func TestPosterPost5xxIsRetried(t *testing.T) {
+	attempts := 0
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			resp := httptest.NewRecorder()
+			if attempts < 3 {
+				resp.WriteHeader(http.StatusBadGateway)
+			} else {
+				resp.WriteHeader(http.StatusCreated)
+			}
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{Token: "token", Repository: "octo/deploy", PullRequest: 1, HTTPClient: client})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, poster.Post(ctx, "body"))
+	assert.Equal(t, 3, attempts)
+}
+
+func TestPosterFindUpdateDelete(t *testing.T) {
+	type request struct {
+		Method string
+		Path   string
+		Body   map[string]string
+	}
+	var requests []request
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			recorded := request{Method: req.Method, Path: req.URL.Path}
+			if req.Body != nil {
+				data, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				if len(data) > 0 {
+					require.NoError(t, json.Unmarshal(data, &recorded.Body))
+				}
+			}
+			requests = append(requests, recorded)
+
+			resp := httptest.NewRecorder()
+			switch {
+			case req.Method == http.MethodGet && req.URL.Path == "/user":
+				_, err := resp.WriteString(`{"login":"deploy-bot"}`)
+				require.NoError(t, err)
+			case req.Method == http.MethodGet:
+				_, err := resp.WriteString(`[
+					{"id":1,"body":"LGTM","user":{"login":"octocat"}},
+					{"id":2,"body":"diff <!-- marker -->","user":{"login":"deploy-bot"}},
+					{"id":3,"body":"<!-- marker --> part 2","user":{"login":"deploy-bot"}},
+					{"id":4,"body":"quoting <!-- marker -->","user":{"login":"octocat"}}
+				]`)
+				require.NoError(t, err)
+			}
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{Token: "token", Repository: "octo/deploy", PullRequest: 42, HTTPClient: client})
+	require.NoError(t, err)
+
+	found, err := poster.Find(context.Background(), "<!-- marker -->")
+	require.NoError(t, err)
+	assert.Equal(t, []comment.Note{{ID: 2, Body: "diff <!-- marker -->"}, {ID: 3, Body: "<!-- marker --> part 2"}}, found, "comments of other users are skipped")
+
+	require.NoError(t, poster.Update(context.Background(), 2, "new body"))
+	require.NoError(t, poster.Delete(context.Background(), 3))
+
+	assert.Equal(t, []request{
+		{Method: http.MethodGet, Path: "/user"},
+		{Method: http.MethodGet, Path: "/repos/octo/deploy/issues/42/comments"},
+		{Method: http.MethodPatch, Path: "/repos/octo/deploy/issues/comments/2", Body: map[string]string{"body": "new body"}},
+		{Method: http.MethodDelete, Path: "/repos/octo/deploy/issues/comments/3"},
+	}, requests)
+}
+
+func TestPosterFindAsActionsToken(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			if req.URL.Path == "/user" {
+				resp.WriteHeader(http.StatusForbidden)
+				_, _ = resp.WriteString(`{"message":"Resource not accessible by integration"}`)
+				return resp.Result(), nil
+			}
+			_, _ = resp.WriteString(`[
+				{"id":1,"body":"<!-- marker -->","user":{"login":"github-actions[bot]"}},
+				{"id":2,"body":"<!-- marker -->","user":{"login":"octocat"}}
+			]`)
+			return resp.Result(), nil
+		}),
+	}
+	poster, err := NewPoster(Config{Token: "token", Repository: "octo/deploy", PullRequest: 42, HTTPClient: client})
+	require.NoError(t, err)
+
+	found, err := poster.Find(context.Background(), "<!-- marker -->")
+	require.NoError(t, err)
+	assert.Equal(t, []comment.Note{{ID: 1, Body: "<!-- marker -->"}}, found)
+}
+
+func TestPosterFindFailsWithoutTokenUser(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusUnauthorized)
+			_, _ = resp.WriteString(`{"message":"Bad credentials"}`)
+			return resp.Result(), nil
+		}),
+	}
+	poster, err := NewPoster(Config{Token: "token", Repository: "octo/deploy", PullRequest: 42, HTTPClient: client})
+	require.NoError(t, err)
+
+	_, err = poster.Find(context.Background(), "<!-- marker -->")
+	require.ErrorContains(t, err, "github: resolve token user")
+	require.ErrorContains(t, err, "401")
+}
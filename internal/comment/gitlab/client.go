@@ -0,0 +1,144 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/helpers"
+)
+
+const (
+	defaultAPIPrefix      = "/api/v4"
+	privateTokenHeader    = "PRIVATE-TOKEN"
+	maxErrorResponseBytes = 4096
+	defaultClientTimeout  = 15 * time.Second
+)
+
+// apiClient performs authenticated requests against the API of one GitLab
+// project. It is shared by the comment poster and the status reporter.
+type apiClient struct {
+	client    *http.Client
+	baseURL   *url.URL
+	projectID string
+	token     string
+	apiPrefix string
+}
+
+// newAPIClient parses baseURL and falls back to the package defaults for an
+// empty apiPrefix and a nil httpClient, which is created with timeout.
+func newAPIClient(baseURL, token, projectID, apiPrefix string, httpClient *http.Client, timeout time.Duration) (apiClient, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return apiClient{}, fmt.Errorf("gitlab: parse base URL: %w", err)
+	}
+
+	if httpClient == nil {
+		if timeout <= 0 {
+			timeout = defaultClientTimeout
+		}
+		httpClient = &http.Client{Timeout: timeout}
+	}
+
+	if apiPrefix == "" {
+		apiPrefix = defaultAPIPrefix
+	}
+
+	return apiClient{
+		client:    httpClient,
+		baseURL:   base,
+		projectID: projectID,
+		token:     token,
+		apiPrefix: apiPrefix,
+	}, nil
+}
+
+// endpoint returns the URL of the API resource at the given path segments.
+func (c apiClient) endpoint(segments ...string) url.URL {
+	endpoint := *c.baseURL
+	endpoint.Path = path.Join(append([]string{endpoint.Path, c.apiPrefix}, segments...)...)
+	return endpoint
+}
+
+// projectEndpoint returns the URL of the project, extended by the given path
+// segments.
+func (c apiClient) projectEndpoint(segments ...string) url.URL {
+	return c.endpoint(append([]string{"projects", url.PathEscape(c.projectID)}, segments...)...)
+}
+
+// send performs a request with payload encoded as JSON, retrying transient
+// failures, and decodes the response into out when it is non-nil.
+func (c apiClient) send(ctx context.Context, method string, endpoint url.URL, payload, out any) error {
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("gitlab: marshal payload: %w", err)
+		}
+	}
+
+	retryCfg := helpers.DefaultRetryConfig()
+	return helpers.WithRetry(ctx, retryCfg, func() error {
+		return c.doRequest(ctx, method, endpoint.String(), data, out)
+	})
+}
+
+// doRequest performs the HTTP request and returns an error only for retryable failures.
+// Client errors (4xx) are wrapped in a permanentError to prevent retry.
+func (c apiClient) doRequest(ctx context.Context, method, endpoint string, payload []byte, out any) error {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("gitlab: build request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set(privateTokenHeader, c.token)
+
+	resp, err := c.client.Do(req) // #nosec G107 G704 -- endpoint is built from operator-configured BaseURL, not user input
+	if err != nil {
+		// Network errors are retryable
+		return fmt.Errorf("gitlab: perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return helpers.WrapPermanent(fmt.Errorf("gitlab: decode response: %w", err))
+			}
+			return nil
+		}
+		// Success - drain response body for connection reuse
+		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // best-effort drain for keep-alive; a failure here is harmless
+		return nil
+	}
+	//nolint:errcheck // best-effort read of the error body; a partial or empty read still yields a usable message
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
+	apiErr := fmt.Errorf("gitlab: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+
+	// 3xx: Redirect responses that reach here (after http.Client's automatic handling)
+	// indicate a configuration issue and should not be retried
+	if resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode < http.StatusBadRequest {
+		return helpers.WrapPermanent(apiErr)
+	}
+
+	// 4xx: Client errors are permanent (bad request, auth issues, not found, etc.)
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode < http.StatusInternalServerError {
+		return helpers.WrapPermanent(apiErr)
+	}
+
+	// 5xx: Server errors are transient and should be retried
+	return apiErr
+}
@@ -0,0 +1,89 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/label"
+)
+
+// LabelerConfig describes settings required to label a GitLab merge request.
+type LabelerConfig struct {
+	BaseURL         string
+	Token           string
+	ProjectID       string
+	MergeRequestIID int
+	HTTPClient      *http.Client
+	APIPrefix       string
+	Timeout         time.Duration
+}
+
+// Labeler reads and changes the labels of a GitLab merge request.
+type Labeler struct {
+	apiClient
+	mergeRequestIID int
+}
+
+// Ensure Labeler implements label.Labeler.
+var _ label.Labeler = (*Labeler)(nil)
+
+// NewLabeler creates a Labeler for the merge request in cfg. It validates
+// that cfg.BaseURL, cfg.Token, cfg.ProjectID and cfg.MergeRequestIID are
+// provided; the HTTP client, API prefix and timeout default as for NewPoster.
+func NewLabeler(cfg LabelerConfig) (*Labeler, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("gitlab: base URL is required")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("gitlab: token is required")
+	}
+	if cfg.ProjectID == "" {
+		return nil, fmt.Errorf("gitlab: project ID is required")
+	}
+	if cfg.MergeRequestIID == 0 {
+		return nil, fmt.Errorf("gitlab: merge request IID is required")
+	}
+
+	api, err := newAPIClient(cfg.BaseURL, cfg.Token, cfg.ProjectID, cfg.APIPrefix, cfg.HTTPClient, cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Labeler{apiClient: api, mergeRequestIID: cfg.MergeRequestIID}, nil
+}
+
+// Labels implements label.Labeler.
+func (l *Labeler) Labels(ctx context.Context) ([]string, error) {
+	var mergeRequest struct {
+		Labels []string `json:"labels"`
+	}
+	if err := l.send(ctx, http.MethodGet, l.mergeRequestEndpoint(), nil, &mergeRequest); err != nil {
+		return nil, err
+	}
+	return mergeRequest.Labels, nil
+}
+
+// UpdateLabels implements label.Labeler. Adding a label the project does
+// not have yet creates it.
+func (l *Labeler) UpdateLabels(ctx context.Context, add, remove []string) error {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+	payload := map[string]string{}
+	if len(add) > 0 {
+		payload["add_labels"] = strings.Join(add, ",")
+	}
+	if len(remove) > 0 {
+		payload["remove_labels"] = strings.Join(remove, ",")
+	}
+	return l.send(ctx, http.MethodPut, l.mergeRequestEndpoint(), payload, nil)
+}
+
+// mergeRequestEndpoint returns the URL of the merge request.
+func (l *Labeler) mergeRequestEndpoint() url.URL {
+	return l.projectEndpoint("merge_requests", strconv.Itoa(l.mergeRequestIID))
+}
@@ -0,0 +1,62 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabeler(t *testing.T) {
+	type request struct {
+		Method string
+		Path   string
+		Body   map[string]string
+	}
+	var requests []request
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			recorded := request{Method: req.Method, Path: req.URL.Path}
+			if req.Method != http.MethodGet {
+				data, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				require.NoError(t, json.Unmarshal(data, &recorded.Body))
+			}
+			requests = append(requests, recorded)
+
+			resp := httptest.NewRecorder()
+			_, _ = resp.WriteString(`{"iid":7,"labels":["backend","app::web"]}`)
+			return resp.Result(), nil
+		}),
+	}
+
+	labeler, err := NewLabeler(LabelerConfig{BaseURL: "https://gitlab.example", Token: "token", ProjectID: "1", MergeRequestIID: 7, HTTPClient: client})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	labels, err := labeler.Labels(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "app::web"}, labels)
+
+	require.NoError(t, labeler.UpdateLabels(ctx, nil, nil))
+	require.Len(t, requests, 1, "nothing to change sends no request")
+
+	require.NoError(t, labeler.UpdateLabels(ctx, []string{"manifests-changed", "app::api"}, []string{"app::web"}))
+	require.Len(t, requests, 2)
+	assert.Equal(t, request{
+		Method: http.MethodPut,
+		Path:   "/api/v4/projects/1/merge_requests/7",
+		Body:   map[string]string{"add_labels": "manifests-changed,app::api", "remove_labels": "app::web"},
+	}, requests[1])
+}
+
+func TestNewLabelerValidatesConfig(t *testing.T) {
+	_, err := NewLabeler(LabelerConfig{BaseURL: "https://gitlab.example", Token: "token", ProjectID: "1"})
+	require.ErrorContains(t, err, "merge request IID is required")
+}
@@ -1,16 +1,15 @@
 // Package gitlab implements the comment.Poster interface for posting
-// diff comments to GitLab merge requests via the GitLab API.
+// diff comments to GitLab merge requests, the status.Reporter interface for
+// setting commit statuses and the label.Labeler interface for labelling
+// merge requests, via the GitLab API.
 package gitlab
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,10 +18,10 @@ import (
 )
 
 const (
-	defaultAPIPrefix      = "/api/v4"
-	privateTokenHeader    = "PRIVATE-TOKEN"
-	maxErrorResponseBytes = 4096
-	defaultClientTimeout  = 15 * time.Second
+	// noteLengthLimit reflects GitLab's documented 1 MB limit for note bodies.
+	noteLengthLimit = 1_000_000
+	// notesPerPage is the largest page size the notes and discussions APIs return.
+	notesPerPage = 100
 )
 
 // Config describes settings required to post comments to a GitLab Merge Request.
@@ -34,24 +33,66 @@ type Config struct {
 	HTTPClient      *http.Client
 	APIPrefix       string
 	Timeout         time.Duration
+	// Discussions posts resolvable discussion threads instead of plain notes.
+	Discussions bool
+	// PositionOnDiff attaches discussions posted with PostOnFile to the file
+	// in the merge request diff. It requires Discussions.
+	PositionOnDiff bool
 }
 
 type Poster struct {
-	client          *http.Client
-	baseURL         *url.URL
-	projectID       string
+	apiClient
 	mergeRequestIID int
-	token           string
-	apiPrefix       string
+	discussions     bool
+	positionOnDiff  bool
+	diffRefs        *diffRefs
+	threads         map[int64]thread // Discussion of each note returned by Find.
+	userID          int64            // Author of the token, resolved on first Find.
 }
 
-// Ensure Poster implements comment.Poster.
-var _ comment.Poster = (*Poster)(nil)
+// diffRefs identifies the versions of the merge request diff a positioned
+// discussion refers to.
+type diffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	StartSHA string `json:"start_sha"`
+	HeadSHA  string `json:"head_sha"`
+}
+
+// thread is the discussion a note belongs to.
+type thread struct {
+	id       string
+	resolved bool
+}
+
+// discussionNote is a note as returned by the notes and discussions APIs.
+type discussionNote struct {
+	ID       int64  `json:"id"`
+	Body     string `json:"body"`
+	System   bool   `json:"system"`
+	Resolved bool   `json:"resolved"`
+	Author   user   `json:"author"`
+}
+
+// user is a GitLab user as embedded in notes and returned by the user API.
+type user struct {
+	ID int64 `json:"id"`
+}
+
+// Ensure Poster implements comment.Poster, reports GitLab's size limit, can
+// update its notes in place and reopen its discussions.
+var (
+	_ comment.Poster      = (*Poster)(nil)
+	_ comment.BodyLimiter = (*Poster)(nil)
+	_ comment.Upserter    = (*Poster)(nil)
+	_ comment.Reopener    = (*Poster)(nil)
+	_ comment.FilePoster  = (*Poster)(nil)
+)
 
 // NewPoster creates a Poster configured to post comments to a GitLab merge request using cfg.
 // It validates that cfg.BaseURL, cfg.Token, cfg.ProjectID and cfg.MergeRequestIID are provided and parses cfg.BaseURL;
 // returns an error if validation or URL parsing fails. If cfg.HTTPClient is nil, a default http.Client is created using
 // cfg.Timeout (or the package default). The Poster will use cfg.APIPrefix if set, otherwise the package default.
+// cfg.PositionOnDiff is rejected unless cfg.Discussions is set.
 func NewPoster(cfg Config) (*Poster, error) {
 	if cfg.BaseURL == "" {
 		return nil, fmt.Errorf("gitlab: base URL is required")
@@ -65,96 +106,228 @@ func NewPoster(cfg Config) (*Poster, error) {
 	if cfg.MergeRequestIID == 0 {
 		return nil, fmt.Errorf("gitlab: merge request IID is required")
 	}
-
-	base, err := url.Parse(cfg.BaseURL)
-	if err != nil {
-		return nil, fmt.Errorf("gitlab: parse base URL: %w", err)
-	}
-
-	client := cfg.HTTPClient
-	if client == nil {
-		timeout := cfg.Timeout
-		if timeout <= 0 {
-			timeout = defaultClientTimeout
-		}
-		client = &http.Client{Timeout: timeout}
+	if cfg.PositionOnDiff && !cfg.Discussions {
+		return nil, fmt.Errorf("gitlab: positioning on the diff requires discussions")
 	}
 
-	apiPrefix := cfg.APIPrefix
-	if apiPrefix == "" {
-		apiPrefix = defaultAPIPrefix
+	api, err := newAPIClient(cfg.BaseURL, cfg.Token, cfg.ProjectID, cfg.APIPrefix, cfg.HTTPClient, cfg.Timeout)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Poster{
-		client:          client,
-		baseURL:         base,
-		projectID:       cfg.ProjectID,
+		apiClient:       api,
 		mergeRequestIID: cfg.MergeRequestIID,
-		token:           cfg.Token,
-		apiPrefix:       apiPrefix,
+		discussions:     cfg.Discussions,
+		positionOnDiff:  cfg.PositionOnDiff,
+		threads:         map[int64]thread{},
 	}, nil
 }
 
-// Post sends the supplied comment body to the configured Merge Request note endpoint.
+// MaxBodyLength implements comment.BodyLimiter.
+func (p *Poster) MaxBodyLength() int {
+	return noteLengthLimit
+}
+
+// Post sends the supplied comment body to the configured Merge Request as a
+// note, or as a new discussion when discussions are enabled.
 // The context can be used to cancel the request or set timeouts.
 // Network errors and 5xx server errors are retried with exponential backoff.
 func (p *Poster) Post(ctx context.Context, body string) error {
 	if strings.TrimSpace(body) == "" {
 		return fmt.Errorf("gitlab: comment body is empty")
 	}
+	if p.discussions {
+		return p.send(ctx, http.MethodPost, p.mergeRequestEndpoint("discussions"), map[string]string{"body": body}, nil)
+	}
+	return p.send(ctx, http.MethodPost, p.notesEndpoint(0), map[string]string{"body": body}, nil)
+}
 
-	endpoint := *p.baseURL
-	endpoint.Path = path.Join(endpoint.Path, p.apiPrefix, "projects", url.PathEscape(p.projectID), "merge_requests", fmt.Sprintf("%d", p.mergeRequestIID), "notes")
+// PostOnFile implements comment.FilePoster. With PositionOnDiff the body
+// opens a discussion on path in the merge request diff; GitLab rejects
+// positions on files the diff does not contain, in which case, and without
+// PositionOnDiff, it is posted like Post does.
+func (p *Poster) PostOnFile(ctx context.Context, body, path string) error {
+	if !p.positionOnDiff || path == "" {
+		return p.Post(ctx, body)
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("gitlab: comment body is empty")
+	}
 
-	payload, err := json.Marshal(map[string]string{"body": body})
+	refs, err := p.mergeRequestDiffRefs(ctx)
 	if err != nil {
-		return fmt.Errorf("gitlab: marshal payload: %w", err)
+		return err
 	}
-
-	retryCfg := helpers.DefaultRetryConfig()
-	return helpers.WithRetry(ctx, retryCfg, func() error {
-		return p.doRequest(ctx, endpoint.String(), payload)
-	})
+	payload := map[string]any{
+		"body": body,
+		"position": map[string]string{
+			"position_type": "file",
+			"base_sha":      refs.BaseSHA,
+			"start_sha":     refs.StartSHA,
+			"head_sha":      refs.HeadSHA,
+			"old_path":      path,
+			"new_path":      path,
+		},
+	}
+	err = p.send(ctx, http.MethodPost, p.mergeRequestEndpoint("discussions"), payload, nil)
+	if err != nil && helpers.IsPermanent(err) {
+		return p.Post(ctx, body)
+	}
+	return err
 }
 
-// doRequest performs the HTTP request and returns an error only for retryable failures.
-// Client errors (4xx) are wrapped in a permanentError to prevent retry.
-func (p *Poster) doRequest(ctx context.Context, endpoint string, payload []byte) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+// Find implements comment.Upserter. It pages through the merge request's
+// notes oldest first, skipping system notes and notes written by anyone but
+// the token's user, which may quote a marker but are not ours to edit. With
+// discussions enabled it reads the discussions instead, so that Reopen can
+// reopen resolved ones.
+func (p *Poster) Find(ctx context.Context, marker string) ([]comment.Note, error) {
+	userID, err := p.currentUserID(ctx)
 	if err != nil {
-		return fmt.Errorf("gitlab: build request: %w", err)
+		return nil, err
+	}
+	if p.discussions {
+		return p.findInDiscussions(ctx, marker, userID)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set(privateTokenHeader, p.token)
 
-	resp, err := p.client.Do(req) // #nosec G107 G704 -- endpoint is built from operator-configured BaseURL, not user input
-	if err != nil {
-		// Network errors are retryable
-		return fmt.Errorf("gitlab: perform request: %w", err)
+	var found []comment.Note
+	for page := 1; ; page++ {
+		var notes []discussionNote
+		if err := p.send(ctx, http.MethodGet, p.pageEndpoint(p.notesEndpoint(0), page), nil, &notes); err != nil {
+			return nil, err
+		}
+		for _, note := range notes {
+			if note.matches(marker, userID) {
+				found = append(found, comment.Note{ID: note.ID, Body: note.Body})
+			}
+		}
+		if len(notes) < notesPerPage {
+			return found, nil
+		}
 	}
-	defer resp.Body.Close()
+}
+
+// findInDiscussions pages through the merge request's discussions, which
+// include plain notes as single-note discussions, and records the discussion
+// of every matching note.
+func (p *Poster) findInDiscussions(ctx context.Context, marker string, userID int64) ([]comment.Note, error) {
+	var found []comment.Note
+	for page := 1; ; page++ {
+		var discussions []struct {
+			ID    string           `json:"id"`
+			Notes []discussionNote `json:"notes"`
+		}
+		if err := p.send(ctx, http.MethodGet, p.pageEndpoint(p.mergeRequestEndpoint("discussions"), page), nil, &discussions); err != nil {
+			return nil, err
+		}
+		for _, discussion := range discussions {
+			for _, note := range discussion.Notes {
+				if note.matches(marker, userID) {
+					found = append(found, comment.Note{ID: note.ID, Body: note.Body})
+					p.threads[note.ID] = thread{id: discussion.ID, resolved: note.Resolved}
+				}
+			}
+		}
+		if len(discussions) < notesPerPage {
+			return found, nil
+		}
+	}
+}
+
+// matches reports whether the note is a user note by userID carrying marker.
+func (n discussionNote) matches(marker string, userID int64) bool {
+	return !n.System && n.Author.ID == userID && strings.Contains(n.Body, marker)
+}
+
+// currentUserID returns the ID of the user the token authenticates as,
+// fetching it on first use.
+func (p *Poster) currentUserID(ctx context.Context) (int64, error) {
+	if p.userID != 0 {
+		return p.userID, nil
+	}
+	var current user
+	if err := p.send(ctx, http.MethodGet, p.endpoint("user"), nil, &current); err != nil {
+		return 0, fmt.Errorf("gitlab: resolve token user: %w", err)
+	}
+	if current.ID == 0 {
+		return 0, fmt.Errorf("gitlab: resolve token user: response has no user ID")
+	}
+	p.userID = current.ID
+	return p.userID, nil
+}
 
-	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
-		// Success - drain response body for connection reuse
-		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // best-effort drain for keep-alive; a failure here is harmless
+// Update implements comment.Upserter. Editing a note leaves its discussion
+// resolved; callers decide with Reopen whether the new body needs review.
+func (p *Poster) Update(ctx context.Context, id int64, body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("gitlab: comment body is empty")
+	}
+	return p.send(ctx, http.MethodPut, p.notesEndpoint(id), map[string]string{"body": body}, nil)
+}
+
+// Reopen implements comment.Reopener. Only discussions of notes returned by
+// Find are known; other notes are left as they are.
+func (p *Poster) Reopen(ctx context.Context, id int64) error {
+	discussion, ok := p.threads[id]
+	if !ok || !discussion.resolved {
 		return nil
 	}
+	endpoint := p.mergeRequestEndpoint("discussions", discussion.id)
+	endpoint.RawQuery = url.Values{"resolved": {"false"}}.Encode()
+	if err := p.send(ctx, http.MethodPut, endpoint, nil, nil); err != nil {
+		return fmt.Errorf("gitlab: reopen discussion: %w", err)
+	}
+	p.threads[id] = thread{id: discussion.id}
+	return nil
+}
 
-	//nolint:errcheck // best-effort read of the error body; a partial or empty read still yields a usable message
-	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
-	apiErr := fmt.Errorf("gitlab: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+// Delete implements comment.Upserter.
+func (p *Poster) Delete(ctx context.Context, id int64) error {
+	return p.send(ctx, http.MethodDelete, p.notesEndpoint(id), nil, nil)
+}
 
-	// 3xx: Redirect responses that reach here (after http.Client's automatic handling)
-	// indicate a configuration issue and should not be retried
-	if resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode < http.StatusBadRequest {
-		return helpers.WrapPermanent(apiErr)
+// mergeRequestDiffRefs returns the diff versions of the merge request,
+// fetching them on first use.
+func (p *Poster) mergeRequestDiffRefs(ctx context.Context) (diffRefs, error) {
+	if p.diffRefs != nil {
+		return *p.diffRefs, nil
 	}
+	var mergeRequest struct {
+		DiffRefs *diffRefs `json:"diff_refs"`
+	}
+	if err := p.send(ctx, http.MethodGet, p.mergeRequestEndpoint(), nil, &mergeRequest); err != nil {
+		return diffRefs{}, err
+	}
+	if mergeRequest.DiffRefs == nil || mergeRequest.DiffRefs.HeadSHA == "" {
+		return diffRefs{}, fmt.Errorf("gitlab: merge request has no diff yet")
+	}
+	p.diffRefs = mergeRequest.DiffRefs
+	return *p.diffRefs, nil
+}
 
-	// 4xx: Client errors are permanent (bad request, auth issues, not found, etc.)
-	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode < http.StatusInternalServerError {
-		return helpers.WrapPermanent(apiErr)
+// notesEndpoint returns the URL of the merge request's notes, or of a single
+// note when id is non-zero.
+func (p *Poster) notesEndpoint(id int64) url.URL {
+	if id != 0 {
+		return p.mergeRequestEndpoint("notes", strconv.FormatInt(id, 10))
 	}
+	return p.mergeRequestEndpoint("notes")
+}
+
+// mergeRequestEndpoint returns the URL of the merge request, extended by the
+// given path segments.
+func (p *Poster) mergeRequestEndpoint(segments ...string) url.URL {
+	return p.projectEndpoint(append([]string{"merge_requests", strconv.Itoa(p.mergeRequestIID)}, segments...)...)
+}
 
-	// 5xx: Server errors are transient and should be retried
-	return apiErr
+// pageEndpoint returns endpoint listing one page of results, oldest first.
+func (p *Poster) pageEndpoint(endpoint url.URL, page int) url.URL {
+	endpoint.RawQuery = url.Values{
+		"order_by": {"created_at"},
+		"sort":     {"asc"},
+		"per_page": {strconv.Itoa(notesPerPage)},
+		"page":     {strconv.Itoa(page)},
+	}.Encode()
+	return endpoint
 }
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shini4i/argo-compare/internal/comment"
 	"github.com/shini4i/argo-compare/internal/helpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -166,3 +167,220 @@ func TestPosterPost5xxIsRetried(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 3, attempts) // Should have retried twice before success
 }
+
+func TestPosterFindUpdateDelete(t *testing.T) {
+	type request struct {
+		Method string
+		Path   string
+		Query  url.Values
+		Body   map[string]string
+	}
+	var requests []request
+
+	notes := make([]map[string]any, 0, notesPerPage+2)
+	for id := 1; id <= notesPerPage+2; id++ {
+		notes = append(notes, map[string]any{"id": id, "body": "unrelated", "system": false, "author": map[string]any{"id": 42}})
+	}
+	notes[3]["body"] = "diff <!-- marker -->"
+	notes[4] = map[string]any{"id": 5, "body": "<!-- marker --> added a commit", "system": true, "author": map[string]any{"id": 42}}
+	notes[5] = map[string]any{"id": 6, "body": "quoting diff <!-- marker -->", "system": false, "author": map[string]any{"id": 99}}
+	notes[notesPerPage+1]["body"] = "second page <!-- marker -->"
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			recorded := request{Method: req.Method, Path: req.URL.Path, Query: req.URL.Query()}
+			if req.Body != nil && req.Method != http.MethodGet {
+				data, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				if len(data) > 0 {
+					require.NoError(t, json.Unmarshal(data, &recorded.Body))
+				}
+			}
+			requests = append(requests, recorded)
+
+			resp := httptest.NewRecorder()
+			if req.Method == http.MethodGet && recorded.Path == "/api/v4/user" {
+				_, _ = resp.WriteString(`{"id":42}`)
+			} else if req.Method == http.MethodGet {
+				page := 1
+				if recorded.Query.Get("page") == "2" {
+					page = 2
+				}
+				start := (page - 1) * notesPerPage
+				end := min(start+notesPerPage, len(notes))
+				require.NoError(t, json.NewEncoder(resp).Encode(notes[start:end]))
+			} else {
+				resp.WriteHeader(http.StatusOK)
+			}
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{
+		BaseURL:         "https://gitlab.example",
+		Token:           "token",
+		ProjectID:       "1",
+		MergeRequestIID: 7,
+		HTTPClient:      client,
+	})
+	require.NoError(t, err)
+
+	found, err := poster.Find(context.Background(), "<!-- marker -->")
+	require.NoError(t, err)
+	assert.Equal(t, []comment.Note{
+		{ID: 4, Body: "diff <!-- marker -->"},
+		{ID: notesPerPage + 2, Body: "second page <!-- marker -->"},
+	}, found, "system notes and notes of other users are skipped and every page is read")
+	require.Len(t, requests, 3)
+	assert.Equal(t, "/api/v4/user", requests[0].Path)
+	assert.Equal(t, "asc", requests[1].Query.Get("sort"))
+	assert.Equal(t, "2", requests[2].Query.Get("page"))
+
+	_, err = poster.Find(context.Background(), "<!-- marker -->")
+	require.NoError(t, err)
+	require.Len(t, requests, 5, "the token user is resolved once")
+
+	require.NoError(t, poster.Update(context.Background(), 4, "new body"))
+	require.NoError(t, poster.Delete(context.Background(), 102))
+
+	assert.Equal(t, request{Method: http.MethodPut, Path: "/api/v4/projects/1/merge_requests/7/notes/4", Query: url.Values{}, Body: map[string]string{"body": "new body"}}, requests[5])
+	assert.Equal(t, http.MethodDelete, requests[6].Method)
+	assert.Equal(t, "/api/v4/projects/1/merge_requests/7/notes/102", requests[6].Path)
+}
+
+func TestPosterFindFailsWithoutTokenUser(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusForbidden)
+			_, _ = resp.WriteString(`{"message":"403 Forbidden"}`)
+			return resp.Result(), nil
+		}),
+	}
+	poster, err := NewPoster(Config{BaseURL: "https://gitlab.example", Token: "token", ProjectID: "1", MergeRequestIID: 7, HTTPClient: client})
+	require.NoError(t, err)
+
+	_, err = poster.Find(context.Background(), "<!-- marker -->")
+	require.ErrorContains(t, err, "gitlab: resolve token user")
+}
+
+func TestNewPosterRequiresDiscussionsForPositions(t *testing.T) {
+	_, err := NewPoster(Config{BaseURL: "http://example.com", Token: "token", ProjectID: "1", MergeRequestIID: 7, PositionOnDiff: true})
+	require.ErrorContains(t, err, "requires discussions")
+}
+
+func TestPosterDiscussions(t *testing.T) {
+	type request struct {
+		Method string
+		Path   string
+		Query  url.Values
+		Body   map[string]any
+	}
+	var requests []request
+	rejectPositions := false
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			recorded := request{Method: req.Method, Path: req.URL.Path, Query: req.URL.Query()}
+			if req.Body != nil && req.Method != http.MethodGet {
+				data, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				if len(data) > 0 {
+					require.NoError(t, json.Unmarshal(data, &recorded.Body))
+				}
+			}
+			requests = append(requests, recorded)
+
+			resp := httptest.NewRecorder()
+			switch {
+			case req.Method == http.MethodGet && recorded.Path == "/api/v4/user":
+				_, _ = resp.WriteString(`{"id":42}`)
+			case req.Method == http.MethodGet && recorded.Path == "/api/v4/projects/1/merge_requests/7":
+				_, _ = resp.WriteString(`{"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`)
+			case req.Method == http.MethodGet:
+				_, _ = resp.WriteString(`[
+					{"id":"abc","notes":[{"id":4,"body":"diff <!-- marker -->","resolved":true,"author":{"id":42}},{"id":5,"body":"looks fine","author":{"id":99}}]},
+					{"id":"def","notes":[{"id":6,"body":"<!-- marker --> resolved this thread","system":true,"author":{"id":42}}]},
+					{"id":"ghi","notes":[{"id":8,"body":"open <!-- marker -->","author":{"id":42}}]},
+					{"id":"jkl","notes":[{"id":9,"body":"quoting <!-- marker -->","resolved":true,"author":{"id":99}}]}
+				]`)
+			case rejectPositions && recorded.Body["position"] != nil:
+				resp.WriteHeader(http.StatusBadRequest)
+				_, _ = resp.WriteString(`{"message":"400 Bad request - Note {:line_code=>[\"can't be blank\"]}"}`)
+			default:
+				resp.WriteHeader(http.StatusCreated)
+			}
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{
+		BaseURL:         "https://gitlab.example",
+		Token:           "token",
+		ProjectID:       "1",
+		MergeRequestIID: 7,
+		HTTPClient:      client,
+		Discussions:     true,
+		PositionOnDiff:  true,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, poster.Post(ctx, "general"))
+	assert.Equal(t, request{Method: http.MethodPost, Path: "/api/v4/projects/1/merge_requests/7/discussions", Query: url.Values{}, Body: map[string]any{"body": "general"}}, requests[0])
+
+	require.NoError(t, poster.PostOnFile(ctx, "on file", "apps/web.yaml"))
+	require.NoError(t, poster.PostOnFile(ctx, "on file again", "apps/web.yaml"))
+	require.Len(t, requests, 4, "diff refs are fetched once")
+	assert.Equal(t, "/api/v4/projects/1/merge_requests/7", requests[1].Path)
+	assert.Equal(t, map[string]any{
+		"position_type": "file",
+		"base_sha":      "base",
+		"start_sha":     "start",
+		"head_sha":      "head",
+		"old_path":      "apps/web.yaml",
+		"new_path":      "apps/web.yaml",
+	}, requests[2].Body["position"])
+
+	requests = nil
+	rejectPositions = true
+	require.NoError(t, poster.PostOnFile(ctx, "fallback", "apps/gone.yaml"))
+	require.Len(t, requests, 2)
+	assert.Nil(t, requests[1].Body["position"], "rejected positions fall back to a general discussion")
+
+	requests = nil
+	found, err := poster.Find(ctx, "<!-- marker -->")
+	require.NoError(t, err)
+	assert.Equal(t, []comment.Note{{ID: 4, Body: "diff <!-- marker -->"}, {ID: 8, Body: "open <!-- marker -->"}}, found)
+	assert.Equal(t, "/api/v4/user", requests[0].Path)
+	assert.Equal(t, "/api/v4/projects/1/merge_requests/7/discussions", requests[1].Path)
+
+	require.NoError(t, poster.Update(ctx, 4, "changed"))
+	require.Len(t, requests, 3, "updating a note leaves its discussion resolved")
+	assert.Equal(t, "/api/v4/projects/1/merge_requests/7/notes/4", requests[2].Path)
+
+	require.NoError(t, poster.Reopen(ctx, 4))
+	require.NoError(t, poster.Reopen(ctx, 4))
+	require.NoError(t, poster.Reopen(ctx, 8))
+	require.NoError(t, poster.Reopen(ctx, 9))
+	require.Len(t, requests, 4, "only our resolved discussion is reopened, once")
+	assert.Equal(t, request{Method: http.MethodPut, Path: "/api/v4/projects/1/merge_requests/7/discussions/abc", Query: url.Values{"resolved": {"false"}}}, requests[3])
+}
+
+func TestPosterPostOnFileWithoutPosition(t *testing.T) {
+	var paths []string
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			paths = append(paths, req.URL.Path)
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusCreated)
+			return resp.Result(), nil
+		}),
+	}
+
+	poster, err := NewPoster(Config{BaseURL: "https://gitlab.example", Token: "token", ProjectID: "1", MergeRequestIID: 7, HTTPClient: client})
+	require.NoError(t, err)
+
+	require.NoError(t, poster.PostOnFile(context.Background(), "body", "apps/web.yaml"))
+	assert.Equal(t, []string{"/api/v4/projects/1/merge_requests/7/notes"}, paths)
+}
@@ -0,0 +1,92 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/status"
+)
+
+// statusDescriptionLimit is the longest description GitLab stores for a
+// commit status.
+const statusDescriptionLimit = 255
+
+// StatusConfig describes settings required to report commit statuses to a
+// GitLab project.
+type StatusConfig struct {
+	BaseURL    string
+	Token      string
+	ProjectID  string
+	HTTPClient *http.Client
+	APIPrefix  string
+	Timeout    time.Duration
+}
+
+// StatusReporter sets commit statuses through the GitLab API. They show up
+// as external jobs in the commit's pipeline and in the merge request widget.
+type StatusReporter struct {
+	apiClient
+}
+
+// Ensure StatusReporter implements status.Reporter.
+var _ status.Reporter = (*StatusReporter)(nil)
+
+// NewStatusReporter creates a StatusReporter for the project in cfg. It
+// validates that cfg.BaseURL, cfg.Token and cfg.ProjectID are provided; the
+// HTTP client, API prefix and timeout default as for NewPoster.
+func NewStatusReporter(cfg StatusConfig) (*StatusReporter, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("gitlab: base URL is required")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("gitlab: token is required")
+	}
+	if cfg.ProjectID == "" {
+		return nil, fmt.Errorf("gitlab: project ID is required")
+	}
+
+	api, err := newAPIClient(cfg.BaseURL, cfg.Token, cfg.ProjectID, cfg.APIPrefix, cfg.HTTPClient, cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &StatusReporter{apiClient: api}, nil
+}
+
+// Report implements status.Reporter. GitLab rejects setting a status to the
+// state it already has, for example when a job is retried; such a status is
+// left as it is.
+func (r *StatusReporter) Report(ctx context.Context, sha string, st status.Status) error {
+	if sha == "" {
+		return fmt.Errorf("gitlab: commit SHA is required")
+	}
+	if st.Name == "" {
+		return fmt.Errorf("gitlab: status name is required")
+	}
+
+	payload := map[string]string{
+		"state":       string(st.State),
+		"name":        st.Name,
+		"description": truncateDescription(st.Description),
+	}
+	if st.TargetURL != "" {
+		payload["target_url"] = st.TargetURL
+	}
+
+	err := r.send(ctx, http.MethodPost, r.projectEndpoint("statuses", sha), payload, nil)
+	if err != nil && strings.Contains(err.Error(), "Cannot transition status") {
+		return nil
+	}
+	return err
+}
+
+// truncateDescription shortens description to the length GitLab stores.
+func truncateDescription(description string) string {
+	runes := []rune(description)
+	if len(runes) <= statusDescriptionLimit {
+		return description
+	}
+	return string(runes[:statusDescriptionLimit-1]) + "…"
+}
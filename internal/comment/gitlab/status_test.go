@@ -0,0 +1,98 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shini4i/argo-compare/internal/helpers"
+	"github.com/shini4i/argo-compare/internal/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusReporterReport(t *testing.T) {
+	var received struct {
+		Method string
+		Path   string
+		Token  string
+		Body   map[string]string
+	}
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			received.Method = req.Method
+			received.Path = req.URL.EscapedPath()
+			received.Token = req.Header.Get(privateTokenHeader)
+			data, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(data, &received.Body))
+
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusCreated)
+			return resp.Result(), nil
+		}),
+	}
+
+	reporter, err := NewStatusReporter(StatusConfig{BaseURL: "https://gitlab.example", Token: "token", ProjectID: "42", HTTPClient: client})
+	require.NoError(t, err)
+
+	err = reporter.Report(context.Background(), "abc123", status.Status{
+		Name:        "argo-compare/apps/web.yaml",
+		State:       status.StateFailed,
+		Description: strings.Repeat("x", 300),
+		TargetURL:   "https://gitlab.example/jobs/1",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "/api/v4/projects/42/statuses/abc123", received.Path)
+	assert.Equal(t, "token", received.Token)
+	assert.Equal(t, "failed", received.Body["state"])
+	assert.Equal(t, "argo-compare/apps/web.yaml", received.Body["name"])
+	assert.Equal(t, "https://gitlab.example/jobs/1", received.Body["target_url"])
+	assert.Len(t, []rune(received.Body["description"]), statusDescriptionLimit)
+}
+
+func TestStatusReporterIgnoresRepeatedState(t *testing.T) {
+	calls := 0
+	client := &http.Client{
+		Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			calls++
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusBadRequest)
+			_, _ = resp.WriteString(`{"message":"Cannot transition status via :run from :running"}`)
+			return resp.Result(), nil
+		}),
+	}
+
+	reporter, err := NewStatusReporter(StatusConfig{BaseURL: "https://gitlab.example", Token: "token", ProjectID: "1", HTTPClient: client})
+	require.NoError(t, err)
+
+	require.NoError(t, reporter.Report(context.Background(), "abc123", status.Status{Name: "argo-compare/app", State: status.StateSuccess}))
+	assert.Equal(t, 1, calls)
+}
+
+func TestStatusReporterErrors(t *testing.T) {
+	_, err := NewStatusReporter(StatusConfig{BaseURL: "https://gitlab.example", Token: "token"})
+	require.ErrorContains(t, err, "project ID is required")
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusForbidden)
+			return resp.Result(), nil
+		}),
+	}
+	reporter, err := NewStatusReporter(StatusConfig{BaseURL: "https://gitlab.example", Token: "token", ProjectID: "1", HTTPClient: client})
+	require.NoError(t, err)
+
+	require.ErrorContains(t, reporter.Report(context.Background(), "", status.Status{Name: "argo-compare/app"}), "commit SHA is required")
+	err = reporter.Report(context.Background(), "abc123", status.Status{Name: "argo-compare/app", State: status.StateSuccess})
+	require.Error(t, err)
+	assert.True(t, helpers.IsPermanent(err))
+}
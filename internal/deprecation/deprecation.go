@@ -0,0 +1,167 @@
+// Package deprecation knows which Kubernetes API versions have been
+// deprecated or removed, and in which Kubernetes release. The table is
+// embedded from apis.yaml and follows the upstream deprecation guide; only
+// built-in APIs are listed, so custom resources are never reported.
+package deprecation
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed apis.yaml
+var apisYAML []byte
+
+// Version is a Kubernetes minor release such as 1.25. Patch levels and
+// pre-release or vendor suffixes are irrelevant to API availability and are
+// dropped when parsing. The zero value means "unknown".
+type Version struct {
+	Major int
+	Minor int
+}
+
+// ParseVersion accepts the forms used by kubectl, Helm and managed clusters:
+// "1.25", "v1.25.3", "1.25.0-eks" and "v1.27.4+k3s1".
+func ParseVersion(s string) (Version, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(trimmed, "-+"); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	parts := strings.Split(trimmed, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid Kubernetes version %q (expected MAJOR.MINOR such as 1.29)", s)
+	}
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid Kubernetes version %q (expected MAJOR.MINOR such as 1.29)", s)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1]}, nil
+}
+
+// String renders the version as MAJOR.MINOR.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// MarshalText encodes the version as MAJOR.MINOR.
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// IsZero reports whether the version is unknown.
+func (v Version) IsZero() bool {
+	return v == Version{}
+}
+
+// AtLeast reports whether v is the same release as other or a later one.
+func (v Version) AtLeast(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	return v.Minor >= other.Minor
+}
+
+// UnmarshalYAML parses a version written as a string in apis.yaml.
+func (v *Version) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := ParseVersion(node.Value)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// API describes a deprecated version of a single kind.
+type API struct {
+	APIVersion   string
+	Kind         string
+	DeprecatedIn Version
+	RemovedIn    Version
+	Replacement  string // API version to migrate to; empty when there is no direct successor.
+}
+
+// DeprecatedBy reports whether the API is deprecated, but still served, in
+// target. An unknown target counts as every release, so the API is reported
+// as deprecated.
+func (a API) DeprecatedBy(target Version) bool {
+	return !a.RemovedBy(target) && (target.IsZero() || target.AtLeast(a.DeprecatedIn))
+}
+
+// RemovedBy reports whether the API server of release target no longer
+// serves the API. It is always false for an unknown target.
+func (a API) RemovedBy(target Version) bool {
+	return !target.IsZero() && target.AtLeast(a.RemovedIn)
+}
+
+// entry is one line of apis.yaml; an API version is usually retired for
+// several kinds at once.
+type entry struct {
+	APIVersion   string   `yaml:"apiVersion"`
+	Kinds        []string `yaml:"kinds"`
+	DeprecatedIn Version  `yaml:"deprecatedIn"`
+	RemovedIn    Version  `yaml:"removedIn"`
+	Replacement  string   `yaml:"replacement"`
+}
+
+// apiKey identifies an API by version and kind.
+type apiKey struct {
+	apiVersion string
+	kind       string
+}
+
+var table = mustLoad(apisYAML)
+
+// mustLoad decodes the embedded table. It panics on malformed data, which the
+// package tests catch long before a release.
+func mustLoad(data []byte) map[apiKey]API {
+	apis, err := load(data)
+	if err != nil {
+		panic(fmt.Sprintf("deprecation: embedded API table: %v", err))
+	}
+	return apis
+}
+
+func load(data []byte) (map[apiKey]API, error) {
+	var entries []entry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	apis := make(map[apiKey]API)
+	for _, e := range entries {
+		if e.APIVersion == "" || len(e.Kinds) == 0 || e.RemovedIn.IsZero() {
+			return nil, fmt.Errorf("entry for %q is missing apiVersion, kinds or removedIn", e.APIVersion)
+		}
+		if !e.RemovedIn.AtLeast(e.DeprecatedIn) {
+			return nil, fmt.Errorf("%s is removed in %s before being deprecated in %s", e.APIVersion, e.RemovedIn, e.DeprecatedIn)
+		}
+		for _, kind := range e.Kinds {
+			key := apiKey{apiVersion: e.APIVersion, kind: kind}
+			if _, dup := apis[key]; dup {
+				return nil, fmt.Errorf("%s %s is listed more than once", e.APIVersion, kind)
+			}
+			apis[key] = API{
+				APIVersion:   e.APIVersion,
+				Kind:         kind,
+				DeprecatedIn: e.DeprecatedIn,
+				RemovedIn:    e.RemovedIn,
+				Replacement:  e.Replacement,
+			}
+		}
+	}
+	return apis, nil
+}
+
+// Lookup returns the deprecation record for kind served at apiVersion, if
+// that combination has been deprecated.
+func Lookup(apiVersion, kind string) (API, bool) {
+	api, ok := table[apiKey{apiVersion: apiVersion, kind: kind}]
+	return api, ok
+}
@@ -0,0 +1,116 @@
+package deprecation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Version
+		wantErr bool
+	}{
+		{input: "1.25", want: Version{Major: 1, Minor: 25}},
+		{input: "v1.25.3", want: Version{Major: 1, Minor: 25}},
+		{input: " 1.29.0-eks-a737599 ", want: Version{Major: 1, Minor: 29}},
+		{input: "v1.27.4+k3s1", want: Version{Major: 1, Minor: 27}},
+		{input: "1", wantErr: true},
+		{input: "1.2.3.4", wantErr: true},
+		{input: "1.x", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseVersion(tt.input)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "invalid Kubernetes version")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	v := Version{Major: 1, Minor: 25}
+	assert.True(t, v.AtLeast(Version{Major: 1, Minor: 25}))
+	assert.True(t, v.AtLeast(Version{Major: 1, Minor: 9}))
+	assert.False(t, v.AtLeast(Version{Major: 1, Minor: 26}))
+	assert.True(t, Version{Major: 2}.AtLeast(v))
+	assert.Equal(t, "1.25", v.String())
+}
+
+func TestLookup(t *testing.T) {
+	api, ok := Lookup("policy/v1beta1", "PodDisruptionBudget")
+	require.True(t, ok)
+	assert.Equal(t, Version{Major: 1, Minor: 21}, api.DeprecatedIn)
+	assert.Equal(t, Version{Major: 1, Minor: 25}, api.RemovedIn)
+	assert.Equal(t, "policy/v1", api.Replacement)
+
+	_, ok = Lookup("policy/v1", "PodDisruptionBudget")
+	assert.False(t, ok)
+
+	_, ok = Lookup("policy/v1beta1", "Deployment")
+	assert.False(t, ok, "kinds are matched together with the API version")
+
+	api, ok = Lookup("policy/v1beta1", "PodSecurityPolicy")
+	require.True(t, ok)
+	assert.Empty(t, api.Replacement)
+}
+
+func TestAPIStatusForTarget(t *testing.T) {
+	api, ok := Lookup("batch/v1beta1", "CronJob") // Deprecated in 1.21, removed in 1.25.
+	require.True(t, ok)
+
+	tests := []struct {
+		target     Version
+		deprecated bool
+		removed    bool
+	}{
+		{target: Version{}, deprecated: true},
+		{target: Version{Major: 1, Minor: 20}},
+		{target: Version{Major: 1, Minor: 21}, deprecated: true},
+		{target: Version{Major: 1, Minor: 24}, deprecated: true},
+		{target: Version{Major: 1, Minor: 25}, removed: true},
+		{target: Version{Major: 1, Minor: 31}, removed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target.String(), func(t *testing.T) {
+			assert.Equal(t, tt.deprecated, api.DeprecatedBy(tt.target))
+			assert.Equal(t, tt.removed, api.RemovedBy(tt.target))
+		})
+	}
+}
+
+func TestLoadRejectsInconsistentEntries(t *testing.T) {
+	tests := map[string]string{
+		"missing removal":  `[{apiVersion: a/v1, kinds: [A], deprecatedIn: "1.1"}]`,
+		"removed early":    `[{apiVersion: a/v1, kinds: [A], deprecatedIn: "1.5", removedIn: "1.4"}]`,
+		"duplicate":        `[{apiVersion: a/v1, kinds: [A, A], deprecatedIn: "1.1", removedIn: "1.2"}]`,
+		"bad version":      `[{apiVersion: a/v1, kinds: [A], deprecatedIn: "one", removedIn: "1.2"}]`,
+		"missing kinds":    `[{apiVersion: a/v1, deprecatedIn: "1.1", removedIn: "1.2"}]`,
+		"missing version":  `[{kinds: [A], deprecatedIn: "1.1", removedIn: "1.2"}]`,
+		"not a list":       `apiVersion: a/v1`,
+		"unparsable table": `[`,
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := load([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEmbeddedTableLoads(t *testing.T) {
+	apis, err := load(apisYAML)
+	require.NoError(t, err)
+	assert.Equal(t, table, apis)
+	assert.NotEmpty(t, apis)
+}
@@ -0,0 +1,13 @@
+// Package label provides abstractions for labelling merge requests based on
+// comparison results.
+package label
+
+import "context"
+
+// Labeler can read and change the labels of a merge request.
+type Labeler interface {
+	// Labels returns the labels currently set on the merge request.
+	Labels(ctx context.Context) ([]string, error)
+	// UpdateLabels adds and removes the given labels in a single change.
+	UpdateLabels(ctx context.Context, add, remove []string) error
+}
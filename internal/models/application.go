@@ -61,6 +61,7 @@ type HelmSource struct {
 	ValueFiles   []string               `yaml:"valueFiles,omitempty"`
 	ValuesObject map[string]interface{} `yaml:"valuesObject,omitempty"`
 	Parameters   []HelmParameter        `yaml:"parameters,omitempty"`
+	KubeVersion  string                 `yaml:"kubeVersion,omitempty"`
 }
 
 // HelmParameter is a single spec.source.helm.parameters entry. ForceString
@@ -149,3 +150,17 @@ func (app *Application) Validate() error {
 
 	return nil
 }
+
+// KubeVersion returns the Kubernetes version declared in spec.source.helm.kubeVersion,
+// or the first one declared across spec.sources. It is empty when no source sets it.
+func (app *Application) KubeVersion() string {
+	if app.Spec.Source != nil && app.Spec.Source.Helm.KubeVersion != "" {
+		return app.Spec.Source.Helm.KubeVersion
+	}
+	for _, source := range app.Spec.Sources {
+		if source != nil && source.Helm.KubeVersion != "" {
+			return source.Helm.KubeVersion
+		}
+	}
+	return ""
+}
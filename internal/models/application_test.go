@@ -285,3 +285,31 @@ func TestApplication_Validate(t *testing.T) {
 	err = appWithNilMultiSourceEntry.Validate()
 	assert.ErrorIs(t, err, ErrUnsupportedAppConfiguration, "expected ErrUnsupportedAppConfiguration for nil entry in Sources")
 }
+
+// TestApplicationKubeVersion verifies that helm.kubeVersion is read from the
+// single source or, for multi-source Applications, from the first source that
+// declares it.
+func TestApplicationKubeVersion(t *testing.T) {
+	var single Application
+	require.NoError(t, yaml.Unmarshal([]byte(`
+spec:
+  source:
+    chart: app
+    helm:
+      kubeVersion: "1.29"
+`), &single))
+	assert.Equal(t, "1.29", single.KubeVersion())
+
+	var multi Application
+	require.NoError(t, yaml.Unmarshal([]byte(`
+spec:
+  sources:
+    - chart: app
+    - chart: other
+      helm:
+        kubeVersion: v1.30.1
+`), &multi))
+	assert.Equal(t, "v1.30.1", multi.KubeVersion())
+
+	assert.Empty(t, (&Application{}).KubeVersion())
+}
@@ -0,0 +1,206 @@
+// Package policy evaluates repository-defined guardrails against rendered
+// Kubernetes resources. Policies are boolean expressions written in the Common
+// Expression Language (CEL) — the same language used by Kubernetes
+// ValidatingAdmissionPolicy — so `object` is the resource as it would be
+// applied and `oldObject` is its current state (null for new resources). An
+// expression that evaluates to false is a violation.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"gopkg.in/yaml.v3"
+)
+
+// Variables that policy expressions may reference.
+const (
+	VariableObject    = "object"
+	VariableOldObject = "oldObject"
+)
+
+// costLimit bounds the work a single evaluation may do, in CEL cost units, so
+// that an expensive expression fails instead of stalling the run. It matches
+// the per-expression limit of Kubernetes admission policies.
+const costLimit = 1000000
+
+// Policy is a single guardrail as written in the policy file.
+type Policy struct {
+	Name       string `yaml:"name"`
+	Match      Match  `yaml:"match"`
+	Expression string `yaml:"expression"`
+	Message    string `yaml:"message"` // Shown for violations; defaults to the failing expression.
+}
+
+// Match restricts a policy to a subset of resources. An empty list matches
+// every resource.
+type Match struct {
+	Kinds      []string `yaml:"kinds"`
+	Namespaces []string `yaml:"namespaces"` // Compared with metadata.namespace of the rendered resource.
+}
+
+// Violation reports a policy that a resource does not satisfy.
+type Violation struct {
+	Policy  string
+	Message string
+}
+
+// Set is a compiled collection of policies, ready for evaluation.
+type Set struct {
+	policies []compiledPolicy
+}
+
+type compiledPolicy struct {
+	Policy
+	program cel.Program
+}
+
+// document is the top-level layout of a policy file.
+type document struct {
+	Policies []Policy `yaml:"policies"`
+}
+
+// Parse decodes and compiles a policy file. Unknown fields, duplicate or
+// missing names and malformed expressions are reported as errors so that a
+// typo cannot silently disable a guardrail.
+func Parse(data []byte) (*Set, error) {
+	var doc document
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse policies: %w", err)
+	}
+
+	env, err := newEnv()
+	if err != nil {
+		return nil, fmt.Errorf("create CEL environment: %w", err)
+	}
+
+	set := &Set{}
+	seen := make(map[string]bool, len(doc.Policies))
+	for i, policy := range doc.Policies {
+		if strings.TrimSpace(policy.Name) == "" {
+			return nil, fmt.Errorf("policy #%d: name is required", i+1)
+		}
+		if seen[policy.Name] {
+			return nil, fmt.Errorf("policy %q: defined more than once", policy.Name)
+		}
+		seen[policy.Name] = true
+
+		if strings.TrimSpace(policy.Expression) == "" {
+			return nil, fmt.Errorf("policy %q: expression is required", policy.Name)
+		}
+		program, err := compile(env, policy.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("policy %q: %w", policy.Name, err)
+		}
+		set.policies = append(set.policies, compiledPolicy{Policy: policy, program: program})
+	}
+	return set, nil
+}
+
+// newEnv declares the policy variables as dyn, since resources have no fixed
+// schema, and enables optional syntax and the cel-go extension libraries that
+// Kubernetes also offers.
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable(VariableObject, cel.DynType),
+		cel.Variable(VariableOldObject, cel.DynType),
+		cel.OptionalTypes(),
+		ext.Strings(),
+		ext.Lists(),
+		ext.Sets(),
+		ext.Regex(),
+		ext.Math(),
+		ext.Encoders(),
+		ext.Bindings(),
+		ext.TwoVarComprehensions(),
+	)
+}
+
+// compile type-checks expression and plans its evaluation. Expressions whose
+// result is known not to be a bool are rejected up front.
+func compile(env *cel.Env, expression string) (cel.Program, error) {
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if output := ast.OutputType(); !output.IsAssignableType(cel.BoolType) {
+		return nil, fmt.Errorf("expression returns %s, want bool", output)
+	}
+	return env.Program(ast, cel.CostLimit(costLimit))
+}
+
+// Len returns the number of policies in the set.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.policies)
+}
+
+// Evaluate checks object against every matching policy and returns the
+// violations in policy order. oldObject is nil for resources that do not
+// exist on the target branch. Expressions that fail to evaluate (for
+// example by selecting a missing field) count as violations, so a policy
+// never passes by accident; guard optional fields with has().
+func (s *Set) Evaluate(object, oldObject map[string]any) []Violation {
+	if s == nil || object == nil {
+		return nil
+	}
+
+	// A nil map must surface as null rather than as an empty map value.
+	var old any
+	if oldObject != nil {
+		old = oldObject
+	}
+	vars := map[string]any{VariableObject: object, VariableOldObject: old}
+
+	var violations []Violation
+	for _, policy := range s.policies {
+		if !policy.matches(object) {
+			continue
+		}
+		if message, ok := policy.check(vars); !ok {
+			violations = append(violations, Violation{Policy: policy.Name, Message: message})
+		}
+	}
+	return violations
+}
+
+// matches reports whether the policy's match block selects object.
+func (p compiledPolicy) matches(object map[string]any) bool {
+	kind, _ := object["kind"].(string)
+	if len(p.Match.Kinds) > 0 && !slices.Contains(p.Match.Kinds, kind) {
+		return false
+	}
+	metadata, _ := object["metadata"].(map[string]any)
+	namespace, _ := metadata["namespace"].(string)
+	return len(p.Match.Namespaces) == 0 || slices.Contains(p.Match.Namespaces, namespace)
+}
+
+// check evaluates the expression and returns the violation message when it
+// does not hold.
+func (p compiledPolicy) check(vars map[string]any) (string, bool) {
+	result, _, err := p.program.Eval(vars)
+	if err != nil {
+		return fmt.Sprintf("evaluation error: %v", err), false
+	}
+	passed, isBool := result.Value().(bool)
+	if !isBool {
+		return fmt.Sprintf("evaluation error: expression returned %s, want bool", result.Type().TypeName()), false
+	}
+	if passed {
+		return "", true
+	}
+	if p.Message != "" {
+		return p.Message, false
+	}
+	return fmt.Sprintf("expression %q is false", strings.TrimSpace(p.Expression)), false
+}
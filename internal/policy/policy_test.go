@@ -0,0 +1,160 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const testPolicies = `
+policies:
+  - name: no-latest-tags
+    match:
+      kinds: [Deployment, StatefulSet]
+    expression: >-
+      object.spec.template.spec.containers.all(c, !c.image.endsWith(":latest"))
+    message: Images must be pinned to a version
+  - name: production-replicas
+    match:
+      kinds: [Deployment]
+      namespaces: [production]
+    expression: object.spec.replicas >= 2
+  - name: no-new-load-balancers
+    match:
+      kinds: [Service]
+    expression: oldObject != null || object.spec.type != "LoadBalancer"
+`
+
+func decode(t *testing.T, manifest string) map[string]any {
+	t.Helper()
+	var object map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(manifest), &object))
+	return object
+}
+
+func TestSetEvaluate(t *testing.T) {
+	set, err := Parse([]byte(testPolicies))
+	require.NoError(t, err)
+	assert.Equal(t, 3, set.Len())
+
+	deployment := decode(t, `
+kind: Deployment
+metadata: {name: web, namespace: production}
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers: [{name: app, image: "nginx:latest"}]
+`)
+	assert.Equal(t, []Violation{
+		{Policy: "no-latest-tags", Message: "Images must be pinned to a version"},
+		{Policy: "production-replicas", Message: `expression "object.spec.replicas >= 2" is false`},
+	}, set.Evaluate(deployment, nil))
+
+	deployment["metadata"].(map[string]any)["namespace"] = "staging"
+	assert.Len(t, set.Evaluate(deployment, nil), 1, "namespace match excludes production-replicas")
+
+	service := decode(t, "kind: Service\nmetadata: {name: web}\nspec: {type: LoadBalancer}\n")
+	assert.Equal(t, []Violation{{Policy: "no-new-load-balancers", Message: `expression "oldObject != null || object.spec.type != \"LoadBalancer\"" is false`}},
+		set.Evaluate(service, nil))
+	assert.Empty(t, set.Evaluate(service, service), "existing load balancers are allowed")
+
+	assert.Empty(t, set.Evaluate(nil, service), "deleted resources are not evaluated")
+}
+
+func TestSetEvaluateReportsEvaluationErrors(t *testing.T) {
+	set, err := Parse([]byte(`
+policies:
+  - name: replicas
+    expression: object.spec.replicas >= 2
+  - name: not-bool
+    expression: object.kind
+`))
+	require.NoError(t, err)
+
+	violations := set.Evaluate(decode(t, "kind: ConfigMap\nmetadata: {name: cfg}\n"), nil)
+	require.Len(t, violations, 2)
+	assert.Equal(t, "evaluation error: no such key: spec", violations[0].Message)
+	assert.Equal(t, "evaluation error: expression returned string, want bool", violations[1].Message)
+}
+
+func TestSetEvaluateLanguageFeatures(t *testing.T) {
+	set, err := Parse([]byte(`
+policies:
+  - name: optional-field
+    expression: object.?spec.?paused.orValue(false) == false
+  - name: extension-strings
+    expression: object.metadata.name.lowerAscii().split("-").size() <= 2
+  - name: timestamps
+    expression: timestamp(object.metadata.annotations.expires) > timestamp("2026-01-01T00:00:00Z")
+  - name: durations
+    expression: duration(object.metadata.annotations.ttl) <= duration("24h")
+`))
+	require.NoError(t, err)
+
+	valid := decode(t, `
+kind: ConfigMap
+metadata:
+  name: Web-Config
+  annotations: {expires: "2027-05-01T00:00:00Z", ttl: 1h}
+`)
+	assert.Empty(t, set.Evaluate(valid, nil))
+
+	invalid := decode(t, `
+kind: ConfigMap
+metadata:
+  name: web-app-config
+  annotations: {expires: "2025-05-01T00:00:00Z", ttl: 48h}
+spec: {paused: true}
+`)
+	var failed []string
+	for _, violation := range set.Evaluate(invalid, nil) {
+		failed = append(failed, violation.Policy)
+	}
+	assert.Equal(t, []string{"optional-field", "extension-strings", "timestamps", "durations"}, failed)
+}
+
+func TestSetEvaluateEnforcesCostLimit(t *testing.T) {
+	set, err := Parse([]byte(`
+policies:
+  - name: expensive
+    expression: object.items.all(a, object.items.all(b, object.items.all(c, a + b + c >= 0)))
+`))
+	require.NoError(t, err)
+
+	items := make([]any, 200)
+	for i := range items {
+		items[i] = i
+	}
+	violations := set.Evaluate(map[string]any{"kind": "List", "items": items}, nil)
+	require.Len(t, violations, 1)
+	assert.Contains(t, violations[0].Message, "evaluation error: operation cancelled: actual cost limit exceeded")
+}
+
+func TestParseErrors(t *testing.T) {
+	for src, wantErr := range map[string]string{
+		"policies:\n  - expression: 'true'\n": "policy #1: name is required",
+		"policies:\n  - name: a\n":            `policy "a": expression is required`,
+		"policies:\n  - {name: a, expression: 'true'}\n  - {name: a, expression: 'true'}\n": `policy "a": defined more than once`,
+		"policies:\n  - {name: a, expression: 'object.'}\n":                                 `policy "a": ERROR: <input>:1:8: Syntax error`,
+		"policies:\n  - {name: a, expression: 'object.spec.size() + 1'}\n":                  `policy "a": expression returns int, want bool`,
+		"policies:\n  - {name: a, expression: 'object.spec.matches()'}\n":                   `policy "a": ERROR: <input>:1:20: found no matching overload for 'matches'`,
+		"policies:\n  - {name: a, expresion: 'true'}\n":                                     "field expresion not found",
+	} {
+		_, err := Parse([]byte(src))
+		require.Error(t, err, src)
+		assert.Contains(t, err.Error(), wantErr, src)
+	}
+}
+
+func TestParseEmptyFile(t *testing.T) {
+	set, err := Parse(nil)
+	require.NoError(t, err)
+	assert.Zero(t, set.Len())
+
+	var nilSet *Set
+	assert.Zero(t, nilSet.Len())
+	assert.Nil(t, nilSet.Evaluate(map[string]any{}, nil))
+}
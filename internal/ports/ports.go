@@ -138,30 +138,30 @@ type HelmChartsProcessor interface {
 // ValidationError represents a single validation error for a Kubernetes manifest.
 type ValidationError struct {
 	// Filename is the path to the manifest file that failed validation.
-	Filename string
+	Filename string `json:"filename"`
 	// Kind is the Kubernetes resource kind (e.g. "Deployment", "Service").
-	Kind string
+	Kind string `json:"kind"`
 	// Name is the metadata.name of the resource.
-	Name string
+	Name string `json:"name"`
 	// Message describes the validation failure.
-	Message string
+	Message string `json:"message"`
 }
 
 // ValidationResult captures the outcome of validating a directory of manifests.
 type ValidationResult struct {
 	// Target identifies which side of the comparison was validated (e.g. "src" or "dst").
-	Target string
+	Target string `json:"target"`
 	// Valid reports whether all manifests passed validation.
-	Valid bool
+	Valid bool `json:"valid"`
 	// ResourceCount is the total number of resources validated.
-	ResourceCount int
+	ResourceCount int `json:"resourceCount"`
 	// ErrorCount is the number of resources that failed validation.
-	ErrorCount int
+	ErrorCount int `json:"errorCount"`
 	// Errors contains structured details for each validation failure.
-	Errors []ValidationError
+	Errors []ValidationError `json:"errors,omitempty"`
 	// InvocationError is non-empty when the validator itself failed to run (e.g. binary not found).
 	// When set, Valid is false and ResourceCount/ErrorCount are zero.
-	InvocationError string
+	InvocationError string `json:"invocationError,omitempty"`
 }
 
 // ManifestValidator validates rendered Kubernetes manifests against schemas.
@@ -0,0 +1,46 @@
+package sanitizer
+
+import "github.com/shini4i/argo-compare/internal/ports"
+
+// ChainMasker applies several maskers in order, feeding each one the output of
+// the previous. Maskers in this package skip values that already hold a
+// placeholder, so overlapping rules never hash a placeholder twice.
+type ChainMasker struct {
+	maskers []ports.SensitiveDataMasker
+}
+
+// Ensure compile-time conformance to the SensitiveDataMasker contract.
+var _ ports.SensitiveDataMasker = (*ChainMasker)(nil)
+
+// NewChainMasker constructs a masker that runs maskers in the given order.
+func NewChainMasker(maskers ...ports.SensitiveDataMasker) *ChainMasker {
+	return &ChainMasker{maskers: maskers}
+}
+
+// Mask runs every masker in turn and reports whether any of them masked something.
+func (c *ChainMasker) Mask(content []byte) ([]byte, bool, error) {
+	masked := false
+	for _, masker := range c.maskers {
+		out, changed, err := masker.Mask(content)
+		if err != nil {
+			return nil, false, err
+		}
+		if changed {
+			content = out
+			masked = true
+		}
+	}
+	return content, masked, nil
+}
+
+// NewMasker builds the standard masking pipeline: Secret data first, then the
+// supplied rules for every other resource. Both stages share one hasher, so a
+// value masked by either stage gets the same placeholder.
+func NewMasker(rules MaskingRules, opts ...MaskerOption) (*ChainMasker, error) {
+	h := newHasher(opts...)
+	ruleMasker, err := newRuleMasker(rules, h)
+	if err != nil {
+		return nil, err
+	}
+	return NewChainMasker(&KubernetesSecretMasker{hasher: h}, ruleMasker), nil
+}
@@ -0,0 +1,66 @@
+package sanitizer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingMasker struct{}
+
+func (failingMasker) Mask([]byte) ([]byte, bool, error) {
+	return nil, false, errors.New("boom")
+}
+
+// TestNewMasker_SharesPlaceholdersAcrossStages ensures a value masked by either stage gets one placeholder and is never hashed twice.
+func TestNewMasker_SharesPlaceholdersAcrossStages(t *testing.T) {
+	masker, err := NewMasker(DefaultMaskingRules(), WithKey([]byte("shared")))
+	require.NoError(t, err)
+
+	input := `apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+stringData:
+  DB_PASSWORD: hunter2
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          env:
+            - name: DB_PASSWORD
+              value: hunter2
+`
+
+	result, masked, err := masker.Mask([]byte(input))
+	require.NoError(t, err)
+	assert.True(t, masked)
+
+	expected := NewKubernetesSecretMasker(WithKey([]byte("shared"))).hasher.placeholder("hunter2")
+	output := string(result)
+	assert.NotContains(t, output, "hunter2")
+	assert.Equal(t, 2, strings.Count(output, expected))
+}
+
+// TestChainMasker_PropagatesErrors ensures a failing stage aborts the chain.
+func TestChainMasker_PropagatesErrors(t *testing.T) {
+	chain := NewChainMasker(NewKubernetesSecretMasker(), failingMasker{})
+
+	_, _, err := chain.Mask([]byte("kind: ConfigMap\n"))
+	assert.EqualError(t, err, "boom")
+}
+
+// TestNewMasker_RejectsInvalidRules ensures rule errors surface from the pipeline constructor.
+func TestNewMasker_RejectsInvalidRules(t *testing.T) {
+	_, err := NewMasker(MaskingRules{Keys: []string{" "}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package sanitizer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rewriteDocuments decodes every YAML document in content, lets sanitize
+// rewrite each one in place, and re-encodes the stream only when at least one
+// document reported a change. Untouched input is returned byte-for-byte so
+// manifests without sensitive data keep their original formatting.
+func rewriteDocuments(content []byte, sanitize func(*yaml.Node) bool) ([]byte, bool, error) {
+	if len(content) == 0 {
+		return content, false, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	var documents []*yaml.Node
+	var masked bool
+
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, false, fmt.Errorf("decode manifest: %w", err)
+		}
+
+		if sanitize(&document) {
+			masked = true
+		}
+
+		documents = append(documents, &document)
+	}
+
+	if !masked {
+		return content, false, nil
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, false, fmt.Errorf("encode manifest: %w", err)
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, false, fmt.Errorf("close encoder: %w", err)
+	}
+
+	return buffer.Bytes(), true, nil
+}
+
+// documentRoot returns the top-level mapping of a decoded YAML document, or
+// nil when the document is empty or not a mapping.
+func documentRoot(document *yaml.Node) *yaml.Node {
+	if document == nil || document.Kind != yaml.DocumentNode || len(document.Content) == 0 {
+		return nil
+	}
+
+	root := document.Content[0]
+	if root == nil || root.Kind != yaml.MappingNode {
+		return nil
+	}
+	return root
+}
+
+// maskScalar replaces a scalar node's value with its placeholder, forcing a
+// plain string so the encoder does not carry over block or quoted styles that
+// only made sense for the original value.
+func maskScalar(node *yaml.Node, maskValue func(string) string) {
+	node.Value = maskValue(node.Value)
+	node.Tag = "!!str"
+	node.Style = yaml.Style(0) // yaml.Style(0) keeps the scalar in plain style; yaml.v3 does not expose a named constant.
+}
+
+// findMappingValue retrieves the value node for the supplied key within a mapping node.
+func findMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	index := findMappingKeyIndex(mapping, key)
+	if index < 0 || index+1 >= len(mapping.Content) {
+		return nil
+	}
+	return mapping.Content[index+1]
+}
+
+// findMappingKeyIndex returns the index of the key node within the mapping node content slice.
+func findMappingKeyIndex(mapping *yaml.Node, key string) int {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return -1
+	}
+
+	for i := 0; i < len(mapping.Content); i += 2 {
+		currentKey := mapping.Content[i]
+		if currentKey == nil || currentKey.Kind != yaml.ScalarNode {
+			continue
+		}
+		if strings.EqualFold(currentKey.Value, key) {
+			return i
+		}
+	}
+
+	return -1
+}
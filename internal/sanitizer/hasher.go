@@ -0,0 +1,135 @@
+package sanitizer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	maskPrefix = "ENC[hmac-sha256:"
+	maskSuffix = "]"
+	// randomKeyBytes is the size of the per-run key generated when no key is supplied.
+	randomKeyBytes = 32
+)
+
+// Digest length bounds, expressed in hex characters of the HMAC-SHA256 digest
+// embedded in each placeholder.
+const (
+	// DefaultDigestLength keeps 128 bits of the digest, enough to make accidental
+	// collisions between distinct secret values practically impossible.
+	DefaultDigestLength = 32
+	// MinDigestLength is the shortest digest accepted; anything shorter makes
+	// distinct values likely to share a placeholder.
+	MinDigestLength = 8
+	// MaxDigestLength is the full hex-encoded HMAC-SHA256 digest.
+	MaxDigestLength = 2 * sha256.Size
+)
+
+// hasher derives deterministic placeholders for sensitive values.
+//
+// Placeholders come from an HMAC-SHA256 keyed by a secret the hasher is
+// constructed with, so a reader of the diff cannot brute-force short or
+// low-entropy values offline without also knowing the key. The same value
+// always maps to the same placeholder for a given key, which keeps diffs
+// deterministic within a run (and across runs when the key is stable).
+type hasher struct {
+	mu           sync.RWMutex
+	key          []byte
+	digestLength int
+	cache        map[string]string // keyed by the full HMAC digest to avoid retaining plaintext secrets.
+}
+
+// MaskerOption customises the placeholder derivation shared by the maskers in this package.
+type MaskerOption func(*hasher)
+
+// WithKey sets the HMAC key used to derive placeholders. An empty key is
+// ignored, leaving the masker on its random per-run key.
+func WithKey(key []byte) MaskerOption {
+	return func(h *hasher) {
+		if len(key) > 0 {
+			h.key = append([]byte{}, key...)
+		}
+	}
+}
+
+// WithDigestLength sets how many hex characters of the digest appear in each
+// placeholder. Zero selects DefaultDigestLength; other values are clamped to
+// [MinDigestLength, MaxDigestLength].
+func WithDigestLength(length int) MaskerOption {
+	return func(h *hasher) {
+		switch {
+		case length == 0:
+			h.digestLength = DefaultDigestLength
+		case length < MinDigestLength:
+			h.digestLength = MinDigestLength
+		case length > MaxDigestLength:
+			h.digestLength = MaxDigestLength
+		default:
+			h.digestLength = length
+		}
+	}
+}
+
+// newHasher applies opts and, when no key was supplied, generates a random
+// one so placeholders are only comparable within the lifetime of the hasher.
+func newHasher(opts ...MaskerOption) *hasher {
+	h := &hasher{
+		digestLength: DefaultDigestLength,
+		cache:        make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if len(h.key) == 0 {
+		h.key = make([]byte, randomKeyBytes)
+		_, _ = rand.Read(h.key) //nolint:errcheck // crypto/rand.Read is documented to never return an error
+	}
+	return h
+}
+
+// placeholder returns a deterministic redacted placeholder for the provided value while reusing cached computations.
+func (h *hasher) placeholder(value string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(value))
+	digestKey := hex.EncodeToString(mac.Sum(nil))
+
+	h.mu.RLock()
+	masked, ok := h.cache[digestKey]
+	h.mu.RUnlock()
+	if ok {
+		return masked
+	}
+
+	masked = maskPrefix + digestKey[:h.digestLength] + maskSuffix
+
+	h.mu.Lock()
+	if cached, exists := h.cache[digestKey]; exists {
+		h.mu.Unlock()
+		return cached
+	}
+	h.cache[digestKey] = masked
+	h.mu.Unlock()
+
+	return masked
+}
+
+// placeholderDigestPattern matches the digest of a placeholder.
+var placeholderDigestPattern = regexp.MustCompile(regexp.QuoteMeta(maskPrefix) + `[0-9a-f]+` + regexp.QuoteMeta(maskSuffix))
+
+// StripPlaceholderDigests removes the digest from every placeholder in s.
+// Placeholders derived from different keys, such as the random keys of two
+// runs, compare equal afterwards.
+func StripPlaceholderDigests(s string) string {
+	return placeholderDigestPattern.ReplaceAllLiteralString(s, maskPrefix+maskSuffix)
+}
+
+// isPlaceholder reports whether value was already produced by a hasher, so a
+// later masker in a chain does not re-hash an earlier masker's output.
+func isPlaceholder(value string) bool {
+	return strings.HasPrefix(value, maskPrefix) && strings.HasSuffix(value, maskSuffix)
+}
@@ -0,0 +1,266 @@
+package sanitizer
+
+import (
+	"math"
+	"strings"
+
+	"github.com/shini4i/argo-compare/internal/ports"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleMasker redacts sensitive values in any kind of resource according to a
+// MaskingRules set: by kind and field path, by key name, and by value content.
+// It complements KubernetesSecretMasker, which only handles Secret objects.
+type RuleMasker struct {
+	rules  compiledRules
+	hasher *hasher
+}
+
+// Ensure compile-time conformance to the SensitiveDataMasker contract.
+var _ ports.SensitiveDataMasker = (*RuleMasker)(nil)
+
+// NewRuleMasker constructs a RuleMasker for rules. It returns an error when a
+// rule is malformed (e.g. an invalid regular expression).
+func NewRuleMasker(rules MaskingRules, opts ...MaskerOption) (*RuleMasker, error) {
+	return newRuleMasker(rules, newHasher(opts...))
+}
+
+// newRuleMasker compiles rules into a RuleMasker that derives placeholders from h.
+func newRuleMasker(rules MaskingRules, h *hasher) (*RuleMasker, error) {
+	compiled, err := compileRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleMasker{rules: compiled, hasher: h}, nil
+}
+
+// Mask redacts every scalar selected by the configured rules and reports whether anything was masked.
+func (m *RuleMasker) Mask(content []byte) ([]byte, bool, error) {
+	return rewriteDocuments(content, m.sanitizeDocument)
+}
+
+// sanitizeDocument applies field rules for the document's kind, then walks
+// the whole tree for key- and value-based matches.
+func (m *RuleMasker) sanitizeDocument(document *yaml.Node) bool {
+	root := documentRoot(document)
+	if root == nil {
+		return false
+	}
+
+	kind := ""
+	if kindNode := findMappingValue(root, "kind"); kindNode != nil {
+		kind = strings.ToLower(kindNode.Value)
+	}
+
+	masked := false
+	for _, rule := range m.rules.fields {
+		if !rule.appliesTo(kind) {
+			continue
+		}
+		for _, segments := range rule.paths {
+			for _, node := range selectPath(root, segments) {
+				if m.maskAll(node) {
+					masked = true
+				}
+			}
+		}
+	}
+
+	if m.walk(root) {
+		masked = true
+	}
+	return masked
+}
+
+// appliesTo reports whether the rule targets the given lower-cased kind.
+func (r compiledFieldRule) appliesTo(kind string) bool {
+	if len(r.kinds) == 0 {
+		return true
+	}
+	_, ok := r.kinds[kind]
+	return ok
+}
+
+// selectPath resolves a field path against node, expanding "*" segments over
+// every mapping value or list item.
+func selectPath(node *yaml.Node, segments []string) []*yaml.Node {
+	if node == nil {
+		return nil
+	}
+	if len(segments) == 0 {
+		return []*yaml.Node{node}
+	}
+
+	segment, rest := segments[0], segments[1:]
+	var children []*yaml.Node
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if segment == "*" || node.Content[i].Value == segment {
+				children = append(children, node.Content[i+1])
+			}
+		}
+	case yaml.SequenceNode:
+		if segment == "*" {
+			children = node.Content
+		}
+	default:
+		return nil
+	}
+
+	var selected []*yaml.Node
+	for _, child := range children {
+		selected = append(selected, selectPath(child, rest)...)
+	}
+	return selected
+}
+
+// maskAll masks every maskable scalar at or below node.
+func (m *RuleMasker) maskAll(node *yaml.Node) bool {
+	if node == nil {
+		return false
+	}
+	if node.Kind == yaml.ScalarNode {
+		return m.maskScalar(node, maskableByKey)
+	}
+
+	masked := false
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue // mapping keys are structure, not data
+		}
+		if m.maskAll(child) {
+			masked = true
+		}
+	}
+	return masked
+}
+
+// walk visits every node under node, masking values whose key (or sibling
+// `name`) matches a key rule and string values whose content matches a value rule.
+func (m *RuleMasker) walk(node *yaml.Node) bool {
+	if node == nil {
+		return false
+	}
+
+	masked := false
+	switch node.Kind {
+	case yaml.MappingNode:
+		if m.maskNameValuePair(node) {
+			masked = true
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind == yaml.ScalarNode && m.keyMatches(key.Value) {
+				if m.maskScalar(value, maskableByKey) {
+					masked = true
+				}
+				continue
+			}
+			if m.walk(value) {
+				masked = true
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if m.walk(item) {
+				masked = true
+			}
+		}
+	case yaml.ScalarNode:
+		if m.valueMatches(node) && m.maskScalar(node, maskableByValue) {
+			masked = true
+		}
+	}
+	return masked
+}
+
+// maskNameValuePair handles list entries shaped like container env vars
+// ({name: DB_PASSWORD, value: ...}), where the sensitive key is carried in the
+// `name` field rather than in the mapping key.
+func (m *RuleMasker) maskNameValuePair(node *yaml.Node) bool {
+	name := findMappingValue(node, "name")
+	value := findMappingValue(node, "value")
+	if name == nil || value == nil || name.Kind != yaml.ScalarNode || value.Kind != yaml.ScalarNode {
+		return false
+	}
+	if !m.keyMatches(name.Value) {
+		return false
+	}
+	return m.maskScalar(value, maskableByKey)
+}
+
+// keyMatches reports whether key matches any key rule.
+func (m *RuleMasker) keyMatches(key string) bool {
+	normalized := normalizeKey(key)
+	for _, re := range m.rules.keys {
+		if re.MatchString(normalized) {
+			return true
+		}
+	}
+	return false
+}
+
+// valueMatches reports whether a scalar's content matches any value rule.
+func (m *RuleMasker) valueMatches(node *yaml.Node) bool {
+	if !maskableByValue(node) {
+		return false
+	}
+	for _, matches := range m.rules.values {
+		if matches(node.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskScalar masks node when allowed reports it maskable and it has not
+// already been replaced by a placeholder earlier in the chain.
+func (m *RuleMasker) maskScalar(node *yaml.Node, allowed func(*yaml.Node) bool) bool {
+	if !allowed(node) || isPlaceholder(node.Value) {
+		return false
+	}
+	maskScalar(node, m.hasher.placeholder)
+	return true
+}
+
+// maskableByKey accepts any non-empty scalar except booleans and nulls: a
+// numeric password is still a password, but masking `enabled: true` under a
+// matching key would only add noise.
+func maskableByKey(node *yaml.Node) bool {
+	if node.Kind != yaml.ScalarNode || node.Value == "" {
+		return false
+	}
+	switch node.ShortTag() {
+	case "!!bool", "!!null":
+		return false
+	default:
+		return true
+	}
+}
+
+// maskableByValue restricts content detection to strings.
+func maskableByValue(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Value != "" && node.ShortTag() == "!!str"
+}
+
+// shannonEntropy returns the entropy of s in bits per byte.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy := 0.0
+	length := float64(len(s))
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,269 @@
+package sanitizer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults applied to a high-entropy rule that omits its tuning knobs. Hex
+// strings top out at 4 bits of entropy per character, so the 4.5 threshold
+// keeps image digests and checksum annotations unmasked while still catching
+// random base64 tokens.
+const (
+	defaultEntropyMinLength = 20
+	defaultEntropyThreshold = 4.5
+)
+
+// MaskingRules configures a RuleMasker. Rules are additive: a scalar is masked
+// when any field, key, or value rule selects it.
+type MaskingRules struct {
+	// Fields mask everything below the given paths in resources of the given kinds.
+	Fields []FieldRule `yaml:"fields"`
+	// Keys are case-insensitive glob patterns (e.g. "*PASSWORD*", "*_TOKEN")
+	// matched against mapping keys anywhere in a resource, and against the
+	// `name` of name/value pairs such as container env entries. Dashes, dots
+	// and slashes in keys are treated as underscores before matching.
+	Keys []string `yaml:"keys"`
+	// Values masks string scalars whose content looks sensitive, wherever they appear.
+	Values ValueRules `yaml:"values"`
+}
+
+// FieldRule selects fields to mask by resource kind and path.
+type FieldRule struct {
+	// Kinds lists the resource kinds the rule applies to (case-insensitive).
+	// An empty list applies the rule to every kind.
+	Kinds []string `yaml:"kinds"`
+	// Paths are dot-separated field paths such as "data" or
+	// "spec.template.spec.containers.*.env.*.value". A "*" segment matches
+	// every key of a mapping or every item of a list. All scalars at or below
+	// the selected node are masked.
+	Paths []string `yaml:"paths"`
+}
+
+// ValueRules selects string values to mask by their content.
+type ValueRules struct {
+	// PrivateKeys masks PEM-encoded private keys.
+	PrivateKeys bool `yaml:"privateKeys"`
+	// JWTs masks values containing a JSON Web Token.
+	JWTs bool `yaml:"jwts"`
+	// CredentialURLs masks URLs and connection strings with embedded
+	// user:password credentials (e.g. postgres://app:hunter2@db:5432/app).
+	CredentialURLs bool `yaml:"credentialURLs"`
+	// HighEntropy masks long, random-looking tokens. Disabled when nil.
+	HighEntropy *HighEntropyRule `yaml:"highEntropy"`
+	// Patterns are additional regular expressions; a value containing a match is masked.
+	Patterns []string `yaml:"patterns"`
+}
+
+// HighEntropyRule tunes the high-entropy detector.
+type HighEntropyRule struct {
+	// MinLength is the shortest value considered (default 20).
+	MinLength int `yaml:"minLength"`
+	// Threshold is the minimum Shannon entropy in bits per character (default 4.5).
+	Threshold float64 `yaml:"threshold"`
+}
+
+// rulesFile is the on-disk schema of a masking rules file. IncludeDefaults is
+// a pointer so an omitted key can default to true.
+type rulesFile struct {
+	IncludeDefaults *bool       `yaml:"includeDefaults"`
+	Fields          []FieldRule `yaml:"fields"`
+	Keys            []string    `yaml:"keys"`
+	Values          ValueRules  `yaml:"values"`
+}
+
+// DefaultMaskingRules returns the built-in rule set: credential-looking env
+// vars and config keys, SealedSecret ciphertext, ExternalSecret inline
+// templates, private keys, JWTs, and connection strings with credentials.
+// High-entropy detection is opt-in because it is prone to false positives.
+func DefaultMaskingRules() MaskingRules {
+	return MaskingRules{
+		Fields: []FieldRule{
+			{Kinds: []string{"SealedSecret"}, Paths: []string{"spec.encryptedData"}},
+			{Kinds: []string{"ExternalSecret"}, Paths: []string{"spec.target.template.data"}},
+		},
+		Keys: []string{
+			"*PASSWORD*",
+			"*PASSWD*",
+			"*_TOKEN",
+			"*_SECRET",
+			"*API_KEY*",
+			"*APIKEY*",
+			"*ACCESS_KEY*",
+			"*PRIVATE_KEY*",
+			"*CLIENT_SECRET*",
+		},
+		Values: ValueRules{
+			PrivateKeys:    true,
+			JWTs:           true,
+			CredentialURLs: true,
+		},
+	}
+}
+
+// ParseMaskingRules decodes a masking rules file. Unknown keys are rejected so
+// typos do not silently disable a rule. Unless the file sets
+// `includeDefaults: false`, its rules are merged on top of DefaultMaskingRules.
+func ParseMaskingRules(data []byte) (MaskingRules, error) {
+	var file rulesFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return MaskingRules{}, fmt.Errorf("parse masking rules: %w", err)
+	}
+
+	rules := MaskingRules{Fields: file.Fields, Keys: file.Keys, Values: file.Values}
+	if file.IncludeDefaults == nil || *file.IncludeDefaults {
+		rules = mergeMaskingRules(DefaultMaskingRules(), rules)
+	}
+
+	if _, err := compileRules(rules); err != nil {
+		return MaskingRules{}, err
+	}
+	return rules, nil
+}
+
+// mergeMaskingRules layers extra on top of base. List rules are concatenated,
+// boolean detectors are enabled if either side enables them, and a high-entropy
+// rule in extra replaces the one in base.
+func mergeMaskingRules(base, extra MaskingRules) MaskingRules {
+	merged := MaskingRules{
+		Fields: slices.Concat(base.Fields, extra.Fields),
+		Keys:   slices.Concat(base.Keys, extra.Keys),
+		Values: ValueRules{
+			PrivateKeys:    base.Values.PrivateKeys || extra.Values.PrivateKeys,
+			JWTs:           base.Values.JWTs || extra.Values.JWTs,
+			CredentialURLs: base.Values.CredentialURLs || extra.Values.CredentialURLs,
+			HighEntropy:    base.Values.HighEntropy,
+			Patterns:       slices.Concat(base.Values.Patterns, extra.Values.Patterns),
+		},
+	}
+	if extra.Values.HighEntropy != nil {
+		merged.Values.HighEntropy = extra.Values.HighEntropy
+	}
+	return merged
+}
+
+var (
+	privateKeyPattern    = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----`)
+	jwtPattern           = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]*`)
+	credentialURLPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]+:[^/\s@]+@`)
+)
+
+// compiledRules is the matcher form of MaskingRules.
+type compiledRules struct {
+	fields []compiledFieldRule
+	keys   []*regexp.Regexp
+	values []func(string) bool
+}
+
+type compiledFieldRule struct {
+	kinds map[string]struct{} // lower-cased; empty matches every kind
+	paths [][]string
+}
+
+// compileRules validates rules and converts them into matchers.
+func compileRules(rules MaskingRules) (compiledRules, error) {
+	var compiled compiledRules
+
+	for i, field := range rules.Fields {
+		if len(field.Paths) == 0 {
+			return compiledRules{}, fmt.Errorf("masking rules: field rule %d has no paths", i)
+		}
+		rule := compiledFieldRule{kinds: make(map[string]struct{}, len(field.Kinds))}
+		for _, kind := range field.Kinds {
+			rule.kinds[strings.ToLower(strings.TrimSpace(kind))] = struct{}{}
+		}
+		for _, p := range field.Paths {
+			segments, err := splitFieldPath(p)
+			if err != nil {
+				return compiledRules{}, fmt.Errorf("masking rules: field rule %d: %w", i, err)
+			}
+			rule.paths = append(rule.paths, segments)
+		}
+		compiled.fields = append(compiled.fields, rule)
+	}
+
+	for _, pattern := range rules.Keys {
+		if strings.TrimSpace(pattern) == "" {
+			return compiledRules{}, errors.New("masking rules: empty key pattern")
+		}
+		compiled.keys = append(compiled.keys, globToRegexp(normalizeKey(pattern)))
+	}
+
+	valueMatchers, err := compileValueRules(rules.Values)
+	if err != nil {
+		return compiledRules{}, err
+	}
+	compiled.values = valueMatchers
+
+	return compiled, nil
+}
+
+// compileValueRules converts the content detectors into predicates.
+func compileValueRules(values ValueRules) ([]func(string) bool, error) {
+	var matchers []func(string) bool
+	if values.PrivateKeys {
+		matchers = append(matchers, privateKeyPattern.MatchString)
+	}
+	if values.JWTs {
+		matchers = append(matchers, jwtPattern.MatchString)
+	}
+	if values.CredentialURLs {
+		matchers = append(matchers, credentialURLPattern.MatchString)
+	}
+	if values.HighEntropy != nil {
+		minLength := values.HighEntropy.MinLength
+		if minLength <= 0 {
+			minLength = defaultEntropyMinLength
+		}
+		threshold := values.HighEntropy.Threshold
+		if threshold <= 0 {
+			threshold = defaultEntropyThreshold
+		}
+		matchers = append(matchers, func(v string) bool {
+			return len(v) >= minLength && !strings.ContainsAny(v, " \t\r\n") && shannonEntropy(v) >= threshold
+		})
+	}
+	for _, pattern := range values.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("masking rules: invalid value pattern %q: %w", pattern, err)
+		}
+		matchers = append(matchers, re.MatchString)
+	}
+	return matchers, nil
+}
+
+// splitFieldPath splits a dot-separated path, rejecting empty segments.
+func splitFieldPath(p string) ([]string, error) {
+	segments := strings.Split(strings.TrimSpace(p), ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid field path %q", p)
+		}
+	}
+	return segments, nil
+}
+
+// normalizeKey folds the separators commonly used in env var names, config
+// keys and annotation names onto underscores so a single pattern such as
+// "*_TOKEN" covers GITHUB_TOKEN, github-token and example.com/github.token.
+func normalizeKey(key string) string {
+	return strings.NewReplacer("-", "_", ".", "_", "/", "_").Replace(key)
+}
+
+// globToRegexp converts a "*"/"?" glob into an anchored, case-insensitive regexp.
+func globToRegexp(glob string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(glob)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.MustCompile("(?i)^" + quoted + "$")
+}
@@ -0,0 +1,81 @@
+package sanitizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseMaskingRulesMergesDefaults ensures file rules extend the built-in set unless defaults are disabled.
+func TestParseMaskingRulesMergesDefaults(t *testing.T) {
+	rules, err := ParseMaskingRules([]byte(`
+keys:
+  - "*_DSN"
+values:
+  patterns:
+    - "sk_live_[A-Za-z0-9]+"
+`))
+	require.NoError(t, err)
+	assert.Contains(t, rules.Keys, "*PASSWORD*")
+	assert.Contains(t, rules.Keys, "*_DSN")
+	assert.True(t, rules.Values.JWTs)
+	assert.Equal(t, []string{"sk_live_[A-Za-z0-9]+"}, rules.Values.Patterns)
+
+	rules, err = ParseMaskingRules([]byte(`
+includeDefaults: false
+keys: ["*_DSN"]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*_DSN"}, rules.Keys)
+	assert.Empty(t, rules.Fields)
+	assert.False(t, rules.Values.PrivateKeys)
+}
+
+// TestParseMaskingRulesEmptyFileUsesDefaults ensures an empty rules file behaves like the built-in set.
+func TestParseMaskingRulesEmptyFileUsesDefaults(t *testing.T) {
+	rules, err := ParseMaskingRules(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaskingRules(), rules)
+}
+
+// TestParseMaskingRulesOptOut ensures a file disabling the defaults restores
+// the output of releases that masked Secrets only.
+func TestParseMaskingRulesOptOut(t *testing.T) {
+	rules, err := ParseMaskingRules([]byte("includeDefaults: false\n"))
+	require.NoError(t, err)
+	masker, err := NewMasker(rules)
+	require.NoError(t, err)
+
+	manifest := []byte("kind: ConfigMap\nmetadata:\n  name: app\ndata:\n  DB_PASSWORD: hunter2\n")
+	out, changed, err := masker.Mask(manifest)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, string(manifest), string(out))
+}
+
+// TestParseMaskingRulesRejectsInvalidRules ensures malformed rule files fail loudly.
+func TestParseMaskingRulesRejectsInvalidRules(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":     "kyes: [\"*_DSN\"]\n",
+		"invalid pattern": "values:\n  patterns: [\"(unclosed\"]\n",
+		"empty path":      "fields:\n  - kinds: [ConfigMap]\n    paths: [\"data..x\"]\n",
+		"missing paths":   "fields:\n  - kinds: [ConfigMap]\n",
+		"empty key":       "keys: [\"\"]\n",
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseMaskingRules([]byte(input))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestShannonEntropy ensures entropy separates random tokens from hex digests.
+func TestShannonEntropy(t *testing.T) {
+	assert.InDelta(t, 0, shannonEntropy("aaaaaaaa"), 0.0001)
+	assert.InDelta(t, 1, shannonEntropy("abababab"), 0.0001)
+	assert.Less(t, shannonEntropy("3f786850e387550fdab836ed7e6dc881de23001b3f786850e387550fdab836ed"), defaultEntropyThreshold)
+	assert.GreaterOrEqual(t, shannonEntropy("Zq8Lk2Vx9Rt4Nw7Bc1Mp5Hs3Jd6Fg0Yu"), defaultEntropyThreshold)
+}
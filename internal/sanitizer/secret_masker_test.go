@@ -28,7 +28,7 @@ stringData:
 	output := string(result)
 	assert.NotContains(t, output, "c2VjcmV0cGFzc3dvcmQ=")
 	assert.NotContains(t, output, "plain-token")
-	assert.Contains(t, output, "ENC[sha256:")
+	assert.Contains(t, output, "ENC[hmac-sha256:")
 
 	again, maskedAgain, err := masker.Mask([]byte(input))
 	require.NoError(t, err)
@@ -85,7 +85,7 @@ stringData:
 	output := string(result)
 	assert.NotContains(t, output, "c2VjcmV0")
 	assert.NotContains(t, output, "another value")
-	assert.Equal(t, 2, strings.Count(output, "ENC[sha256:"))
+	assert.Equal(t, 2, strings.Count(output, "ENC[hmac-sha256:"))
 }
 
 // TestKubernetesSecretMasker_MaskDifferentiatesValues ensures masked placeholders differ for distinct inputs.
@@ -116,8 +116,8 @@ data:
 	assert.True(t, maskedTwo)
 
 	assert.NotEqual(t, string(resultOne), string(resultTwo))
-	assert.Contains(t, string(resultOne), "ENC[sha256:")
-	assert.Contains(t, string(resultTwo), "ENC[sha256:")
+	assert.Contains(t, string(resultOne), "ENC[hmac-sha256:")
+	assert.Contains(t, string(resultTwo), "ENC[hmac-sha256:")
 }
 
 // TestKubernetesSecretMasker_HandlesNonMappingData ensures non-mapping secret data blocks are ignored safely.
@@ -135,3 +135,75 @@ data: []
 	assert.False(t, masked)
 	assert.Equal(t, input, string(result))
 }
+
+const keyedSecretManifest = `apiVersion: v1
+kind: Secret
+metadata:
+  name: sample
+stringData:
+  pin: "1234"
+`
+
+// TestKubernetesSecretMasker_KeyedPlaceholdersAreStable ensures two maskers sharing a key produce identical placeholders.
+func TestKubernetesSecretMasker_KeyedPlaceholdersAreStable(t *testing.T) {
+	first, _, err := NewKubernetesSecretMasker(WithKey([]byte("repo-key"))).Mask([]byte(keyedSecretManifest))
+	require.NoError(t, err)
+	second, _, err := NewKubernetesSecretMasker(WithKey([]byte("repo-key"))).Mask([]byte(keyedSecretManifest))
+	require.NoError(t, err)
+
+	assert.Equal(t, string(first), string(second))
+	assert.NotContains(t, string(first), "1234")
+}
+
+// TestKubernetesSecretMasker_KeyChangesPlaceholder ensures placeholders depend on the key, not only on the value.
+func TestKubernetesSecretMasker_KeyChangesPlaceholder(t *testing.T) {
+	first, _, err := NewKubernetesSecretMasker(WithKey([]byte("key-one"))).Mask([]byte(keyedSecretManifest))
+	require.NoError(t, err)
+	second, _, err := NewKubernetesSecretMasker(WithKey([]byte("key-two"))).Mask([]byte(keyedSecretManifest))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, string(first), string(second))
+}
+
+// TestKubernetesSecretMasker_RandomKeyPerInstance ensures unkeyed maskers do not share placeholders.
+func TestKubernetesSecretMasker_RandomKeyPerInstance(t *testing.T) {
+	first, _, err := NewKubernetesSecretMasker().Mask([]byte(keyedSecretManifest))
+	require.NoError(t, err)
+	second, _, err := NewKubernetesSecretMasker().Mask([]byte(keyedSecretManifest))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, string(first), string(second))
+}
+
+// TestStripPlaceholderDigests ensures output of maskers with different keys compares equal once digests are stripped.
+func TestStripPlaceholderDigests(t *testing.T) {
+	first, _, err := NewKubernetesSecretMasker().Mask([]byte(keyedSecretManifest))
+	require.NoError(t, err)
+	second, _, err := NewKubernetesSecretMasker().Mask([]byte(keyedSecretManifest))
+	require.NoError(t, err)
+
+	assert.Equal(t, StripPlaceholderDigests(string(first)), StripPlaceholderDigests(string(second)))
+	assert.Contains(t, StripPlaceholderDigests(string(first)), maskPrefix+maskSuffix)
+	assert.Equal(t, "ENC[hmac-sha256:not-hex]", StripPlaceholderDigests("ENC[hmac-sha256:not-hex]"))
+}
+
+// TestKubernetesSecretMasker_DigestLength ensures the placeholder carries the configured number of hex characters.
+func TestKubernetesSecretMasker_DigestLength(t *testing.T) {
+	cases := []struct {
+		name     string
+		length   int
+		expected int
+	}{
+		{name: "default", length: 0, expected: DefaultDigestLength},
+		{name: "custom", length: 12, expected: 12},
+		{name: "clamped to minimum", length: 2, expected: MinDigestLength},
+		{name: "clamped to maximum", length: 100, expected: MaxDigestLength},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			masker := NewKubernetesSecretMasker(WithKey([]byte("key")), WithDigestLength(tc.length))
+			assert.Len(t, masker.hasher.placeholder("value"), len(maskPrefix)+tc.expected+len(maskSuffix))
+		})
+	}
+}
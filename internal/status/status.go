@@ -0,0 +1,31 @@
+// Package status provides abstractions for reporting comparison outcomes as
+// commit statuses on upstream systems.
+package status
+
+import "context"
+
+// State is the outcome a commit status reports.
+type State string
+
+const (
+	// StateSuccess reports an Application that passed every configured gate.
+	StateSuccess State = "success"
+	// StateFailed reports an Application that failed validation, violated a
+	// policy or exceeded the risk threshold.
+	StateFailed State = "failed"
+)
+
+// Status describes the outcome of one Application.
+type Status struct {
+	Name        string // Identifies the status among the commit's others, e.g. argo-compare/apps/web.yaml.
+	State       State
+	Description string
+	TargetURL   string // Optional link shown with the status, such as the job log.
+}
+
+// Reporter can publish a commit status to an upstream system.
+type Reporter interface {
+	// Report sets status on the commit with the given SHA, replacing an
+	// earlier status of the same name.
+	Report(ctx context.Context, sha string, status Status) error
+}
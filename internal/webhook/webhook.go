@@ -0,0 +1,137 @@
+// Package webhook delivers JSON payloads to an arbitrary HTTP endpoint, such
+// as a chat bot or a change-tracking service, optionally signed with HMAC.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/helpers"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of the request body, keyed with
+	// Config.Secret and hex encoded with a "sha256=" prefix.
+	SignatureHeader = "X-Argo-Compare-Signature-256"
+
+	signaturePrefix       = "sha256="
+	userAgent             = "argo-compare"
+	maxErrorResponseBytes = 4096
+	defaultClientTimeout  = 15 * time.Second
+)
+
+// Config describes the endpoint payloads are delivered to.
+type Config struct {
+	URL        string            // Absolute http or https URL the payload is POSTed to.
+	Headers    map[string]string // Extra request headers, e.g. Authorization; they override the defaults.
+	Secret     string            // Signs the body in SignatureHeader; empty sends unsigned requests.
+	HTTPClient *http.Client
+	Timeout    time.Duration
+}
+
+// Sender POSTs payloads to a webhook endpoint.
+type Sender struct {
+	client   *http.Client
+	endpoint string
+	headers  map[string]string
+	secret   []byte
+}
+
+// NewSender creates a Sender for cfg. It validates that cfg.URL is an absolute
+// http or https URL. If cfg.HTTPClient is nil, a default http.Client is created
+// using cfg.Timeout (or the package default).
+func NewSender(cfg Config) (*Sender, error) {
+	endpoint, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: parse URL: %w", err)
+	}
+	if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("webhook: URL must be an absolute http or https URL")
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = defaultClientTimeout
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+
+	return &Sender{
+		client:   client,
+		endpoint: endpoint.String(),
+		headers:  cfg.Headers,
+		secret:   []byte(cfg.Secret),
+	}, nil
+}
+
+// Send POSTs payload as JSON. Network errors and 5xx server errors are retried
+// with exponential backoff; every attempt carries the same signature, so the
+// receiver can deduplicate deliveries by it.
+func (s *Sender) Send(ctx context.Context, payload []byte) error {
+	retryCfg := helpers.DefaultRetryConfig()
+	return helpers.WithRetry(ctx, retryCfg, func() error {
+		return s.doRequest(ctx, payload)
+	})
+}
+
+// Sign returns the SignatureHeader value for payload signed with secret, for
+// receivers to compare against with hmac.Equal.
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// doRequest performs the HTTP request and returns an error only for retryable failures.
+// Client errors (4xx) are wrapped in a permanentError to prevent retry.
+func (s *Sender) doRequest(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("webhook: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+	if len(s.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(s.secret, payload))
+	}
+
+	resp, err := s.client.Do(req) // #nosec G107 G704 -- endpoint is the operator-configured webhook URL, not user input
+	if err != nil {
+		// Network errors are retryable
+		return fmt.Errorf("webhook: perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		// Success - drain response body for connection reuse
+		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // best-effort drain for keep-alive; a failure here is harmless
+		return nil
+	}
+
+	//nolint:errcheck // best-effort read of the error body; a partial or empty read still yields a usable message
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
+	apiErr := fmt.Errorf("webhook: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+
+	// 3xx and 4xx responses indicate a configuration issue (wrong URL,
+	// rejected credentials or signature) and should not be retried
+	if resp.StatusCode < http.StatusInternalServerError {
+		return helpers.WrapPermanent(apiErr)
+	}
+
+	// 5xx: Server errors are transient and should be retried
+	return apiErr
+}
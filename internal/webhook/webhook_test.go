@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shini4i/argo-compare/internal/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func respondWith(status int) *http.Response {
+	resp := httptest.NewRecorder()
+	resp.WriteHeader(status)
+	return resp.Result()
+}
+
+func TestSenderSend(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			received = req
+			var err error
+			body, err = io.ReadAll(req.Body)
+			require.NoError(t, err)
+			return respondWith(http.StatusNoContent), nil
+		}),
+	}
+
+	sender, err := NewSender(Config{
+		URL:        "https://hooks.example.com/argo-compare?team=platform",
+		Headers:    map[string]string{"Authorization": "Bearer token", "User-Agent": "platform-bot"},
+		Secret:     "s3cret",
+		HTTPClient: client,
+	})
+	require.NoError(t, err)
+
+	payload := []byte(`{"applications":[]}`)
+	require.NoError(t, sender.Send(context.Background(), payload))
+
+	require.NotNil(t, received)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "https://hooks.example.com/argo-compare?team=platform", received.URL.String())
+	assert.Equal(t, payload, body)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", received.Header.Get("Authorization"))
+	assert.Equal(t, "platform-bot", received.Header.Get("User-Agent"), "configured headers override the defaults")
+	// echo -n '{"applications":[]}' | openssl dgst -sha256 -hmac s3cret
+	assert.Equal(t, "sha256=b0b7834bd09dc5c4a6d55656dab3d99d1d91281bdeb41cc9fe992f8c3c8287de", received.Header.Get(SignatureHeader))
+}
+
+func TestSenderSendWithoutSecret(t *testing.T) {
+	var received *http.Request
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			received = req
+			return respondWith(http.StatusOK), nil
+		}),
+	}
+
+	sender, err := NewSender(Config{URL: "http://bot.internal/hook", HTTPClient: client})
+	require.NoError(t, err)
+	require.NoError(t, sender.Send(context.Background(), []byte("{}")))
+
+	require.NotNil(t, received)
+	assert.Empty(t, received.Header.Get(SignatureHeader))
+	assert.Equal(t, "argo-compare", received.Header.Get("User-Agent"))
+}
+
+func TestNewSenderRejectsInvalidURLs(t *testing.T) {
+	for _, endpoint := range []string{"", "hooks.example.com/argo-compare", "ftp://hooks.example.com", "https://", "://bad"} {
+		_, err := NewSender(Config{URL: endpoint})
+		assert.Error(t, err, endpoint)
+	}
+}
+
+func TestSenderSend4xxIsNotRetried(t *testing.T) {
+	attempts := 0
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return respondWith(http.StatusUnauthorized), nil
+		}),
+	}
+
+	sender, err := NewSender(Config{URL: "https://hooks.example.com", HTTPClient: client})
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), []byte("{}"))
+	require.Error(t, err)
+	assert.True(t, helpers.IsPermanent(err))
+	assert.Contains(t, err.Error(), "401")
+	assert.Equal(t, 1, attempts) // Should not retry 4xx
+}
+
+func TestSenderSend5xxIsRetried(t *testing.T) {
+	attempts := 0
+	var signatures []string
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			signatures = append(signatures, req.Header.Get(SignatureHeader))
+			if attempts < 2 {
+				return respondWith(http.StatusBadGateway), nil
+			}
+			return respondWith(http.StatusAccepted), nil
+		}),
+	}
+
+	sender, err := NewSender(Config{URL: "https://hooks.example.com", Secret: "s3cret", HTTPClient: client})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, sender.Send(ctx, []byte("{}")))
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, signatures[0], signatures[1], "retries carry the same signature")
+}